@@ -3,6 +3,10 @@ package fio
 import (
 	"bufio"
 	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
 )
 
 //ReadBufferedLine is a function which wraps around the bufio.ReadLine method to
@@ -37,3 +41,127 @@ func ReadBufferedLine(r *bufio.Reader, p *[]byte) (bool, error) {
 
 	return false, nil
 }
+
+//CellType is the type used by the spreadsheet formats that store typed values
+//(such as .xlsx and .ods files) to remember the type of each value. The values
+//themselves are always stored as strings, to satisfy the Spreadsheeter
+//interface.
+type CellType byte
+
+//The various cell types to use in conjunction with the CellType type
+const (
+	CellTypeString CellType = iota //a plain string value
+	CellTypeNumber                 //a numeric value, formatted by strconv
+	CellTypeBool                   //a boolean value, stored as TRUE or FALSE
+	CellTypeDate                   //a date (and time), see CellDateLayout and CellDateTimeLayout
+)
+
+//The layouts used to store date values as strings. A date without a time of day
+//is stored using CellDateLayout, otherwise CellDateTimeLayout is used.
+const (
+	CellDateLayout     = "2006-01-02"
+	CellDateTimeLayout = "2006-01-02 15:04:05"
+)
+
+//The strings used to store boolean cell values
+const (
+	cellBoolTrue  = "TRUE"
+	cellBoolFalse = "FALSE"
+)
+
+//inferCellType determines the cell type of a value that is set without an
+//explicit type. Only numbers are inferred, and only if they do not start with a
+//meaningful zero (like zip codes or account numbers do).
+func inferCellType(value string) CellType {
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return CellTypeString
+	}
+
+	if len(value) > 1 && value[0] == '0' && value[1] != '.' {
+		return CellTypeString
+	}
+
+	if value[0] == '+' || value[0] == '.' || value[len(value)-1] == '.' {
+		return CellTypeString
+	}
+
+	return CellTypeNumber
+}
+
+//cellNumber matches the decimal number syntax accepted by the spreadsheet
+//formats, which excludes the hexadecimal, infinite and NaN values accepted by
+//strconv.ParseFloat(...)
+var cellNumber = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+//validCellNumber checks if a value can be stored as a number cell
+func validCellNumber(value string) bool {
+	if !cellNumber.MatchString(value) {
+		return false
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	return err == nil && !math.IsInf(f, 0)
+}
+
+//formatCellTime converts a time to a string using CellDateLayout when it does
+//not have a time of day, and CellDateTimeLayout otherwise.
+func formatCellTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format(CellDateLayout)
+	}
+
+	return t.Format(CellDateTimeLayout)
+}
+
+//parseCellTime converts a string stored using either CellDateTimeLayout or
+//CellDateLayout back to a time.
+func parseCellTime(value string) (time.Time, error) {
+	t, err := time.Parse(CellDateTimeLayout, value)
+
+	if err != nil {
+		t, err = time.Parse(CellDateLayout, value)
+	}
+
+	return t, err
+}
+
+//spreadsheetData returns the rows of a spreadsheet. The rows of the
+//spreadsheet types within this package are returned directly, other
+//implementations are read using Get(...) until a row without any values is
+//encountered.
+func spreadsheetData(s Spreadsheeter) [][]string {
+	switch sheet := s.(type) {
+	case SpreadsheetDelimSheet:
+		return sheet.Data
+	case *SpreadsheetFixed:
+		return sheet.Data
+	case *SpreadsheetXLSX:
+		return sheet.Data
+	case *SpreadsheetODS:
+		return sheet.Data
+	}
+
+	var data [][]string
+
+	for row := 0; ; row++ {
+		var values []string
+
+		for col := 0; ; col++ {
+			value, ok := s.Get(row, col)
+
+			if !ok {
+				break
+			}
+
+			values = append(values, value)
+		}
+
+		if len(values) == 0 {
+			return data
+		}
+
+		data = append(data, values)
+	}
+}
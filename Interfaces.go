@@ -5,16 +5,28 @@ interfaces are:
 
 - FileLoader: Provides a single Load(...) function
 - FileSaver: Provides a single Save(...) function
-- Settinger: Provides methods to add/set/get variables besides implementing load/save methods
+- Settinger: Provides methods to add/set/get/delete/rename/list variables besides implementing load/save methods
 - Spreadsheeter: Provides set/get methods and implements load/save methods
+- RowReader: Provides a single ReadRow() function to stream rows of a spreadsheet
+- RowWriter: Provides a single WriteRow(...) function to stream rows of a spreadsheet
+- Workbooker: Provides access to multiple named Spreadsheeter sheets and implements load/save methods
 
 The currently implemented file types are:
 
 - SettingsINI: Implements the Settinger interface for .ini-like files
 - SpreadsheetDelim: Implements the Spreadsheeter interface for .csv-like files
+- SpreadsheetFixed: Implements the Spreadsheeter interface for fixed-width column files
+- SpreadsheetXLSX: Implements the Spreadsheeter interface for sheets within .xlsx workbooks
+- SpreadsheetODS: Implements the Spreadsheeter interface for sheets within .ods documents
+- Workbook: Implements the Workbooker interface for .xlsx and .ods files
+- WorkbookDir: Implements the Workbooker interface for a directory of .csv-like files
 */
 package fio
 
+import (
+	"iter"
+)
+
 //The FileLoader interface defines a single 'Load(string) error' function
 type FileLoader interface {
 	Load(file string) error
@@ -25,21 +37,45 @@ type FileSaver interface {
 	Save(file string) error
 }
 
+//The RowReader interface defines a single 'ReadRow() ([]string, error)'
+//function, which returns the next row of a spreadsheet. After the last row
+//the returned error is io.EOF.
+type RowReader interface {
+	ReadRow() ([]string, error)
+}
+
+//The RowWriter interface defines a single 'WriteRow([]string) error' function,
+//which appends a row to a spreadsheet.
+type RowWriter interface {
+	WriteRow(row []string) error
+}
+
 //The Settinger interface provides an interface for general settings files,
 //mainly based on the manner in which .ini files are commonly defined. A combination
 //of a variable name and value are stored in a file which can (but do not have
 //to) be stored under a header name. Setting the value of a variable should not
 //be allowed if the value doesn't exists and adding a value should not be allowed
 //if the value exists. In the case that a value is added to a non-existant
-//header than this header should be created in the process.
+//header than this header should be created in the process. Deleting or
+//renaming should not be allowed if the header or value doesn't exist, and
+//renaming should not be allowed if the new name is already in use. Headers and
+//variable names are listed in alphabetical order.
 type Settinger interface {
 	FileLoader
 	FileSaver
 	HeaderExists(header string) bool
-	ValueExists(header, name, value string) bool
+	ValueExists(header, name string) bool
 	Add(header, name, value string) error
 	Set(header, name, value string) error
 	Get(header, name string) (string, bool)
+	DeleteValue(header, name string) error
+	DeleteHeader(header string) error
+	RenameHeader(header, newHeader string) error
+	RenameValue(header, name, newName string) error
+	HeaderNames() []string
+	Keys(header string) []string
+	AllHeaders() iter.Seq2[int, string]
+	AllValues(header string) iter.Seq2[string, string]
 
 	//various methods derived from Get(...)
 	GetInt(header, name string) (int, bool, error)
@@ -65,3 +101,18 @@ type Spreadsheeter interface {
 	GetFloat32(row, col int) (float32, bool, error)
 	GetFloat64(row, col int) (float64, bool, error)
 }
+
+//The Workbooker interface provides an interface for files containing multiple
+//named spreadsheets, such as .xlsx and .ods files. Each sheet is a
+//Spreadsheeter, and the order of the sheets is preserved. Adding a sheet
+//should not be allowed if a sheet with the same name exists, and removing or
+//renaming a sheet should not be allowed if the sheet doesn't exist.
+type Workbooker interface {
+	FileLoader
+	FileSaver
+	Sheets() []string
+	Sheet(name string) (Spreadsheeter, bool)
+	AddSheet(name string) (Spreadsheeter, error)
+	RemoveSheet(name string) error
+	RenameSheet(name, newName string) error
+}
@@ -2,8 +2,9 @@ package fio
 
 import (
 	"bufio"
-	"bytes"
+	"iter"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -22,6 +23,30 @@ type SettingsINI struct {
 	buffer   int
 	Filename string
 	Headers  map[string]*SettingsINIHeader
+	lines    map[settingsINIPosition]settingsINILine
+
+	observers *settingsObservers
+	batching  bool
+	pending   []SettingsChange
+	history   *settingsHistory
+}
+
+//settingsINIPosition identifies a header (if name is empty) or a value pair
+//while keeping track of the lines on which they were loaded
+type settingsINIPosition struct {
+	header string
+	name   string
+}
+
+//settingsINIEnd is the position of the comments following the last header or
+//value pair. Loaded names cannot contain an equal-character.
+var settingsINIEnd = settingsINIPosition{"", "="}
+
+//settingsINILine stores the line number of a loaded header or value pair,
+//together with the comment lines preceding it
+type settingsINILine struct {
+	number   int
+	comments []string
 }
 
 //NewSettingsINI creates a new SettingsINI instance and returns the pointer. The
@@ -29,11 +54,13 @@ type SettingsINI struct {
 //file. During loading this buffer will grow to the largest line encountered, an
 //initially adequate buffer reduces the times it will have to be resized.
 func NewSettingsINI(buffer int) *SettingsINI {
-	return &SettingsINI{buffer, "", make(map[string]*SettingsINIHeader)}
+	return &SettingsINI{buffer, "", make(map[string]*SettingsINIHeader), nil, nil, false, nil, nil}
 }
 
 //Load is capable of loading a file styled like a .ini file. Headers should be
-//defined using the '[HeaderName]' syntax, variables as 'Name = Value'.
+//defined using the '[HeaderName]' syntax, variables as 'Name = Value'. Lines
+//starting with '//' are comments, they are kept with the header or variable
+//following them and written again when saving.
 func (si *SettingsINI) Load(filename string) error {
 	//check argument for errors
 	if len(filename) == 0 {
@@ -57,14 +84,25 @@ func (si *SettingsINI) Load(filename string) error {
 	buffer := make([]byte, 0, si.buffer)
 	reader := bufio.NewReader(file)
 
-	//create the map
+	//keep the previous contents to notify subscribers of the changes once
+	//loading succeeds
+	var previous *SettingsINI
+
+	if si.observers != nil {
+		previous = si.Copy()
+	}
+
+	//create the maps
 	si.Headers = make(map[string]*SettingsINIHeader)
+	si.lines = make(map[settingsINIPosition]settingsINILine)
 
 	//process file contents
 	var currentHeader *SettingsINIHeader = nil
+	var comments []string
+	currentHeaderName := ""
 	eof := false
 
-	for !eof {
+	for lineNumber := 1; !eof; lineNumber++ {
 		//read a new line
 		buffer = buffer[:0]
 		eof, err = ReadBufferedLine(reader, &buffer)
@@ -84,7 +122,8 @@ func (si *SettingsINI) Load(filename string) error {
 		if len(line) >= 2 {
 			//if the line contains a comment, continue
 			if line[0] == '/' && line[1] == '/' {
-				//this is a comment
+				//this is a comment, keep it with the next header or value pair
+				comments = append(comments, line)
 				continue
 			}
 
@@ -108,7 +147,10 @@ func (si *SettingsINI) Load(filename string) error {
 
 					//create and append the new header, then continue processing next line
 					currentHeader = &SettingsINIHeader{make(map[string]string)}
+					currentHeaderName = headerName
 					si.Headers[headerName] = currentHeader
+					si.lines[settingsINIPosition{headerName, ""}] = settingsINILine{lineNumber, comments}
+					comments = nil
 				} else {
 					//invalid INI syntax: an opening bracket '[', but no matching closing bracket
 					file.Close()
@@ -146,10 +188,16 @@ func (si *SettingsINI) Load(filename string) error {
 
 				//add value to current header
 				currentHeader.Values[name] = value
+				si.lines[settingsINIPosition{currentHeaderName, name}] = settingsINILine{lineNumber, comments}
+				comments = nil
 			}
 		}
 	}
 
+	if len(comments) != 0 {
+		si.lines[settingsINIEnd] = settingsINILine{0, comments}
+	}
+
 	//everything is loaded, close the file
 	err = file.Close()
 
@@ -157,97 +205,57 @@ func (si *SettingsINI) Load(filename string) error {
 		return Error{ErrorTypeLoading, "SettingsINI", "Failed to close the file after reading"}
 	}
 
+	if previous != nil {
+		si.record(settingsChanges(previous, si)...)
+	}
+
 	return nil
 }
 
-//Save will store the current SettingsINI type contents to a file.
+//Save will store the current SettingsINI type contents to a file, using the
+//DefaultSettingsFormat. See SaveFormat(...) to use a different format.
 func (si *SettingsINI) Save(filename string) error {
-	//make sure a valid filename exists
-	if len(filename) == 0 {
-		if len(si.Filename) == 0 {
-			return Error{ErrorTypeInvalidArgument, "SettingsINI", "Internal and argument filenames are empty"}
-		}
-
-		filename = si.Filename
-	}
-
-	//all data is in the settings file now, save to file
-	file, err := os.Create(filename)
-
-	if err != nil {
-		return Error{ErrorTypeSaving, "SettingsINI", "Failed to open file for writing"}
-	}
-
-	//Note: If there is a 'headerless' header (that is: a header without a name),
-	//then it should be written first. If not, then the next time the file is read
-	//the headerless header will be interpreted as belonging to the previous header
-	header, ok := si.Headers[""]
-
-	if ok {
-		//write the headerless data
-		for valueKey, value := range header.Values {
-			var valueBuffer bytes.Buffer
+	return si.SaveFormat(filename, DefaultSettingsFormat)
+}
 
-			valueBuffer.WriteString(valueKey)
-			valueBuffer.WriteString(" = ")
-			valueBuffer.WriteString(value)
-			valueBuffer.WriteByte('\n')
+//Copy returns a deep copy of the settings, including the line numbers and
+//comments of the loaded headers and value pairs. Change subscriptions, commit hooks and the
+//undo history are not copied.
+func (si *SettingsINI) Copy() *SettingsINI {
+	result := &SettingsINI{si.buffer, si.Filename, make(map[string]*SettingsINIHeader, len(si.Headers)), nil, nil, false, nil, nil}
 
-			_, err = file.WriteString(valueBuffer.String())
+	for name, header := range si.Headers {
+		values := make(map[string]string, len(header.Values))
 
-			if err != nil { //uses the fact that if err != nil, then nWritten < len(buffer.String())
-				file.Close()
-				return Error{ErrorTypeSaving, "SettingsINI", "Failed to write headerless value pair string to file"}
-			}
+		for key, value := range header.Values {
+			values[key] = value
 		}
-	}
-
-	//loop through all headers
-	for headerKey, header := range si.Headers {
-		//Note: Only write headered data here
-		if len(headerKey) != 0 {
-			var headerBuffer bytes.Buffer
-
-			//write the header
-			headerBuffer.WriteByte('[')
-			headerBuffer.WriteString(headerKey)
-			headerBuffer.WriteString("]\n")
 
-			_, err = file.WriteString(headerBuffer.String())
-
-			if err != nil { //uses the fact that if err != nil, then nWritten < len(buffer.String())
-				file.Close()
-				return Error{ErrorTypeSaving, "SettingsINI", "Failed to write header string to file"}
-			}
-
-			//write all the values
-			for valueKey, value := range header.Values {
-				var valueBuffer bytes.Buffer
-
-				//write the value
-				valueBuffer.WriteString(valueKey)
-				valueBuffer.WriteString(" = ")
-				valueBuffer.WriteString(value)
-				valueBuffer.WriteByte('\n')
+		result.Headers[name] = &SettingsINIHeader{values}
+	}
 
-				_, err = file.WriteString(valueBuffer.String())
+	if si.lines != nil {
+		result.lines = make(map[settingsINIPosition]settingsINILine, len(si.lines))
 
-				if err != nil { //uses the fact that if err != nil, then nWritten < len(buffer.String())
-					file.Close()
-					return Error{ErrorTypeSaving, "SettingsINI", "Failed to write headered value pair string to file"}
-				}
-			}
+		for position, line := range si.lines {
+			result.lines[position] = line
 		}
 	}
 
-	err = file.Close()
+	return result
+}
 
-	if err != nil {
-		//failed to close the file
-		return Error{ErrorTypeSaving, "SettingsINI", "Failed to close the file after saving"}
-	}
+//Line returns the line number (starting at 1) on which the specified value
+//pair was loaded, or on which the header was loaded if name is empty. The
+//function returns 0 if the header or value pair was not loaded from a file.
+func (si *SettingsINI) Line(header, name string) int {
+	return si.lines[settingsINIPosition{header, name}].number
+}
 
-	return nil
+//comments returns the comment lines loaded before the specified header (if
+//name is empty) or value pair
+func (si *SettingsINI) comments(header, name string) []string {
+	return si.lines[settingsINIPosition{header, name}].comments
 }
 
 //HeaderExists returns true if the specified header name is stored in the
@@ -294,6 +302,7 @@ func (si *SettingsINI) Add(header, name, value string) error {
 
 	//set the new value
 	h.Values[name] = value
+	si.record(SettingsChange{SettingsAdded, header, name, "", value})
 	return nil
 }
 
@@ -318,10 +327,184 @@ func (si *SettingsINI) Set(header, name, value string) error {
 	}
 
 	//set value
-	h.Values[name] = value
+	if old := h.Values[name]; old != value {
+		h.Values[name] = value
+		si.record(SettingsChange{SettingsModified, header, name, old, value})
+	}
+
 	return nil
 }
 
+//DeleteValue will remove the specified variable from the specified header. The
+//header itself remains, even if it no longer contains any variables. The
+//function will return an error if the header or the variable does not exist.
+func (si *SettingsINI) DeleteValue(header, name string) error {
+	h, ok := si.Headers[header]
+
+	if !ok {
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find header while deleting value"}
+	}
+
+	old, ok := h.Values[name]
+
+	if !ok {
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find value pair while deleting value"}
+	}
+
+	delete(h.Values, name)
+	delete(si.lines, settingsINIPosition{header, name})
+	si.record(SettingsChange{SettingsRemoved, header, name, old, ""})
+	return nil
+}
+
+//DeleteHeader will remove the specified header and all of its variables. The
+//function will return an error if the header does not exist.
+func (si *SettingsINI) DeleteHeader(header string) error {
+	if !si.HeaderExists(header) {
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find header while deleting header"}
+	}
+
+	return si.Batch(func(si *SettingsINI) error {
+		for _, name := range si.Keys(header) {
+			si.DeleteValue(header, name)
+		}
+
+		delete(si.Headers, header)
+		delete(si.lines, settingsINIPosition{header, ""})
+		return nil
+	})
+}
+
+//RenameHeader will rename the specified header, keeping its variables. The
+//function will return an error if the header does not exist or if a header
+//with the new name already exists.
+func (si *SettingsINI) RenameHeader(header, newHeader string) error {
+	h, ok := si.Headers[header]
+
+	switch {
+	case !ok:
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find header while renaming header"}
+	case header == newHeader:
+		return nil
+	case si.HeaderExists(newHeader):
+		return Error{ErrorTypeExists, "SettingsINI", "Header with the new name already exists"}
+	}
+
+	names := si.Keys(header)
+	delete(si.Headers, header)
+	si.Headers[newHeader] = h
+
+	if si.lines != nil {
+		for _, name := range append(names, "") {
+			if line, ok := si.lines[settingsINIPosition{header, name}]; ok {
+				delete(si.lines, settingsINIPosition{header, name})
+				si.lines[settingsINIPosition{newHeader, name}] = line
+			}
+		}
+	}
+
+	changes := make([]SettingsChange, 0, 2*len(names))
+
+	for _, name := range names {
+		changes = append(changes, SettingsChange{SettingsRemoved, header, name, h.Values[name], ""}, SettingsChange{SettingsAdded, newHeader, name, "", h.Values[name]})
+	}
+
+	si.record(changes...)
+	return nil
+}
+
+//RenameValue will rename the specified variable within the specified header,
+//keeping its value. The function will return an error if the header or the
+//variable does not exist, or if a variable with the new name already exists.
+func (si *SettingsINI) RenameValue(header, name, newName string) error {
+	h, ok := si.Headers[header]
+
+	if !ok {
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find header while renaming value"}
+	}
+
+	value, ok := h.Values[name]
+
+	switch {
+	case !ok:
+		return Error{ErrorTypeNotFound, "SettingsINI", "Could not find value pair while renaming value"}
+	case name == newName:
+		return nil
+	case si.ValueExists(header, newName):
+		return Error{ErrorTypeExists, "SettingsINI", "Value pair with the new name already exists"}
+	}
+
+	delete(h.Values, name)
+	h.Values[newName] = value
+
+	if line, ok := si.lines[settingsINIPosition{header, name}]; ok {
+		delete(si.lines, settingsINIPosition{header, name})
+		si.lines[settingsINIPosition{header, newName}] = line
+	}
+
+	si.record(SettingsChange{SettingsRemoved, header, name, value, ""}, SettingsChange{SettingsAdded, header, newName, "", value})
+	return nil
+}
+
+//HeaderNames returns the names of all headers in alphabetical order, the
+//headerless variables are stored under the empty header name, which is listed
+//first if it exists
+func (si *SettingsINI) HeaderNames() []string {
+	names := make([]string, 0, len(si.Headers))
+
+	for name := range si.Headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+//Keys returns the names of all variables within the specified header in
+//alphabetical order. If the header does not exist the result is empty.
+func (si *SettingsINI) Keys(header string) []string {
+	h, ok := si.Headers[header]
+
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(h.Values))
+
+	for name := range h.Values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+//AllHeaders returns an iterator over the index and name of every header, in
+//the order of HeaderNames()
+func (si *SettingsINI) AllHeaders() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, name := range si.HeaderNames() {
+			if !yield(i, name) {
+				return
+			}
+		}
+	}
+}
+
+//AllValues returns an iterator over the name and value of every variable
+//within the specified header, in the order of Keys(...)
+func (si *SettingsINI) AllValues(header string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, name := range si.Keys(header) {
+			value, ok := si.Get(header, name)
+
+			if ok && !yield(name, value) {
+				return
+			}
+		}
+	}
+}
+
 //Get will return the specified variable's value if it exists in the specified
 //header. If either the variable or the header does not exist then the function's
 //boolean return value will be false.
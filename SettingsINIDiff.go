@@ -0,0 +1,255 @@
+package fio
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+//MarshalText converts the change type to its string representation, such that
+//it is readable within JSON output
+func (sct SettingsChangeType) MarshalText() ([]byte, error) {
+	if sct > SettingsModified {
+		return nil, Error{ErrorTypeInvalidArgument, "SettingsChangeType", "Unknown change type"}
+	}
+
+	return []byte(sct.String()), nil
+}
+
+//UnmarshalText converts the string representation of a change type back
+func (sct *SettingsChangeType) UnmarshalText(text []byte) error {
+	for _, t := range []SettingsChangeType{SettingsAdded, SettingsRemoved, SettingsModified} {
+		if string(text) == t.String() {
+			*sct = t
+			return nil
+		}
+	}
+
+	return Error{ErrorTypeParsing, "SettingsChangeType", "Unknown change type '" + string(text) + "'"}
+}
+
+//SettingsDiff describes the differences between two settings documents as
+//the changes required to turn the first document into the second, sorted by
+//header and name. The diff can be printed using String(), converted to and
+//from JSON using the encoding/json package and applied as a patch using
+//Apply(...).
+type SettingsDiff struct {
+	Changes []SettingsChange `json:"changes"`
+}
+
+//settingsValue returns the value of a variable, or nil if it does not exist
+func settingsValue(s Settinger, header, name string) *string {
+	value, ok := s.Get(header, name)
+
+	if !ok {
+		return nil
+	}
+
+	return &value
+}
+
+//DiffSettings compares two settings documents and returns the added, removed
+//and modified variables of every header. Headers without variables are not
+//compared.
+func DiffSettings(a, b Settinger) SettingsDiff {
+	diff := SettingsDiff{}
+
+	for _, header := range a.HeaderNames() {
+		for name, value := range a.AllValues(header) {
+			newValue := settingsValue(b, header, name)
+
+			if newValue == nil {
+				diff.Changes = append(diff.Changes, SettingsChange{SettingsRemoved, header, name, value, ""})
+			} else if *newValue != value {
+				diff.Changes = append(diff.Changes, SettingsChange{SettingsModified, header, name, value, *newValue})
+			}
+		}
+	}
+
+	for _, header := range b.HeaderNames() {
+		for name, value := range b.AllValues(header) {
+			if !a.ValueExists(header, name) {
+				diff.Changes = append(diff.Changes, SettingsChange{SettingsAdded, header, name, "", value})
+			}
+		}
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Header != diff.Changes[j].Header {
+			return diff.Changes[i].Header < diff.Changes[j].Header
+		}
+
+		return diff.Changes[i].Name < diff.Changes[j].Name
+	})
+
+	return diff
+}
+
+//Empty returns true if the documents are equal
+func (sd SettingsDiff) Empty() bool {
+	return len(sd.Changes) == 0
+}
+
+//Headers returns the names of the headers containing changes
+func (sd SettingsDiff) Headers() []string {
+	var headers []string
+
+	for _, change := range sd.Changes {
+		if len(headers) == 0 || headers[len(headers)-1] != change.Header {
+			headers = append(headers, change.Header)
+		}
+	}
+
+	return headers
+}
+
+//Header returns the changes within the specified header
+func (sd SettingsDiff) Header(header string) []SettingsChange {
+	var changes []SettingsChange
+
+	for _, change := range sd.Changes {
+		if change.Header == header {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+//String returns a human-readable description of the diff, listing the
+//changes per header. Added variables are prefixed by '+', removed variables by
+//'-' and modified variables by '~'.
+func (sd SettingsDiff) String() string {
+	var result strings.Builder
+
+	for i, change := range sd.Changes {
+		if i == 0 || sd.Changes[i-1].Header != change.Header {
+			result.WriteString("[" + change.Header + "]\n")
+		}
+
+		switch change.Type {
+		case SettingsAdded:
+			result.WriteString("+ " + change.Name + " = " + change.New + "\n")
+		case SettingsRemoved:
+			result.WriteString("- " + change.Name + " = " + change.Old + "\n")
+		default:
+			result.WriteString("~ " + change.Name + " = " + change.Old + " -> " + change.New + "\n")
+		}
+	}
+
+	return result.String()
+}
+
+//JSON returns the diff as indented JSON, see SettingsDiff
+func (sd SettingsDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(sd, "", "  ")
+}
+
+//Apply applies the diff as a patch to the document. The document has to
+//contain the old values of the removed and modified variables and may not
+//contain the added variables, otherwise no changes are made and the returned
+//error lists every mismatch.
+func (sd SettingsDiff) Apply(s Settinger) error {
+	var conflicts []string
+
+	for _, change := range sd.Changes {
+		current := settingsValue(s, change.Header, change.Name)
+		description := "[" + change.Header + "] " + change.Name
+
+		switch {
+		case change.Type == SettingsAdded && current != nil:
+			conflicts = append(conflicts, description+" already exists")
+		case change.Type != SettingsAdded && current == nil:
+			conflicts = append(conflicts, description+" does not exist")
+		case change.Type != SettingsAdded && *current != change.Old:
+			conflicts = append(conflicts, description+" is '"+*current+"' instead of '"+change.Old+"'")
+		}
+	}
+
+	if len(conflicts) != 0 {
+		return Error{ErrorTypeExists, "SettingsDiff", "The patch does not apply: " + strings.Join(conflicts, ", ")}
+	}
+
+	for _, change := range sd.Changes {
+		var err error
+
+		switch change.Type {
+		case SettingsAdded:
+			err = s.Add(change.Header, change.Name, change.New)
+		case SettingsRemoved:
+			err = s.DeleteValue(change.Header, change.Name)
+		default:
+			err = s.Set(change.Header, change.Name, change.New)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//SettingsConflict describes a variable changed differently by both sides of a
+//three-way merge. A nil value means the variable does not exist in that
+//document.
+type SettingsConflict struct {
+	Header string
+	Name   string
+	Base   *string
+	Ours   *string
+	Theirs *string
+}
+
+func (sc SettingsConflict) String() string {
+	describe := func(value *string) string {
+		if value == nil {
+			return "(missing)"
+		}
+
+		return "'" + *value + "'"
+	}
+
+	return "[" + sc.Header + "] " + sc.Name + ": base " + describe(sc.Base) + ", ours " + describe(sc.Ours) + ", theirs " + describe(sc.Theirs)
+}
+
+//equalSettingsValues compares two values that may not exist
+func equalSettingsValues(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+//MergeSettings performs a three-way merge: the changes between base and
+//theirs are applied to a copy of ours. Variables changed by both sides to
+//different values are conflicts, for which the value of ours is kept. The
+//function returns the merged document (a new SettingsINI instance) and the
+//conflicts, sorted by header and name.
+func MergeSettings(base, ours, theirs Settinger) (*SettingsINI, []SettingsConflict) {
+	result := NewSettingsINI(256)
+
+	for _, header := range ours.HeaderNames() {
+		result.Headers[header] = &SettingsINIHeader{make(map[string]string)}
+
+		for name, value := range ours.AllValues(header) {
+			result.Headers[header].Values[name] = value
+		}
+	}
+
+	var conflicts []SettingsConflict
+	var changes []SettingsChange
+
+	for _, change := range DiffSettings(base, theirs).Changes {
+		baseValue := settingsValue(base, change.Header, change.Name)
+		ourValue := settingsValue(ours, change.Header, change.Name)
+		theirValue := settingsValue(theirs, change.Header, change.Name)
+
+		switch {
+		case equalSettingsValues(ourValue, baseValue):
+			changes = append(changes, change)
+		case !equalSettingsValues(ourValue, theirValue):
+			conflicts = append(conflicts, SettingsConflict{change.Header, change.Name, baseValue, ourValue, theirValue})
+		}
+	}
+
+	result.applyChanges(changes)
+	return result, conflicts
+}
@@ -0,0 +1,84 @@
+package fio
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testSettingsINIFrom(values map[string]map[string]string) *SettingsINI {
+	si := NewSettingsINI(16)
+
+	for header, pairs := range values {
+		for name, value := range pairs {
+			si.Add(header, name, value)
+		}
+	}
+
+	return si
+}
+
+func TestDiffSettings(t *testing.T) {
+	a := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "80", "host": "a"}, "log": {"level": "info"}})
+	b := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "8080", "debug": "true"}, "log": {"level": "info"}})
+	diff := DiffSettings(a, b)
+
+	expected := []SettingsChange{
+		{SettingsAdded, "server", "debug", "", "true"},
+		{SettingsRemoved, "server", "host", "a", ""},
+		{SettingsModified, "server", "port", "80", "8080"},
+	}
+
+	if !reflect.DeepEqual(diff.Changes, expected) || !reflect.DeepEqual(diff.Headers(), []string{"server"}) || len(diff.Header("log")) != 0 {
+		t.Errorf("%v != %v\n", diff.Changes, expected)
+	}
+
+	if text := diff.String(); text != "[server]\n+ debug = true\n- host = a\n~ port = 80 -> 8080\n" {
+		t.Errorf("Unexpected human-readable diff:\n%s\n", text)
+	}
+
+	//the JSON representation can be read back
+	data, err := diff.JSON()
+	var decoded SettingsDiff
+
+	if err != nil || json.Unmarshal(data, &decoded) != nil || !reflect.DeepEqual(decoded, diff) {
+		t.Errorf("Failed to convert the diff to and from JSON:\n%s\n", data)
+	}
+
+	//applying the diff as a patch turns a copy of a into b
+	patched := a.Copy()
+
+	if err = diff.Apply(patched); err != nil || !DiffSettings(patched, b).Empty() {
+		t.Errorf("Failed to apply the patch (error: %v)\n", err)
+	}
+
+	//a document that does not match is not changed
+	if err = diff.Apply(patched); err == nil || !DiffSettings(patched, b).Empty() {
+		t.Errorf("Expected applying the patch twice to fail without changes\n")
+	}
+}
+
+func TestMergeSettings(t *testing.T) {
+	base := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "80", "host": "a", "mode": "x"}})
+	ours := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "81", "host": "a", "mode": "y"}, "local": {"path": "/tmp"}})
+	theirs := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "80", "host": "b", "mode": "z", "timeout": "5"}})
+
+	merged, conflicts := MergeSettings(base, ours, theirs)
+	expected := testSettingsINIFrom(map[string]map[string]string{"server": {"port": "81", "host": "b", "mode": "y", "timeout": "5"}, "local": {"path": "/tmp"}})
+
+	if diff := DiffSettings(merged, expected); !diff.Empty() {
+		t.Errorf("Unexpected merge result:\n%s\n", diff.String())
+	}
+
+	if len(conflicts) != 1 || conflicts[0].String() != "[server] mode: base 'x', ours 'y', theirs 'z'" {
+		t.Errorf("Unexpected conflicts %v\n", conflicts)
+	}
+
+	//removing a variable on one side while changing it on the other conflicts
+	theirs.DeleteValue("server", "port")
+	_, conflicts = MergeSettings(base, ours, theirs)
+
+	if len(conflicts) != 2 || conflicts[1].Theirs != nil || *conflicts[1].Ours != "81" {
+		t.Errorf("Unexpected conflicts %v\n", conflicts)
+	}
+}
@@ -0,0 +1,255 @@
+package fio
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+//SettingsOrder is the type used to specify the order in which headers and
+//variables are written
+type SettingsOrder byte
+
+//The various orders to use in conjunction with the SettingsOrder type
+const (
+	SettingsOrderOriginal     SettingsOrder = iota //the order of the loaded file, followed by the added headers and variables in alphabetical order
+	SettingsOrderAlphabetical                      //alphabetical order
+)
+
+//SettingsFormat describes how SettingsINI contents are written. Headers and
+//variables are ordered according to Order, unless CompareHeaders or
+//CompareKeys are specified (returning a negative value if a should be written
+//before b, a positive value if a should be written after b and 0 if the order
+//does not matter). The headerless variables are always written first.
+//
+//If Align is true the '=' characters of the variables within a header are
+//aligned, if Spacing is true the '=' character is surrounded by spaces.
+//BlankLines is the number of empty lines between headers, LineEnding
+//seperates the lines (an empty LineEnding is '\n') and FinalNewline selects if
+//the last line ends with a LineEnding.
+type SettingsFormat struct {
+	Order          SettingsOrder
+	CompareHeaders func(a, b string) int
+	CompareKeys    func(header, a, b string) int
+	Align          bool
+	Spacing        bool
+	BlankLines     int
+	LineEnding     string
+	FinalNewline   bool
+}
+
+//DefaultSettingsFormat is the format used by SettingsINI.Save(...)
+var DefaultSettingsFormat = SettingsFormat{
+	Order:        SettingsOrderOriginal,
+	Spacing:      true,
+	LineEnding:   "\n",
+	FinalNewline: true,
+}
+
+//CanonicalSettingsFormat is an order-independent format, such that equal
+//settings always result in equal files
+var CanonicalSettingsFormat = SettingsFormat{
+	Order:        SettingsOrderAlphabetical,
+	Spacing:      true,
+	BlankLines:   1,
+	LineEnding:   "\n",
+	FinalNewline: true,
+}
+
+//sortNames sorts header or variable names according to the format. Loaded
+//names are sorted by line when using the original order.
+func (sf *SettingsFormat) sortNames(names []string, compare func(a, b string) int, line func(name string) int) {
+	sort.SliceStable(names, func(i, j int) bool {
+		if compare != nil {
+			return compare(names[i], names[j]) < 0
+		}
+
+		if sf.Order == SettingsOrderOriginal {
+			a, b := line(names[i]), line(names[j])
+
+			switch {
+			case a != 0 && b != 0 && a != b:
+				return a < b
+			case a != 0 && b == 0:
+				return true
+			case a == 0 && b != 0:
+				return false
+			}
+		}
+
+		return names[i] < names[j]
+	})
+}
+
+//Write writes the settings to the writer using the specified format. Loaded
+//comments are written directly before the header or variable that followed
+//them, comments at the end of the loaded file are written last.
+func (si *SettingsINI) Write(w io.Writer, format SettingsFormat) error {
+	lineEnding := format.LineEnding
+
+	if len(lineEnding) == 0 {
+		lineEnding = "\n"
+	}
+
+	separator := "="
+
+	if format.Spacing {
+		separator = " = "
+	}
+
+	//the headerless variables are written first, as they would otherwise
+	//belong to the last header when loading the file again
+	headers := si.HeaderNames()
+
+	if len(headers) != 0 && len(headers[0]) == 0 {
+		headers = headers[1:]
+	}
+
+	format.sortNames(headers, format.CompareHeaders, func(name string) int {
+		return si.Line(name, "")
+	})
+
+	if len(si.Keys("")) != 0 {
+		headers = append([]string{""}, headers...)
+	}
+
+	var lines []string
+
+	for i, header := range headers {
+		if i != 0 {
+			for j := 0; j < format.BlankLines; j++ {
+				lines = append(lines, "")
+			}
+		}
+
+		if len(header) != 0 {
+			lines = append(lines, si.comments(header, "")...)
+			lines = append(lines, "["+header+"]")
+		}
+
+		names := si.Keys(header)
+		var compare func(a, b string) int
+
+		if format.CompareKeys != nil {
+			compare = func(a, b string) int {
+				return format.CompareKeys(header, a, b)
+			}
+		}
+
+		format.sortNames(names, compare, func(name string) int {
+			return si.Line(header, name)
+		})
+
+		width := 0
+
+		for _, name := range names {
+			if length := utf8.RuneCountInString(name); length > width {
+				width = length
+			}
+		}
+
+		for _, name := range names {
+			value, _ := si.Get(header, name)
+			padding := ""
+
+			if format.Align {
+				padding = strings.Repeat(" ", width-utf8.RuneCountInString(name))
+			}
+
+			lines = append(lines, si.comments(header, name)...)
+			lines = append(lines, name+padding+separator+value)
+		}
+	}
+
+	lines = append(lines, si.comments(settingsINIEnd.header, settingsINIEnd.name)...)
+
+	writer := bufio.NewWriter(w)
+	writer.WriteString(strings.Join(lines, lineEnding))
+
+	if format.FinalNewline && len(lines) != 0 {
+		writer.WriteString(lineEnding)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "SettingsINI", "Failed to write the settings"}
+	}
+
+	return nil
+}
+
+//SaveFormat will store the current SettingsINI type contents to a file using
+//the specified format. If an empty filename is specified the filename used for
+//the last call to Load(...) is used.
+func (si *SettingsINI) SaveFormat(filename string, format SettingsFormat) error {
+	//make sure a valid filename exists
+	if len(filename) == 0 {
+		if len(si.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SettingsINI", "Internal and argument filenames are empty"}
+		}
+
+		filename = si.Filename
+	}
+
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SettingsINI", "Failed to open file for writing"}
+	}
+
+	if err = si.Write(file, format); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return Error{ErrorTypeSaving, "SettingsINI", "Failed to close the file after saving"}
+	}
+
+	return nil
+}
+
+//FormatSettingsINI normalises a settings file in place by loading it and
+//writing it again using the specified format (such as CanonicalSettingsFormat).
+//The file is replaced by renaming a temporary file, so it is never left half
+//written. Comments are kept, see Write(...).
+func FormatSettingsINI(filename string, format SettingsFormat) error {
+	si := NewSettingsINI(256)
+
+	if err := si.Load(filename); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filename)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "SettingsINI", "Failed to retrieve the file permissions"}
+	}
+
+	temporary, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SettingsINI", "Failed to create a temporary file"}
+	}
+
+	err = si.Write(temporary, format)
+	chmodErr := temporary.Chmod(info.Mode().Perm())
+	closeErr := temporary.Close()
+
+	switch {
+	case err != nil:
+	case chmodErr != nil || closeErr != nil:
+		err = Error{ErrorTypeSaving, "SettingsINI", "Failed to close the temporary file"}
+	case os.Rename(temporary.Name(), filename) != nil:
+		err = Error{ErrorTypeSaving, "SettingsINI", "Failed to replace the file"}
+	}
+
+	if err != nil {
+		os.Remove(temporary.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,83 @@
+package fio
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSettingsINIWrite(t *testing.T) {
+	si := NewSettingsINI(16)
+
+	if err := si.Load(testSettingsINIWrite(t, "format.ini", "z = 1\n[server]\nport=80\nhost = a\n[app]\nname = x\n")); err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	si.Add("server", "debug", "true")
+	si.Add("", "a", "2")
+
+	formats := []struct {
+		format   SettingsFormat
+		expected string
+	}{
+		{DefaultSettingsFormat, "z = 1\na = 2\n[server]\nport = 80\nhost = a\ndebug = true\n[app]\nname = x\n"},
+		{CanonicalSettingsFormat, "a = 2\nz = 1\n\n[app]\nname = x\n\n[server]\ndebug = true\nhost = a\nport = 80\n"},
+		{SettingsFormat{Order: SettingsOrderAlphabetical, Align: true, Spacing: true, LineEnding: "\r\n"}, "a = 2\r\nz = 1\r\n[app]\r\nname = x\r\n[server]\r\ndebug = true\r\nhost  = a\r\nport  = 80"},
+		{SettingsFormat{
+			CompareHeaders: func(a, b string) int { return strings.Compare(b, a) },
+			CompareKeys:    func(header, a, b string) int { return len(a) - len(b) },
+			FinalNewline:   true,
+		}, "a=2\nz=1\n[server]\nhost=a\nport=80\ndebug=true\n[app]\nname=x\n"},
+	}
+
+	for i, test := range formats {
+		var buffer bytes.Buffer
+
+		if err := si.Write(&buffer, test.format); err != nil || buffer.String() != test.expected {
+			t.Errorf("Format %d: %q != %q (error: %v)\n", i, buffer.String(), test.expected, err)
+		}
+	}
+
+	//saving twice results in the same file
+	filename := testSettingsINIWrite(t, "saved.ini", "")
+	si.Save(filename)
+	first, _ := os.ReadFile(filename)
+	si.Save(filename)
+	second, _ := os.ReadFile(filename)
+
+	if string(first) != string(second) || string(first) != formats[0].expected {
+		t.Errorf("Expected deterministic output, got %q and %q\n", first, second)
+	}
+}
+
+func TestFormatSettingsINI(t *testing.T) {
+	filename := testSettingsINIWrite(t, "canonical.ini", "[b]\ny=1\nx = 2\n[a]\nk  =  v\n")
+
+	if err := FormatSettingsINI(filename, CanonicalSettingsFormat); err != nil {
+		t.Fatalf("Failed to format file, error: %s\n", err.Error())
+	}
+
+	data, _ := os.ReadFile(filename)
+
+	if expected := "[a]\nk = v\n\n[b]\nx = 2\ny = 1\n"; string(data) != expected {
+		t.Errorf("%q != %q\n", data, expected)
+	}
+
+	//comments are kept with the header or variable following them
+	filename = testSettingsINIWrite(t, "comments.ini", "// important note\n[s]\n// port comment\nport=1\n// a\na = 2\n// end\n")
+
+	if err := FormatSettingsINI(filename, CanonicalSettingsFormat); err != nil {
+		t.Fatalf("Failed to format file, error: %s\n", err.Error())
+	}
+
+	data, _ = os.ReadFile(filename)
+
+	if expected := "// important note\n[s]\n// a\na = 2\n// port comment\nport = 1\n// end\n"; string(data) != expected {
+		t.Errorf("%q != %q\n", data, expected)
+	}
+
+	if err := FormatSettingsINI(testSettingsINIWrite(t, "broken.ini", "[a\n"), CanonicalSettingsFormat); err == nil {
+		t.Errorf("Expected formatting a broken file to fail\n")
+	}
+}
@@ -0,0 +1,241 @@
+package fio
+
+import (
+	"sync"
+)
+
+//SettingsWildcard matches any header or any name when subscribing to changes
+const SettingsWildcard = "*"
+
+//SettingsDispatch is the type used to specify how change callbacks are called
+type SettingsDispatch byte
+
+//The various dispatch modes to use in conjunction with the SettingsDispatch
+//type
+const (
+	SettingsDispatchSync  SettingsDispatch = iota //callbacks are called by the goroutine changing the settings, before the change returns
+	SettingsDispatchAsync                         //callbacks are called in order by a dispatcher goroutine
+)
+
+//settingsObserver is a single subscription, either to the changes of a single
+//value pair or to the grouped changes within a header
+type settingsObserver struct {
+	header  string
+	name    string
+	change  func(old, new string)
+	changes func(changes []SettingsChange)
+}
+
+//matches checks if the observer is interested in the change
+func (so *settingsObserver) matches(change SettingsChange) bool {
+	return (so.header == SettingsWildcard || so.header == change.Header) && (so.name == SettingsWildcard || so.name == change.Name)
+}
+
+//settingsObservers keeps track of the subscriptions of a SettingsINI instance
+//and of the changes waiting for the dispatcher goroutine
+type settingsObservers struct {
+	lock      sync.Mutex
+	next      int
+	observers map[int]*settingsObserver
+	order     []int
+	dispatch  SettingsDispatch
+	queue     [][]SettingsChange
+	running   bool
+}
+
+//call calls the callbacks of all observers interested in the changes
+func (so *settingsObservers) call(changes []SettingsChange) {
+	so.lock.Lock()
+	observers := make([]*settingsObserver, 0, len(so.order))
+
+	for _, id := range so.order {
+		observers = append(observers, so.observers[id])
+	}
+
+	so.lock.Unlock()
+
+	for _, observer := range observers {
+		if observer.change != nil {
+			for _, change := range changes {
+				if observer.matches(change) {
+					observer.change(change.Old, change.New)
+				}
+			}
+
+			continue
+		}
+
+		var matching []SettingsChange
+
+		for _, change := range changes {
+			if observer.matches(change) {
+				matching = append(matching, change)
+			}
+		}
+
+		if len(matching) != 0 {
+			observer.changes(matching)
+		}
+	}
+}
+
+//notify passes a group of changes to the callbacks, either directly or
+//through the dispatcher goroutine. The dispatcher goroutine exits when no
+//changes are waiting.
+func (so *settingsObservers) notify(changes []SettingsChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	so.lock.Lock()
+
+	if so.dispatch == SettingsDispatchSync {
+		so.lock.Unlock()
+		so.call(changes)
+		return
+	}
+
+	so.queue = append(so.queue, changes)
+
+	if so.running {
+		so.lock.Unlock()
+		return
+	}
+
+	so.running = true
+	so.lock.Unlock()
+
+	go func() {
+		for {
+			so.lock.Lock()
+
+			if len(so.queue) == 0 {
+				so.running = false
+				so.lock.Unlock()
+				return
+			}
+
+			next := so.queue[0]
+			so.queue = so.queue[1:]
+			so.lock.Unlock()
+
+			so.call(next)
+		}
+	}()
+}
+
+//SettingsSubscription is returned when subscribing to changes, it is used to
+//unsubscribe
+type SettingsSubscription struct {
+	observers *settingsObservers
+	id        int
+}
+
+//Unsubscribe stops calling the callback of the subscription. A callback that
+//is already being called by the dispatcher goroutine may still finish.
+func (ss *SettingsSubscription) Unsubscribe() {
+	ss.observers.lock.Lock()
+	defer ss.observers.lock.Unlock()
+
+	if _, ok := ss.observers.observers[ss.id]; !ok {
+		return
+	}
+
+	delete(ss.observers.observers, ss.id)
+
+	for i, id := range ss.observers.order {
+		if id == ss.id {
+			ss.observers.order = append(ss.observers.order[:i], ss.observers.order[i+1:]...)
+			break
+		}
+	}
+}
+
+//subscribe adds an observer, creating the subscription administration if
+//required
+func (si *SettingsINI) subscribe(observer *settingsObserver) *SettingsSubscription {
+	if si.observers == nil {
+		si.observers = &settingsObservers{observers: make(map[int]*settingsObserver)}
+	}
+
+	si.observers.lock.Lock()
+	defer si.observers.lock.Unlock()
+
+	id := si.observers.next
+	si.observers.next++
+	si.observers.observers[id] = observer
+	si.observers.order = append(si.observers.order, id)
+
+	return &SettingsSubscription{si.observers, id}
+}
+
+//OnChange calls the callback with the old and new value whenever the value
+//pair changes through Add(...), Set(...), Load(...) or a batch. Either header
+//or name (or both) can be SettingsWildcard to subscribe to all headers or all
+//names. Added value pairs have an empty old value and removed value pairs have
+//an empty new value, use OnChanges(...) to distinguish these from empty values.
+//Callbacks are called in order of subscription.
+func (si *SettingsINI) OnChange(header, name string, callback func(old, new string)) *SettingsSubscription {
+	return si.subscribe(&settingsObserver{header, name, callback, nil})
+}
+
+//OnChanges calls the callback with all changes within the header (or within
+//all headers if header is SettingsWildcard) caused by a single operation, such
+//as a batch or a reload.
+func (si *SettingsINI) OnChanges(header string, callback func(changes []SettingsChange)) *SettingsSubscription {
+	return si.subscribe(&settingsObserver{header, SettingsWildcard, nil, callback})
+}
+
+//SetDispatch selects how the callbacks are called, see SettingsDispatch. By
+//default callbacks are called synchronously.
+func (si *SettingsINI) SetDispatch(dispatch SettingsDispatch) {
+	if si.observers == nil {
+		si.observers = &settingsObservers{observers: make(map[int]*settingsObserver)}
+	}
+
+	si.observers.lock.Lock()
+	si.observers.dispatch = dispatch
+	si.observers.lock.Unlock()
+}
+
+//record notifies the subscribers of changes, or keeps the changes until the
+//current batch finishes
+func (si *SettingsINI) record(changes ...SettingsChange) {
+	switch {
+	case si.observers == nil || len(changes) == 0:
+	case si.batching:
+		si.pending = append(si.pending, changes...)
+	default:
+		si.observers.notify(changes)
+	}
+}
+
+//batch calls the function, collecting the changes it makes instead of
+//notifying the subscribers. Nested batches are part of the outer batch, in
+//which case no changes are returned. If the function panics the batch is
+//ended without notifying its changes.
+func (si *SettingsINI) batch(update func(si *SettingsINI) error) ([]SettingsChange, error) {
+	if si.batching {
+		return nil, update(si)
+	}
+
+	si.batching = true
+
+	defer func() {
+		si.batching, si.pending = false, nil
+	}()
+
+	err := update(si)
+	return si.pending, err
+}
+
+//Batch calls the function to change several value pairs, after which the
+//subscribers receive a single grouped notification. The changes made before
+//the function returns an error are not undone (see SyncSettingsINI.Update(...)
+//for atomic updates) and are notified as well.
+func (si *SettingsINI) Batch(update func(si *SettingsINI) error) error {
+	changes, err := si.batch(update)
+	si.record(changes...)
+
+	return err
+}
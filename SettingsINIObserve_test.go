@@ -0,0 +1,153 @@
+package fio
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSettingsINIOnChange(t *testing.T) {
+	si := NewSettingsINI(16)
+	si.Add("pool", "size", "4")
+
+	var sizes []string
+	var all []SettingsChange
+	var groups int
+
+	size := si.OnChange("pool", "size", func(old, new string) {
+		sizes = append(sizes, old+">"+new)
+	})
+
+	si.OnChanges(SettingsWildcard, func(changes []SettingsChange) {
+		all = append(all, changes...)
+		groups++
+	})
+
+	si.Set("pool", "size", "8")
+	si.Set("pool", "size", "8")
+	si.Add("log", "level", "debug")
+
+	if !reflect.DeepEqual(sizes, []string{"4>8"}) || groups != 2 {
+		t.Errorf("Unexpected notifications %v in %d groups\n", sizes, groups)
+	}
+
+	//a batch results in a single grouped notification
+	err := si.Batch(func(si *SettingsINI) error {
+		si.Set("pool", "size", "16")
+		return si.Set("log", "level", "info")
+	})
+
+	expected := []SettingsChange{
+		{SettingsModified, "pool", "size", "4", "8"},
+		{SettingsAdded, "log", "level", "", "debug"},
+		{SettingsModified, "pool", "size", "8", "16"},
+		{SettingsModified, "log", "level", "debug", "info"},
+	}
+
+	if err != nil || groups != 3 || !reflect.DeepEqual(all, expected) {
+		t.Errorf("%v != %v in %d groups (error: %v)\n", all, expected, groups, err)
+	}
+
+	//unsubscribed callbacks are no longer called
+	size.Unsubscribe()
+	size.Unsubscribe()
+	si.Set("pool", "size", "32")
+
+	if len(sizes) != 2 {
+		t.Errorf("Expected 2 size notifications, got %v\n", sizes)
+	}
+
+	//reloading notifies the differences
+	filename := testSettingsINIWrite(t, "observed.ini", "[pool]\nsize = 64\n")
+	all = nil
+
+	if err = si.Load(filename); err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	expected = []SettingsChange{
+		{SettingsRemoved, "log", "level", "info", ""},
+		{SettingsModified, "pool", "size", "32", "64"},
+	}
+
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("%v != %v\n", all, expected)
+	}
+
+	//a failed load does not notify the partially loaded contents
+	all = nil
+
+	if err = si.Load(testSettingsINIWrite(t, "broken.ini", "[pool]\nsize = 1\n[pool]\n")); err == nil || len(all) != 0 {
+		t.Errorf("Expected a failed load without notifications, got %v (error: %v)\n", all, err)
+	}
+
+	si.Set("pool", "size", "64")
+
+	//a recovered panic within a batch does not swallow later changes
+	func() {
+		defer func() {
+			recover()
+		}()
+
+		si.Batch(func(si *SettingsINI) error {
+			si.Set("pool", "size", "65")
+			panic("update failed")
+		})
+	}()
+
+	all = nil
+	si.Set("pool", "size", "66")
+
+	if expected = []SettingsChange{{SettingsModified, "pool", "size", "65", "66"}}; !reflect.DeepEqual(all, expected) {
+		t.Errorf("%v != %v\n", all, expected)
+	}
+}
+
+func TestSyncSettingsINIOnChange(t *testing.T) {
+	s := NewSyncSettingsINI(NewSettingsINI(16))
+	s.Add("pool", "size", "4")
+	s.SetDispatch(SettingsDispatchAsync)
+
+	received := make(chan []SettingsChange, 4)
+
+	s.OnChanges("pool", func(changes []SettingsChange) {
+		//reading the settings from a callback does not deadlock
+		s.Get("pool", "size")
+		received <- changes
+	})
+
+	s.Update(func(si *SettingsINI) error {
+		si.Set("pool", "size", "8")
+		return si.Add("pool", "max", "10")
+	})
+
+	s.Set("pool", "size", "12")
+
+	expected := [][]SettingsChange{
+		{{SettingsModified, "pool", "size", "4", "8"}, {SettingsAdded, "pool", "max", "", "10"}},
+		{{SettingsModified, "pool", "size", "8", "12"}},
+	}
+
+	for _, group := range expected {
+		select {
+		case changes := <-received:
+			if !reflect.DeepEqual(changes, group) {
+				t.Errorf("%v != %v\n", changes, group)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for changes\n")
+		}
+	}
+
+	//failed updates are not notified
+	s.Update(func(si *SettingsINI) error {
+		si.Set("pool", "size", "0")
+		return si.Set("pool", "missing", "0")
+	})
+
+	select {
+	case changes := <-received:
+		t.Errorf("Unexpected notification %v\n", changes)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,469 @@
+package fio
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//settingsSchemaBuffer is the initial buffer size used while loading schema
+//files
+const settingsSchemaBuffer = 256
+
+//SettingsKeySchema describes a single value pair within a header. The value
+//has to be of the specified Type (Layout is the time layout used by ColumnTime
+//values, CellDateTimeLayout if empty). Numeric values (ColumnInt and
+//ColumnFloat) have to lie between Minimum and Maximum if these are not nil, and
+//if Enum is not empty the value has to be one of its values. If Deprecated is
+//not empty the key is deprecated and Deprecated describes what to use instead.
+//Default is the value used by SettingsSchema.ApplyDefaults(...), keys without a
+//default are left missing.
+type SettingsKeySchema struct {
+	Name       string
+	Type       ColumnType
+	Layout     string
+	Required   bool
+	Deprecated string
+	Default    string
+	Minimum    *float64
+	Maximum    *float64
+	Enum       []string
+}
+
+//SettingsHeaderSchema describes a header and the value pairs it may contain.
+//The headerless value pairs are described by a header with an empty name. If
+//AllowUnknown is true the header may contain keys that are not described.
+type SettingsHeaderSchema struct {
+	Name         string
+	Required     bool
+	Deprecated   string
+	AllowUnknown bool
+	Keys         []SettingsKeySchema
+}
+
+//SettingsSchema describes the allowed contents of a SettingsINI instance. The
+//schema is either declared in Go or loaded from a schema file using
+//Load(...). If AllowUnknownHeaders is true the settings may contain headers
+//that are not described.
+type SettingsSchema struct {
+	Headers             []SettingsHeaderSchema
+	AllowUnknownHeaders bool
+}
+
+//SettingsViolation describes a single mismatch between the settings and a
+//schema. Line is the line on which the header or value pair was loaded, or 0
+//if it is missing (or was not loaded from a file). Warnings (such as the use
+//of deprecated keys) do not make the settings invalid.
+type SettingsViolation struct {
+	Line    int
+	Header  string
+	Name    string
+	Message string
+	Warning bool
+}
+
+func (sv SettingsViolation) String() string {
+	var result strings.Builder
+
+	if sv.Line > 0 {
+		result.WriteString("line " + strconv.Itoa(sv.Line) + ": ")
+	}
+
+	result.WriteString("[" + sv.Header + "]")
+
+	if len(sv.Name) != 0 {
+		result.WriteString(" " + sv.Name)
+	}
+
+	if sv.Warning {
+		result.WriteString(" (warning)")
+	}
+
+	return result.String() + ": " + sv.Message
+}
+
+//header returns the description of the header with the specified name
+func (ss *SettingsSchema) header(name string) *SettingsHeaderSchema {
+	for i := range ss.Headers {
+		if ss.Headers[i].Name == name {
+			return &ss.Headers[i]
+		}
+	}
+
+	return nil
+}
+
+//key returns the description of the key with the specified name
+func (shs *SettingsHeaderSchema) key(name string) *SettingsKeySchema {
+	for i := range shs.Keys {
+		if shs.Keys[i].Name == name {
+			return &shs.Keys[i]
+		}
+	}
+
+	return nil
+}
+
+//check returns a description of the mismatch between the value and the key
+//description, or an empty string if the value is valid
+func (sks *SettingsKeySchema) check(value string) string {
+	parsed, err := SchemaColumn{sks.Name, sks.Type, false, sks.Layout}.parse(value)
+
+	if err != nil {
+		return err.(Error).Message
+	}
+
+	var number float64
+
+	switch v := parsed.(type) {
+	case int64:
+		number = float64(v)
+	case float64:
+		number = v
+	}
+
+	if sks.Type == ColumnInt || sks.Type == ColumnFloat {
+		if sks.Minimum != nil && number < *sks.Minimum {
+			return "Value " + value + " is smaller than the minimum of " + formatFloat(*sks.Minimum)
+		}
+
+		if sks.Maximum != nil && number > *sks.Maximum {
+			return "Value " + value + " is larger than the maximum of " + formatFloat(*sks.Maximum)
+		}
+	}
+
+	if len(sks.Enum) != 0 {
+		for _, allowed := range sks.Enum {
+			if value == allowed {
+				return ""
+			}
+		}
+
+		return "Value '" + value + "' is not one of '" + strings.Join(sks.Enum, "', '") + "'"
+	}
+
+	return ""
+}
+
+//Validate checks the settings against the schema and returns every violation,
+//in order of line number. Missing headers and keys are reported on the line
+//of their header (or line 0 if the header is missing as well).
+func (ss *SettingsSchema) Validate(si *SettingsINI) []SettingsViolation {
+	var violations []SettingsViolation
+
+	//check the described headers and keys
+	for _, header := range ss.Headers {
+		values, exists := si.Headers[header.Name]
+		headerLine := si.Line(header.Name, "")
+
+		if !exists {
+			if header.Required {
+				violations = append(violations, SettingsViolation{0, header.Name, "", "Required header is missing", false})
+			}
+		} else if len(header.Deprecated) != 0 {
+			violations = append(violations, SettingsViolation{headerLine, header.Name, "", "Header is deprecated: " + header.Deprecated, true})
+		}
+
+		for _, key := range header.Keys {
+			value, ok := "", false
+
+			if exists {
+				value, ok = values.Values[key.Name]
+			}
+
+			switch {
+			case !ok && key.Required && (exists || header.Required):
+				violations = append(violations, SettingsViolation{headerLine, header.Name, key.Name, "Required key is missing", false})
+			case !ok:
+			default:
+				line := si.Line(header.Name, key.Name)
+
+				if message := key.check(value); len(message) != 0 {
+					violations = append(violations, SettingsViolation{line, header.Name, key.Name, message, false})
+				}
+
+				if len(key.Deprecated) != 0 {
+					violations = append(violations, SettingsViolation{line, header.Name, key.Name, "Key is deprecated: " + key.Deprecated, true})
+				}
+			}
+		}
+	}
+
+	//check for headers and keys that are not described
+	for name, values := range si.Headers {
+		header := ss.header(name)
+
+		if header == nil {
+			if !ss.AllowUnknownHeaders {
+				violations = append(violations, SettingsViolation{si.Line(name, ""), name, "", "Unknown header", false})
+			}
+
+			continue
+		}
+
+		if header.AllowUnknown {
+			continue
+		}
+
+		for key := range values.Values {
+			if header.key(key) == nil {
+				violations = append(violations, SettingsViolation{si.Line(name, key), name, key, "Unknown key", false})
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+
+		switch {
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Header != b.Header:
+			return a.Header < b.Header
+		}
+
+		return a.Name < b.Name
+	})
+
+	return violations
+}
+
+//Valid returns true if the settings do not violate the schema, ignoring
+//warnings
+func (ss *SettingsSchema) Valid(si *SettingsINI) bool {
+	for _, violation := range ss.Validate(si) {
+		if !violation.Warning {
+			return false
+		}
+	}
+
+	return true
+}
+
+//ApplyDefaults adds the default value of every described key that is missing
+//from the settings, creating headers where required. The function returns the
+//number of added value pairs.
+func (ss *SettingsSchema) ApplyDefaults(si *SettingsINI) int {
+	count := 0
+
+	for _, header := range ss.Headers {
+		for _, key := range header.Keys {
+			if len(key.Default) != 0 && si.Add(header.Name, key.Name, key.Default) == nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+//settingsSchemaSection is a section of a schema file, in the order of the file
+type settingsSchemaSection struct {
+	name string
+	line int
+}
+
+//settingsSchemaBool parses a boolean option of a schema file
+func settingsSchemaBool(value string, line int) (bool, error) {
+	result, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(line) + ": expected a boolean instead of '" + value + "'"}
+	}
+
+	return result, nil
+}
+
+//settingsSchemaDeprecated parses the deprecated option of a schema file, which
+//is either a boolean or a message
+func settingsSchemaDeprecated(value string) string {
+	if deprecated, err := strconv.ParseBool(value); err == nil {
+		if deprecated {
+			return "no replacement"
+		}
+
+		return ""
+	}
+
+	return value
+}
+
+//Load replaces the schema by the one described in a schema file. The schema
+//file is an .ini file (see SettingsINI) wherein every header describes either
+//a header or a key of the settings:
+//
+//	allow_unknown_headers = false
+//
+//	[server]
+//	required = true
+//	allow_unknown = false
+//
+//	[server.port]
+//	type = int
+//	min = 1
+//	max = 65535
+//	default = 8080
+//
+//	[server.mode]
+//	enum = debug, release
+//	deprecated = use [runtime] mode instead
+//
+//The part after the last dot is the name of the key, '[.name]' describes a
+//headerless key. Headers support the required, deprecated (a boolean or a
+//message) and allow_unknown options, keys support the type (string, int,
+//float, bool or time), layout, required, deprecated, default, min, max and enum
+//(a comma-seperated list) options. Headers that only occur as part of a key are
+//added as optional headers.
+func (ss *SettingsSchema) Load(filename string) error {
+	file := NewSettingsINI(settingsSchemaBuffer)
+
+	if err := file.Load(filename); err != nil {
+		return err
+	}
+
+	schema := SettingsSchema{}
+	var sections []settingsSchemaSection
+
+	for name := range file.Headers {
+		sections = append(sections, settingsSchemaSection{name, file.Line(name, "")})
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].line < sections[j].line
+	})
+
+	for _, section := range sections {
+		values := file.Headers[section.name].Values
+		var options []settingsSchemaSection
+
+		for option := range values {
+			options = append(options, settingsSchemaSection{option, file.Line(section.name, option)})
+		}
+
+		sort.Slice(options, func(i, j int) bool {
+			return options[i].line < options[j].line
+		})
+
+		if len(section.name) == 0 {
+			for _, option := range options {
+				if option.name != "allow_unknown_headers" {
+					return Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(option.line) + ": unknown option '" + option.name + "'"}
+				}
+
+				var err error
+
+				if schema.AllowUnknownHeaders, err = settingsSchemaBool(values[option.name], option.line); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		headerName, keyName, isKey := section.name, "", false
+
+		if dot := strings.LastIndexByte(section.name, '.'); dot >= 0 {
+			headerName, keyName, isKey = section.name[:dot], section.name[dot+1:], true
+		}
+
+		header := schema.header(headerName)
+
+		if header == nil {
+			schema.Headers = append(schema.Headers, SettingsHeaderSchema{Name: headerName})
+			header = &schema.Headers[len(schema.Headers)-1]
+		}
+
+		if !isKey {
+			for _, option := range options {
+				value := values[option.name]
+				var err error
+
+				switch option.name {
+				case "required":
+					header.Required, err = settingsSchemaBool(value, option.line)
+				case "deprecated":
+					header.Deprecated = settingsSchemaDeprecated(value)
+				case "allow_unknown":
+					header.AllowUnknown, err = settingsSchemaBool(value, option.line)
+				default:
+					err = Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(option.line) + ": unknown header option '" + option.name + "'"}
+				}
+
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if len(keyName) == 0 || header.key(keyName) != nil {
+			return Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(section.line) + ": invalid or duplicate key '" + section.name + "'"}
+		}
+
+		key := SettingsKeySchema{Name: keyName}
+
+		for _, option := range options {
+			value := values[option.name]
+			var err error
+
+			switch option.name {
+			case "type":
+				valid := false
+
+				for _, columnType := range []ColumnType{ColumnString, ColumnInt, ColumnFloat, ColumnBool, ColumnTime} {
+					if value == columnType.String() {
+						key.Type, valid = columnType, true
+					}
+				}
+
+				if !valid {
+					err = Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(option.line) + ": unknown type '" + value + "'"}
+				}
+			case "layout":
+				key.Layout = value
+			case "required":
+				key.Required, err = settingsSchemaBool(value, option.line)
+			case "deprecated":
+				key.Deprecated = settingsSchemaDeprecated(value)
+			case "default":
+				key.Default = value
+			case "min", "max":
+				var bound float64
+
+				if bound, err = strconv.ParseFloat(value, 64); err != nil {
+					err = Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(option.line) + ": expected a number instead of '" + value + "'"}
+				} else if option.name == "min" {
+					key.Minimum = &bound
+				} else {
+					key.Maximum = &bound
+				}
+			case "enum":
+				for _, allowed := range strings.Split(value, ",") {
+					key.Enum = append(key.Enum, strings.TrimSpace(allowed))
+				}
+			default:
+				err = Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(option.line) + ": unknown key option '" + option.name + "'"}
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if key.Type == ColumnTime && len(key.Layout) == 0 {
+			key.Layout = CellDateTimeLayout
+		}
+
+		if len(key.Default) != 0 {
+			if message := key.check(key.Default); len(message) != 0 {
+				return Error{ErrorTypeParsing, "SettingsSchema", "Line " + strconv.Itoa(section.line) + ": invalid default: " + message}
+			}
+		}
+
+		header.Keys = append(header.Keys, key)
+	}
+
+	*ss = schema
+	return nil
+}
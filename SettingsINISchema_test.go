@@ -0,0 +1,166 @@
+package fio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testSettingsINIWrite(t *testing.T, name, contents string) string {
+	filename := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write test file, error: %s\n", err.Error())
+	}
+
+	return filename
+}
+
+func TestSettingsINILine(t *testing.T) {
+	si := NewSettingsINI(16)
+
+	if err := si.Load(testSettingsINIWrite(t, "lines.ini", "a = 1\n\n[server]\n//comment\nport = 80\n")); err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	if si.Line("", "a") != 1 || si.Line("server", "") != 3 || si.Line("server", "port") != 5 || si.Line("server", "missing") != 0 {
+		t.Errorf("Unexpected line numbers %v\n", si.lines)
+	}
+}
+
+func TestSettingsSchemaLoad(t *testing.T) {
+	schemaFile := testSettingsINIWrite(t, "schema.ini", `allow_unknown_headers = false
+
+[server]
+required = true
+
+[server.port]
+type = int
+min = 1
+max = 65535
+default = 8080
+required = true
+
+[server.mode]
+enum = debug, release
+deprecated = use [runtime] mode instead
+
+[.name]
+required = true
+
+[log]
+allow_unknown = true
+
+[log.level]
+default = info
+`)
+
+	var schema SettingsSchema
+
+	if err := schema.Load(schemaFile); err != nil {
+		t.Fatalf("Failed to load schema, error: %s\n", err.Error())
+	}
+
+	minimum, maximum := 1.0, 65535.0
+	expected := SettingsSchema{[]SettingsHeaderSchema{
+		{"server", true, "", false, []SettingsKeySchema{
+			{"port", ColumnInt, "", true, "", "8080", &minimum, &maximum, nil},
+			{"mode", ColumnString, "", false, "use [runtime] mode instead", "", nil, nil, []string{"debug", "release"}},
+		}},
+		{"", false, "", false, []SettingsKeySchema{{Name: "name", Required: true}}},
+		{"log", false, "", true, []SettingsKeySchema{{Name: "level", Default: "info"}}},
+	}, false}
+
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("%+v != %+v\n", schema, expected)
+	}
+
+	invalid := []string{
+		"[server.port]\ntype = complex\n",
+		"[server]\nrequired = maybe\n",
+		"[server.port]\nunknown = 1\n",
+		"[server.port]\ntype = int\ndefault = x\n",
+	}
+
+	for i, contents := range invalid {
+		if err := schema.Load(testSettingsINIWrite(t, "invalid.ini", contents)); err == nil {
+			t.Errorf("Schema %d: expected loading to fail\n", i)
+		}
+	}
+}
+
+func TestSettingsSchemaValidate(t *testing.T) {
+	minimum, maximum := 1.0, 65535.0
+	schema := SettingsSchema{[]SettingsHeaderSchema{
+		{"server", true, "", false, []SettingsKeySchema{
+			{"port", ColumnInt, "", true, "", "8080", &minimum, &maximum, nil},
+			{"mode", ColumnString, "", false, "use [runtime] mode instead", "", nil, nil, []string{"debug", "release"}},
+			{"timeout", ColumnFloat, "", false, "", "2.5", nil, nil, nil},
+		}},
+		{"", false, "", false, []SettingsKeySchema{{Name: "name", Required: true}}},
+		{"database", true, "", false, []SettingsKeySchema{{Name: "url", Required: true}}},
+	}, false}
+
+	si := NewSettingsINI(16)
+	err := si.Load(testSettingsINIWrite(t, "settings.ini", "nmae = x\n[server]\nport = 70000\nmode = test\nprot = 1\n[extra]\na = b\n"))
+
+	if err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	expected := []SettingsViolation{
+		{0, "", "name", "Required key is missing", false},
+		{0, "database", "", "Required header is missing", false},
+		{0, "database", "url", "Required key is missing", false},
+		{1, "", "nmae", "Unknown key", false},
+		{3, "server", "port", "Value 70000 is larger than the maximum of 65535", false},
+		{4, "server", "mode", "Value 'test' is not one of 'debug', 'release'", false},
+		{4, "server", "mode", "Key is deprecated: use [runtime] mode instead", true},
+		{5, "server", "prot", "Unknown key", false},
+		{6, "extra", "", "Unknown header", false},
+	}
+
+	if violations := schema.Validate(si); !reflect.DeepEqual(violations, expected) {
+		t.Errorf("%v != %v\n", violations, expected)
+	}
+
+	if schema.Valid(si) {
+		t.Errorf("Expected the settings to be invalid\n")
+	}
+
+	//defaults only fill in missing values
+	if count := schema.ApplyDefaults(si); count != 1 {
+		t.Errorf("Expected 1 added default, got %d\n", count)
+	}
+
+	if port, _ := si.Get("server", "port"); port != "70000" {
+		t.Errorf("Expected the existing port to remain, got '%s'\n", port)
+	}
+
+	if timeout, _ := si.Get("server", "timeout"); timeout != "2.5" {
+		t.Errorf("Expected the default timeout, got '%s'\n", timeout)
+	}
+
+	valid := NewSettingsINI(16)
+	valid.Add("", "name", "x")
+	valid.Add("server", "port", "80")
+	valid.Add("server", "mode", "debug")
+	valid.Add("database", "url", "localhost")
+
+	if !schema.Valid(valid) {
+		t.Errorf("Expected the settings to be valid, violations: %v\n", schema.Validate(valid))
+	}
+
+	//time keys without a layout use CellDateTimeLayout
+	schema = SettingsSchema{[]SettingsHeaderSchema{
+		{"", false, "", false, []SettingsKeySchema{{Name: "started", Type: ColumnTime}}},
+	}, false}
+
+	valid = NewSettingsINI(16)
+	valid.Add("", "started", "2024-01-02 03:04:05")
+
+	if !schema.Valid(valid) {
+		t.Errorf("Expected the time to be valid, violations: %v\n", schema.Validate(valid))
+	}
+}
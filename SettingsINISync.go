@@ -0,0 +1,332 @@
+package fio
+
+import (
+	"iter"
+	"sync"
+)
+
+//SyncSettingsINI wraps a SettingsINI instance such that it can be shared
+//between goroutines. All methods are guarded by a read/write lock, the wrapped
+//instance should not be accessed directly after wrapping it. Readers that need
+//a consistent view of several values should use Read(...) or Snapshot(), and
+//related changes should be applied together using Update(...). New instances
+//should be created using NewSyncSettingsINI(...).
+type SyncSettingsINI struct {
+	lock     sync.RWMutex
+	settings *SettingsINI
+}
+
+//NewSyncSettingsINI wraps the specified settings and returns the pointer to
+//the wrapper
+func NewSyncSettingsINI(si *SettingsINI) *SyncSettingsINI {
+	return &SyncSettingsINI{settings: si}
+}
+
+//Load loads the file into a new SettingsINI instance and replaces the wrapped
+//settings only if loading succeeds, see SettingsINI.Load(...). Readers are not
+//blocked while the file is read.
+func (s *SyncSettingsINI) Load(filename string) error {
+	s.lock.RLock()
+	loaded := NewSettingsINI(s.settings.buffer)
+	loaded.Filename = s.settings.Filename
+	s.lock.RUnlock()
+
+	if err := loaded.Load(filename); err != nil {
+		return err
+	}
+
+	s.Replace(loaded)
+	return nil
+}
+
+//Save stores the settings to a file, see SettingsINI.Save(...)
+func (s *SyncSettingsINI) Save(filename string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.Save(filename)
+}
+
+//HeaderExists see SettingsINI.HeaderExists(...)
+func (s *SyncSettingsINI) HeaderExists(header string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.HeaderExists(header)
+}
+
+//ValueExists see SettingsINI.ValueExists(...)
+func (s *SyncSettingsINI) ValueExists(header, name string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.ValueExists(header, name)
+}
+
+//locked calls the function while holding the write lock and notifies the
+//subscribers of the returned changes after releasing the lock, such that
+//synchronous callbacks can read the settings. The lock is released as well if
+//the function panics, in which case nothing is notified.
+func (s *SyncSettingsINI) locked(update func() ([]SettingsChange, error)) error {
+	var observers *settingsObservers
+
+	changes, err := func() ([]SettingsChange, error) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		changes, err := update()
+		observers = s.settings.observers
+		return changes, err
+	}()
+
+	if observers != nil {
+		observers.notify(changes)
+	}
+
+	return err
+}
+
+//apply calls the function with the wrapped settings while holding the write
+//lock, see locked(...)
+func (s *SyncSettingsINI) apply(update func(si *SettingsINI) error) error {
+	return s.locked(func() ([]SettingsChange, error) {
+		return s.settings.batch(update)
+	})
+}
+
+//Add see SettingsINI.Add(...)
+func (s *SyncSettingsINI) Add(header, name, value string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.Add(header, name, value)
+	})
+}
+
+//Set see SettingsINI.Set(...)
+func (s *SyncSettingsINI) Set(header, name, value string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.Set(header, name, value)
+	})
+}
+
+//DeleteValue see SettingsINI.DeleteValue(...)
+func (s *SyncSettingsINI) DeleteValue(header, name string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.DeleteValue(header, name)
+	})
+}
+
+//DeleteHeader see SettingsINI.DeleteHeader(...)
+func (s *SyncSettingsINI) DeleteHeader(header string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.DeleteHeader(header)
+	})
+}
+
+//RenameHeader see SettingsINI.RenameHeader(...)
+func (s *SyncSettingsINI) RenameHeader(header, newHeader string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.RenameHeader(header, newHeader)
+	})
+}
+
+//RenameValue see SettingsINI.RenameValue(...)
+func (s *SyncSettingsINI) RenameValue(header, name, newName string) error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.RenameValue(header, name, newName)
+	})
+}
+
+//HeaderNames see SettingsINI.HeaderNames()
+func (s *SyncSettingsINI) HeaderNames() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.HeaderNames()
+}
+
+//Keys see SettingsINI.Keys(...)
+func (s *SyncSettingsINI) Keys(header string) []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.Keys(header)
+}
+
+//AllHeaders see SettingsINI.AllHeaders(), the headers are listed as they were
+//when the iteration started
+func (s *SyncSettingsINI) AllHeaders() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, name := range s.HeaderNames() {
+			if !yield(i, name) {
+				return
+			}
+		}
+	}
+}
+
+//AllValues see SettingsINI.AllValues(...), the values are listed as they were
+//when the iteration started
+func (s *SyncSettingsINI) AllValues(header string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		s.lock.RLock()
+		names := s.settings.Keys(header)
+		values := make([]string, len(names))
+
+		for i, name := range names {
+			values[i], _ = s.settings.Get(header, name)
+		}
+
+		s.lock.RUnlock()
+
+		for i, name := range names {
+			if !yield(name, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+//Get see SettingsINI.Get(...)
+func (s *SyncSettingsINI) Get(header, name string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.Get(header, name)
+}
+
+//GetInt see SettingsINI.GetInt(...)
+func (s *SyncSettingsINI) GetInt(header, name string) (int, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.GetInt(header, name)
+}
+
+//GetUint see SettingsINI.GetUint(...)
+func (s *SyncSettingsINI) GetUint(header, name string) (uint, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.GetUint(header, name)
+}
+
+//GetFloat32 see SettingsINI.GetFloat32(...)
+func (s *SyncSettingsINI) GetFloat32(header, name string) (float32, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.GetFloat32(header, name)
+}
+
+//GetFloat64 see SettingsINI.GetFloat64(...)
+func (s *SyncSettingsINI) GetFloat64(header, name string) (float64, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.GetFloat64(header, name)
+}
+
+//Snapshot returns a deep copy of the settings, which is not affected by later
+//changes
+func (s *SyncSettingsINI) Snapshot() *SettingsINI {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.settings.Copy()
+}
+
+//Read calls the function with the wrapped settings while holding the read
+//lock, such that all values read by the function are consistent. The function
+//should not change the settings or keep a reference to them.
+func (s *SyncSettingsINI) Read(read func(si *SettingsINI)) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	read(s.settings)
+}
+
+//Update applies a batch of changes atomically. The function is called with a
+//copy of the settings while holding the write lock, if it returns nil the copy
+//replaces the wrapped settings. If it returns an error the changes are
+//discarded and the error is returned, the same applies if it panics. Other
+//goroutines either see all or none of the changes, and subscribers receive a
+//single grouped notification.
+func (s *SyncSettingsINI) Update(update func(si *SettingsINI) error) error {
+	return s.locked(func() ([]SettingsChange, error) {
+		updated := s.settings.Copy()
+		updated.observers = s.settings.observers
+
+		if s.settings.history != nil {
+			updated.history = s.settings.history.copy()
+		}
+
+		changes, err := updated.batch(update)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.settings = updated
+		return changes, nil
+	})
+}
+
+//Replace replaces the wrapped settings and returns the previous ones. The
+//subscriptions and commit hooks are moved to the new settings (unless these
+//have their own) and receive the differences as a single grouped notification.
+//The undo history is not moved.
+func (s *SyncSettingsINI) Replace(si *SettingsINI) *SettingsINI {
+	previous, _ := s.replace(si)
+	return previous
+}
+
+//replace implements Replace(...) and returns the differences as well. These
+//are determined while holding the write lock, as the new settings may be
+//changed by other goroutines as soon as the lock is released.
+func (s *SyncSettingsINI) replace(si *SettingsINI) (*SettingsINI, []SettingsChange) {
+	s.lock.Lock()
+	previous := s.settings
+
+	if si.observers == nil {
+		si.observers = previous.observers
+	}
+
+	if si.history == nil && previous.history != nil {
+		si.history = &settingsHistory{hooks: previous.history.hooks}
+	}
+
+	changes := settingsChanges(previous, si)
+	s.settings = si
+	s.lock.Unlock()
+
+	if si.observers != nil {
+		si.observers.notify(changes)
+	}
+
+	return previous, changes
+}
+
+//OnChange see SettingsINI.OnChange(...). Callbacks are called after the lock
+//is released, so synchronous callbacks can read the settings.
+func (s *SyncSettingsINI) OnChange(header, name string, callback func(old, new string)) *SettingsSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.settings.OnChange(header, name, callback)
+}
+
+//OnChanges see SettingsINI.OnChanges(...)
+func (s *SyncSettingsINI) OnChanges(header string, callback func(changes []SettingsChange)) *SettingsSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.settings.OnChanges(header, callback)
+}
+
+//SetDispatch see SettingsINI.SetDispatch(...)
+func (s *SyncSettingsINI) SetDispatch(dispatch SettingsDispatch) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.settings.SetDispatch(dispatch)
+}
@@ -0,0 +1,130 @@
+package fio
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncSettingsINIUpdate(t *testing.T) {
+	si := NewSettingsINI(16)
+	si.Add("pool", "min", "0")
+	si.Add("pool", "max", "0")
+	s := NewSyncSettingsINI(si)
+
+	var wait sync.WaitGroup
+
+	//writers change both values together, readers should never see them differ
+	for i := 1; i <= 4; i++ {
+		wait.Add(2)
+
+		go func(i int) {
+			defer wait.Done()
+
+			for j := 0; j < 100; j++ {
+				value := strconv.Itoa(i*1000 + j)
+				s.Update(func(si *SettingsINI) error {
+					si.Set("pool", "min", value)
+					return si.Set("pool", "max", value)
+				})
+			}
+		}(i)
+
+		go func() {
+			defer wait.Done()
+
+			for j := 0; j < 100; j++ {
+				s.Read(func(si *SettingsINI) {
+					min, _ := si.Get("pool", "min")
+					max, _ := si.Get("pool", "max")
+
+					if min != max {
+						t.Errorf("Read inconsistent values %s and %s\n", min, max)
+					}
+				})
+
+				snapshot := s.Snapshot()
+				min, _ := snapshot.Get("pool", "min")
+				max, _ := snapshot.Get("pool", "max")
+
+				if min != max {
+					t.Errorf("Snapshot contains inconsistent values %s and %s\n", min, max)
+				}
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	//a failing batch is discarded completely
+	before, _ := s.Get("pool", "min")
+	err := s.Update(func(si *SettingsINI) error {
+		si.Set("pool", "min", "-1")
+		return si.Set("pool", "missing", "1")
+	})
+
+	if after, _ := s.Get("pool", "min"); err == nil || after != before {
+		t.Errorf("Expected the failed batch to be discarded, got '%s' instead of '%s' (error: %v)\n", after, before, err)
+	}
+
+	//a failing batch does not change the undo history
+	tx := s.Begin()
+	tx.Set("pool", "max", "-2")
+	tx.Commit()
+
+	s.Update(func(si *SettingsINI) error {
+		si.Undo()
+		return si.Set("pool", "missing", "1")
+	})
+
+	if err = s.Undo(); err != nil {
+		t.Errorf("Expected the transaction to remain undoable, error: %s\n", err.Error())
+	}
+
+	if value, _ := s.Get("pool", "max"); value != before {
+		t.Errorf("Expected '%s' after undo, got '%s'\n", before, value)
+	}
+
+	//a panicking update releases the lock
+	for _, update := range []func(update func(si *SettingsINI) error) error{s.Update, s.apply} {
+		func() {
+			defer func() {
+				recover()
+			}()
+
+			update(func(si *SettingsINI) error {
+				si.Set("pool", "min", "-3")
+				panic("update failed")
+			})
+		}()
+	}
+
+	if err = s.Set("pool", "min", before); err != nil {
+		t.Errorf("Failed to set the value after a panic, error: %s\n", err.Error())
+	}
+
+	//snapshots are not affected by later changes
+	snapshot := s.Snapshot()
+	s.Set("pool", "min", "5")
+
+	if value, _ := snapshot.Get("pool", "min"); value != before {
+		t.Errorf("Expected the snapshot to remain '%s', got '%s'\n", before, value)
+	}
+}
+
+func TestSyncSettingsINILoad(t *testing.T) {
+	s := NewSyncSettingsINI(NewSettingsINI(16))
+
+	if err := s.Load(testSettingsINIWrite(t, "sync.ini", "[a]\nb = c\n")); err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	//a file that fails to load leaves the settings untouched
+	if err := s.Load(testSettingsINIWrite(t, "broken.ini", "[a\n")); err == nil {
+		t.Errorf("Expected loading a broken file to fail\n")
+	}
+
+	if value, ok := s.Get("a", "b"); !ok || value != "c" {
+		t.Errorf("Expected 'c', got '%s'\n", value)
+	}
+}
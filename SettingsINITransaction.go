@@ -0,0 +1,442 @@
+package fio
+
+//settingsHistory keeps track of the commit hooks and of the committed
+//transactions that can be undone and redone
+type settingsHistory struct {
+	hooks []func(si *SettingsINI) error
+	undo  []settingsEdit
+	redo  []settingsEdit
+}
+
+//copy returns a copy of the history that can be changed without affecting the
+//original
+func (sh *settingsHistory) copy() *settingsHistory {
+	return &settingsHistory{
+		append([]func(si *SettingsINI) error(nil), sh.hooks...),
+		append([]settingsEdit(nil), sh.undo...),
+		append([]settingsEdit(nil), sh.redo...),
+	}
+}
+
+//settingsEdit holds the changes of a committed transaction: the changed value
+//pairs and the headers that were created or removed
+type settingsEdit struct {
+	changes []SettingsChange
+	added   []string
+	removed []string
+}
+
+//empty returns true if the edit does not change anything
+func (se settingsEdit) empty() bool {
+	return len(se.changes) == 0 && len(se.added) == 0 && len(se.removed) == 0
+}
+
+//invert returns the edit undoing the edit
+func (se settingsEdit) invert() settingsEdit {
+	return settingsEdit{invertChanges(se.changes), se.removed, se.added}
+}
+
+//getHistory returns the history, creating it if required
+func (si *SettingsINI) getHistory() *settingsHistory {
+	if si.history == nil {
+		si.history = &settingsHistory{}
+	}
+
+	return si.history
+}
+
+//applyChanges changes the settings according to the changes, ignoring the old
+//values. Headers are created when required and, like DeleteValue(...), kept
+//when their last value pair is removed.
+func (si *SettingsINI) applyChanges(changes []SettingsChange) {
+	for _, change := range changes {
+		header, ok := si.Headers[change.Header]
+
+		if change.Type == SettingsRemoved {
+			if !ok {
+				continue
+			}
+
+			if old, exists := header.Values[change.Name]; exists {
+				delete(header.Values, change.Name)
+				si.record(SettingsChange{SettingsRemoved, change.Header, change.Name, old, ""})
+			}
+
+			continue
+		}
+
+		if !ok {
+			header = &SettingsINIHeader{make(map[string]string)}
+			si.Headers[change.Header] = header
+		}
+
+		old, exists := header.Values[change.Name]
+		header.Values[change.Name] = change.New
+
+		switch {
+		case !exists:
+			si.record(SettingsChange{SettingsAdded, change.Header, change.Name, "", change.New})
+		case old != change.New:
+			si.record(SettingsChange{SettingsModified, change.Header, change.Name, old, change.New})
+		}
+	}
+}
+
+//applyEdit creates the added headers, applies the changed value pairs and
+//removes the removed headers, including value pairs added to them outside of
+//the transaction
+func (si *SettingsINI) applyEdit(edit settingsEdit) {
+	for _, header := range edit.added {
+		if !si.HeaderExists(header) {
+			si.Headers[header] = &SettingsINIHeader{make(map[string]string)}
+		}
+	}
+
+	si.applyChanges(edit.changes)
+
+	for _, header := range edit.removed {
+		if si.HeaderExists(header) {
+			si.DeleteHeader(header)
+		}
+	}
+}
+
+//invertChanges returns the changes undoing the specified changes
+func invertChanges(changes []SettingsChange) []SettingsChange {
+	inverted := make([]SettingsChange, len(changes))
+
+	for i, change := range changes {
+		inverse := SettingsChange{change.Type, change.Header, change.Name, change.New, change.Old}
+
+		switch change.Type {
+		case SettingsAdded:
+			inverse.Type = SettingsRemoved
+		case SettingsRemoved:
+			inverse.Type = SettingsAdded
+		}
+
+		inverted[len(changes)-1-i] = inverse
+	}
+
+	return inverted
+}
+
+//AddCommitHook adds a function that is called when a transaction is committed.
+//The hook receives a copy of the settings with the changes of the transaction
+//applied, if it returns an error the commit fails. Hooks can be used to
+//validate the settings, see SettingsSchema.CommitHook().
+func (si *SettingsINI) AddCommitHook(hook func(si *SettingsINI) error) {
+	history := si.getHistory()
+	history.hooks = append(history.hooks, hook)
+}
+
+//commit checks the edit using the commit hooks, applies it as a single batch
+//and adds it to the undo history
+func (si *SettingsINI) commit(edit settingsEdit) error {
+	history := si.getHistory()
+
+	if len(history.hooks) != 0 {
+		result := si.Copy()
+		result.applyEdit(edit)
+
+		for _, hook := range history.hooks {
+			if err := hook(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	if edit.empty() {
+		return nil
+	}
+
+	si.Batch(func(si *SettingsINI) error {
+		si.applyEdit(edit)
+		return nil
+	})
+
+	history.undo = append(history.undo, edit)
+	history.redo = nil
+	return nil
+}
+
+//CanUndo returns true if there is a committed transaction to undo
+func (si *SettingsINI) CanUndo() bool {
+	return si.history != nil && len(si.history.undo) != 0
+}
+
+//CanRedo returns true if there is an undone transaction to redo
+func (si *SettingsINI) CanRedo() bool {
+	return si.history != nil && len(si.history.redo) != 0
+}
+
+//Undo reverts the changes of the last committed transaction that is not undone
+//yet. Changes made outside of transactions are not part of the history, the
+//old values are restored regardless of them.
+func (si *SettingsINI) Undo() error {
+	if !si.CanUndo() {
+		return Error{ErrorTypeNotFound, "SettingsINI", "There is no transaction to undo"}
+	}
+
+	edit := si.history.undo[len(si.history.undo)-1]
+	si.history.undo = si.history.undo[:len(si.history.undo)-1]
+	si.history.redo = append(si.history.redo, edit)
+
+	return si.Batch(func(si *SettingsINI) error {
+		si.applyEdit(edit.invert())
+		return nil
+	})
+}
+
+//Redo applies the changes of the last undone transaction again
+func (si *SettingsINI) Redo() error {
+	if !si.CanRedo() {
+		return Error{ErrorTypeNotFound, "SettingsINI", "There is no transaction to redo"}
+	}
+
+	edit := si.history.redo[len(si.history.redo)-1]
+	si.history.redo = si.history.redo[:len(si.history.redo)-1]
+	si.history.undo = append(si.history.undo, edit)
+
+	return si.Batch(func(si *SettingsINI) error {
+		si.applyEdit(edit)
+		return nil
+	})
+}
+
+//ClearHistory removes all transactions from the undo and redo history
+func (si *SettingsINI) ClearHistory() {
+	if si.history != nil {
+		si.history.undo, si.history.redo = nil, nil
+	}
+}
+
+//SettingsTransaction collects changes to settings that are applied together
+//when the transaction is committed. The transaction works on a copy of the
+//settings, so its changes are invisible to others until Commit() is called and
+//changes made to the settings after Begin() are invisible to the transaction.
+//Committing applies the changed value pairs and the created and removed headers
+//only: a value pair changed both by the transaction and outside of it receives
+//the value of the transaction. New instances are created using
+//SettingsINI.Begin() or SyncSettingsINI.Begin().
+type SettingsTransaction struct {
+	original *SettingsINI
+	settings *SettingsINI
+	commit   func(edit settingsEdit) error
+	finished bool
+}
+
+//Begin starts a new transaction on the settings
+func (si *SettingsINI) Begin() *SettingsTransaction {
+	return &SettingsTransaction{si.Copy(), si.Copy(), si.commit, false}
+}
+
+//Begin starts a new transaction on the wrapped settings. Commit hooks are
+//called while holding the write lock, so they should not use the wrapper.
+func (s *SyncSettingsINI) Begin() *SettingsTransaction {
+	snapshot := s.Snapshot()
+
+	return &SettingsTransaction{snapshot, snapshot.Copy(), func(edit settingsEdit) error {
+		return s.apply(func(si *SettingsINI) error {
+			return si.commit(edit)
+		})
+	}, false}
+}
+
+//check returns an error if the transaction is finished
+func (st *SettingsTransaction) check() error {
+	if st.finished {
+		return Error{ErrorTypeInvalidArgument, "SettingsTransaction", "The transaction is already committed or rolled back"}
+	}
+
+	return nil
+}
+
+//HeaderExists see SettingsINI.HeaderExists(...)
+func (st *SettingsTransaction) HeaderExists(header string) bool {
+	return st.settings.HeaderExists(header)
+}
+
+//ValueExists see SettingsINI.ValueExists(...)
+func (st *SettingsTransaction) ValueExists(header, name string) bool {
+	return st.settings.ValueExists(header, name)
+}
+
+//Add see SettingsINI.Add(...)
+func (st *SettingsTransaction) Add(header, name, value string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.Add(header, name, value)
+}
+
+//Set see SettingsINI.Set(...)
+func (st *SettingsTransaction) Set(header, name, value string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.Set(header, name, value)
+}
+
+//Delete see SettingsINI.DeleteValue(...)
+func (st *SettingsTransaction) Delete(header, name string) error {
+	return st.DeleteValue(header, name)
+}
+
+//DeleteValue see SettingsINI.DeleteValue(...)
+func (st *SettingsTransaction) DeleteValue(header, name string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.DeleteValue(header, name)
+}
+
+//DeleteHeader see SettingsINI.DeleteHeader(...)
+func (st *SettingsTransaction) DeleteHeader(header string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.DeleteHeader(header)
+}
+
+//RenameHeader see SettingsINI.RenameHeader(...)
+func (st *SettingsTransaction) RenameHeader(header, newHeader string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.RenameHeader(header, newHeader)
+}
+
+//RenameValue see SettingsINI.RenameValue(...)
+func (st *SettingsTransaction) RenameValue(header, name, newName string) error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	return st.settings.RenameValue(header, name, newName)
+}
+
+//HeaderNames see SettingsINI.HeaderNames()
+func (st *SettingsTransaction) HeaderNames() []string {
+	return st.settings.HeaderNames()
+}
+
+//Keys see SettingsINI.Keys(...)
+func (st *SettingsTransaction) Keys(header string) []string {
+	return st.settings.Keys(header)
+}
+
+//Get see SettingsINI.Get(...), the value includes the changes made within the
+//transaction
+func (st *SettingsTransaction) Get(header, name string) (string, bool) {
+	return st.settings.Get(header, name)
+}
+
+//GetInt see SettingsINI.GetInt(...)
+func (st *SettingsTransaction) GetInt(header, name string) (int, bool, error) {
+	return st.settings.GetInt(header, name)
+}
+
+//GetUint see SettingsINI.GetUint(...)
+func (st *SettingsTransaction) GetUint(header, name string) (uint, bool, error) {
+	return st.settings.GetUint(header, name)
+}
+
+//GetFloat32 see SettingsINI.GetFloat32(...)
+func (st *SettingsTransaction) GetFloat32(header, name string) (float32, bool, error) {
+	return st.settings.GetFloat32(header, name)
+}
+
+//GetFloat64 see SettingsINI.GetFloat64(...)
+func (st *SettingsTransaction) GetFloat64(header, name string) (float64, bool, error) {
+	return st.settings.GetFloat64(header, name)
+}
+
+//Changes returns the changes made within the transaction so far
+func (st *SettingsTransaction) Changes() []SettingsChange {
+	return settingsChanges(st.original, st.settings)
+}
+
+//edit returns the changed value pairs and the headers created or removed
+//within the transaction, such that empty headers are committed as well
+func (st *SettingsTransaction) edit() settingsEdit {
+	edit := settingsEdit{changes: st.Changes()}
+
+	for _, header := range st.settings.HeaderNames() {
+		if !st.original.HeaderExists(header) {
+			edit.added = append(edit.added, header)
+		}
+	}
+
+	for _, header := range st.original.HeaderNames() {
+		if !st.settings.HeaderExists(header) {
+			edit.removed = append(edit.removed, header)
+		}
+	}
+
+	return edit
+}
+
+//Commit applies the changes of the transaction to the settings as a single
+//batch, after checking them using the commit hooks. If a hook fails its error
+//is returned and the transaction remains open, such that the changes can be
+//corrected or rolled back. A committed transaction can be undone using
+//SettingsINI.Undo().
+func (st *SettingsTransaction) Commit() error {
+	if err := st.check(); err != nil {
+		return err
+	}
+
+	if err := st.commit(st.edit()); err != nil {
+		return err
+	}
+
+	st.finished = true
+	return nil
+}
+
+//Rollback discards the changes of the transaction
+func (st *SettingsTransaction) Rollback() {
+	st.finished = true
+}
+
+//CommitHook returns a commit hook (see SettingsINI.AddCommitHook(...)) that
+//rejects settings violating the schema. Warnings are ignored.
+func (ss *SettingsSchema) CommitHook() func(si *SettingsINI) error {
+	return func(si *SettingsINI) error {
+		for _, violation := range ss.Validate(si) {
+			if !violation.Warning {
+				return Error{ErrorTypeInvalidArgument, "SettingsSchema", violation.String()}
+			}
+		}
+
+		return nil
+	}
+}
+
+//Undo see SettingsINI.Undo()
+func (s *SyncSettingsINI) Undo() error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.Undo()
+	})
+}
+
+//Redo see SettingsINI.Redo()
+func (s *SyncSettingsINI) Redo() error {
+	return s.apply(func(si *SettingsINI) error {
+		return si.Redo()
+	})
+}
+
+//AddCommitHook see SettingsINI.AddCommitHook(...)
+func (s *SyncSettingsINI) AddCommitHook(hook func(si *SettingsINI) error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.settings.AddCommitHook(hook)
+}
@@ -0,0 +1,147 @@
+package fio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSettingsTransaction(t *testing.T) {
+	si := NewSettingsINI(16)
+	si.Add("pool", "min", "1")
+	si.Add("pool", "max", "4")
+
+	var groups [][]SettingsChange
+
+	si.OnChanges(SettingsWildcard, func(changes []SettingsChange) {
+		groups = append(groups, changes)
+	})
+
+	tx := si.Begin()
+	tx.Set("pool", "max", "8")
+	tx.Add("log", "level", "debug")
+
+	if err := tx.Delete("pool", "min"); err != nil {
+		t.Errorf("Failed to delete value, error: %s\n", err.Error())
+	}
+
+	if err := tx.Delete("pool", "missing"); err == nil {
+		t.Errorf("Expected deleting a missing value to fail\n")
+	}
+
+	//the changes are isolated until committed
+	if value, _ := tx.Get("pool", "max"); value != "8" {
+		t.Errorf("Expected the transaction to see 8, got '%s'\n", value)
+	}
+
+	if value, _ := si.Get("pool", "max"); value != "4" || len(groups) != 0 {
+		t.Errorf("Expected the settings to be unchanged, got '%s'\n", value)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit, error: %s\n", err.Error())
+	}
+
+	expected := []SettingsChange{
+		{SettingsAdded, "log", "level", "", "debug"},
+		{SettingsModified, "pool", "max", "4", "8"},
+		{SettingsRemoved, "pool", "min", "1", ""},
+	}
+
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0], expected) {
+		t.Errorf("Expected a single notification %v, got %v\n", expected, groups)
+	}
+
+	if err := tx.Set("pool", "max", "9"); err == nil {
+		t.Errorf("Expected changing a committed transaction to fail\n")
+	}
+
+	//rolled back transactions change nothing
+	tx = si.Begin()
+	tx.Set("pool", "max", "100")
+	tx.Rollback()
+
+	if value, _ := si.Get("pool", "max"); value != "8" || tx.Commit() == nil {
+		t.Errorf("Expected the rolled back transaction to be discarded, got '%s'\n", value)
+	}
+
+	//undo and redo
+	if err := si.Undo(); err != nil {
+		t.Fatalf("Failed to undo, error: %s\n", err.Error())
+	}
+
+	if min, _ := si.Get("pool", "min"); min != "1" || si.HeaderExists("log") || !si.CanRedo() || si.CanUndo() {
+		t.Errorf("Unexpected settings after undo %v\n", si.Headers)
+	}
+
+	if err := si.Redo(); err != nil || si.ValueExists("pool", "min") || !si.ValueExists("log", "level") {
+		t.Errorf("Unexpected settings after redo (error: %v)\n", err)
+	}
+
+	if err := si.Redo(); err == nil {
+		t.Errorf("Expected redoing without undone transactions to fail\n")
+	}
+}
+
+func TestSettingsTransactionHeaders(t *testing.T) {
+	si := NewSettingsINI(16)
+	si.Add("pool", "size", "4")
+	si.Headers["empty"] = &SettingsINIHeader{make(map[string]string)}
+	si.Headers["unused"] = &SettingsINIHeader{make(map[string]string)}
+
+	//removing the last value pair keeps the header
+	tx := si.Begin()
+	tx.DeleteValue("pool", "size")
+
+	if err := tx.Commit(); err != nil || !si.HeaderExists("pool") {
+		t.Errorf("Expected the header to be kept (error: %v)\n", err)
+	}
+
+	//header operations on empty headers are committed and undone
+	tx = si.Begin()
+	tx.DeleteHeader("empty")
+	tx.RenameHeader("unused", "renamed")
+
+	if err := tx.Commit(); err != nil || si.HeaderExists("empty") || si.HeaderExists("unused") || !si.HeaderExists("renamed") {
+		t.Errorf("Unexpected headers after commit %v (error: %v)\n", si.HeaderNames(), err)
+	}
+
+	if err := si.Undo(); err != nil || !si.HeaderExists("empty") || !si.HeaderExists("unused") || si.HeaderExists("renamed") {
+		t.Errorf("Unexpected headers after undo %v (error: %v)\n", si.HeaderNames(), err)
+	}
+}
+
+func TestSettingsTransactionCommitHook(t *testing.T) {
+	maximum := 10.0
+	schema := SettingsSchema{[]SettingsHeaderSchema{
+		{"pool", false, "", false, []SettingsKeySchema{{Name: "size", Type: ColumnInt, Maximum: &maximum}}},
+	}, false}
+
+	s := NewSyncSettingsINI(NewSettingsINI(16))
+	s.Add("pool", "size", "4")
+	s.AddCommitHook(schema.CommitHook())
+
+	tx := s.Begin()
+	tx.Set("pool", "size", "20")
+
+	if err := tx.Commit(); err == nil {
+		t.Errorf("Expected the commit hook to reject the transaction\n")
+	}
+
+	tx.Set("pool", "size", "8")
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Failed to commit, error: %s\n", err.Error())
+	}
+
+	if value, _ := s.Get("pool", "size"); value != "8" {
+		t.Errorf("Expected 8, got '%s'\n", value)
+	}
+
+	if err := s.Undo(); err != nil {
+		t.Errorf("Failed to undo, error: %s\n", err.Error())
+	}
+
+	if value, _ := s.Get("pool", "size"); value != "4" {
+		t.Errorf("Expected 4 after undo, got '%s'\n", value)
+	}
+}
@@ -0,0 +1,327 @@
+package fio
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+//SettingsChangeType is the type used to describe how a value pair changed
+type SettingsChangeType byte
+
+//The various changes to use in conjunction with the SettingsChangeType type
+const (
+	SettingsAdded    SettingsChangeType = iota //the value pair did not exist before
+	SettingsRemoved                            //the value pair no longer exists
+	SettingsModified                           //the value of the value pair changed
+)
+
+//The strings used to describe the SettingsChangeType value when it is printed
+const (
+	stringSettingsAdded    = "added"
+	stringSettingsRemoved  = "removed"
+	stringSettingsModified = "modified"
+)
+
+func (sct SettingsChangeType) String() string {
+	switch sct {
+	case SettingsAdded:
+		return stringSettingsAdded
+	case SettingsRemoved:
+		return stringSettingsRemoved
+	case SettingsModified:
+		return stringSettingsModified
+	}
+
+	return "UNKNOWN"
+}
+
+//SettingsChange describes a change of a single value pair. Old is empty for
+//added value pairs and New is empty for removed value pairs.
+type SettingsChange struct {
+	Type   SettingsChangeType `json:"type"`
+	Header string             `json:"header"`
+	Name   string             `json:"name"`
+	Old    string             `json:"old,omitempty"`
+	New    string             `json:"new,omitempty"`
+}
+
+//settingsChanges returns the changes between two versions of the settings,
+//sorted by header and name
+func settingsChanges(old, new *SettingsINI) []SettingsChange {
+	var changes []SettingsChange
+
+	for headerName, header := range old.Headers {
+		for name, value := range header.Values {
+			newValue, ok := new.Get(headerName, name)
+
+			if !ok {
+				changes = append(changes, SettingsChange{SettingsRemoved, headerName, name, value, ""})
+			} else if newValue != value {
+				changes = append(changes, SettingsChange{SettingsModified, headerName, name, value, newValue})
+			}
+		}
+	}
+
+	for headerName, header := range new.Headers {
+		for name, value := range header.Values {
+			if !old.ValueExists(headerName, name) {
+				changes = append(changes, SettingsChange{SettingsAdded, headerName, name, "", value})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Header != changes[j].Header {
+			return changes[i].Header < changes[j].Header
+		}
+
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+//settingsSubscription is a channel receiving the changes found by a
+//SettingsWatcher, done is closed when the subscriber unsubscribes
+type settingsSubscription struct {
+	changes chan []SettingsChange
+	done    chan struct{}
+	once    sync.Once
+}
+
+//SettingsWatcher watches the file the settings were loaded from and reloads
+//the settings when the file changes. The file is polled every Interval, on
+//Linux changes are additionally detected immediately using inotify. As the
+//directory containing the file is watched rather than the file itself, editors
+//that save by writing a new file and renaming it over the original are
+//supported.
+//
+//Once a change is detected the watcher waits until the file has not changed
+//for the Debounce duration before reloading it. The reloaded settings replace
+//the watched settings atomically, but only if the file loads without errors;
+//otherwise OnError (if not nil) is called with the error and the previous
+//settings are kept. After replacing the settings all subscribers receive the
+//changed value pairs, if there are any.
+//
+//New instances should be created using NewSettingsWatcher(...).
+type SettingsWatcher struct {
+	settings *SyncSettingsINI
+	Interval time.Duration
+	Debounce time.Duration
+	OnError  func(err error)
+
+	lock          sync.Mutex
+	subscriptions []*settingsSubscription
+	stop          chan struct{}
+	stopped       chan struct{}
+}
+
+//NewSettingsWatcher creates a new SettingsWatcher for the specified settings
+//and returns its pointer. The watcher does not start watching until Start() is
+//called. The settings have to be loaded from a file (see SettingsINI.Filename)
+//and should only be changed through the wrapper.
+func NewSettingsWatcher(settings *SyncSettingsINI, interval, debounce time.Duration) *SettingsWatcher {
+	return &SettingsWatcher{settings: settings, Interval: interval, Debounce: debounce}
+}
+
+//Subscribe returns a channel receiving the changes of every reload, together
+//with a function to unsubscribe. The watcher waits for subscribers to receive
+//the changes, so the channel should be read continuously until unsubscribing.
+//The channel is never closed.
+func (sw *SettingsWatcher) Subscribe() (<-chan []SettingsChange, func()) {
+	subscription := &settingsSubscription{make(chan []SettingsChange, 1), make(chan struct{}), sync.Once{}}
+
+	sw.lock.Lock()
+	sw.subscriptions = append(sw.subscriptions, subscription)
+	sw.lock.Unlock()
+
+	unsubscribe := func() {
+		subscription.once.Do(func() {
+			close(subscription.done)
+			sw.lock.Lock()
+			defer sw.lock.Unlock()
+
+			for i, s := range sw.subscriptions {
+				if s == subscription {
+					sw.subscriptions = append(sw.subscriptions[:i], sw.subscriptions[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+
+	return subscription.changes, unsubscribe
+}
+
+//filename returns the name of the watched file
+func (sw *SettingsWatcher) filename() string {
+	var filename string
+
+	sw.settings.Read(func(si *SettingsINI) {
+		filename = si.Filename
+	})
+
+	return filename
+}
+
+//Start starts watching the file in a seperate goroutine. The function returns
+//an error if the settings were not loaded from a file or if the watcher is
+//already started.
+func (sw *SettingsWatcher) Start() error {
+	filename := sw.filename()
+
+	if len(filename) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SettingsWatcher", "The settings were not loaded from a file"}
+	}
+
+	if sw.Interval <= 0 {
+		return Error{ErrorTypeInvalidArgument, "SettingsWatcher", "The polling interval has to be positive"}
+	}
+
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	if sw.stop != nil {
+		return Error{ErrorTypeInvalidArgument, "SettingsWatcher", "The watcher is already started"}
+	}
+
+	sw.stop = make(chan struct{})
+	sw.stopped = make(chan struct{})
+
+	//fall back to polling only if the notifications are not available
+	notifier, _ := newSettingsNotifier(filename)
+	go sw.watch(filename, notifier, sw.stop, sw.stopped)
+
+	return nil
+}
+
+//Stop stops watching the file and waits until the watching goroutine exits
+func (sw *SettingsWatcher) Stop() {
+	sw.lock.Lock()
+	stop, stopped := sw.stop, sw.stopped
+	sw.stop, sw.stopped = nil, nil
+	sw.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+}
+
+//settingsFileState is used to detect changes of a file while polling
+type settingsFileState struct {
+	info os.FileInfo
+}
+
+//changed checks if the file changed since the state was stored. A missing file
+//(for instance halfway a save by renaming) is not a change.
+func (sfs *settingsFileState) changed(filename string) bool {
+	info, err := os.Stat(filename)
+
+	if err != nil {
+		return false
+	}
+
+	previous := sfs.info
+	sfs.info = info
+
+	return previous == nil || !os.SameFile(previous, info) || !previous.ModTime().Equal(info.ModTime()) || previous.Size() != info.Size()
+}
+
+//watch polls the file and receives notifications until stop is closed
+func (sw *SettingsWatcher) watch(filename string, notifier *settingsNotifier, stop, stopped chan struct{}) {
+	defer close(stopped)
+
+	var notifications <-chan struct{}
+
+	if notifier != nil {
+		defer notifier.Close()
+		notifications = notifier.events
+	}
+
+	state := settingsFileState{}
+	state.changed(filename)
+
+	ticker := time.NewTicker(sw.Interval)
+	defer ticker.Stop()
+
+	var debounce *time.Timer
+	var debounced <-chan time.Time
+
+	for {
+		changed := false
+
+		select {
+		case <-stop:
+			if debounce != nil {
+				debounce.Stop()
+			}
+
+			return
+		case <-ticker.C:
+			changed = state.changed(filename)
+		case _, ok := <-notifications:
+			if !ok {
+				notifications = nil
+				continue
+			}
+
+			state.changed(filename)
+			changed = true
+		case <-debounced:
+			debounce, debounced = nil, nil
+			sw.reload(filename, stop)
+			continue
+		}
+
+		//restart the debounce period on every change
+		if changed {
+			if debounce != nil {
+				debounce.Stop()
+			}
+
+			debounce = time.NewTimer(sw.Debounce)
+			debounced = debounce.C
+		}
+	}
+}
+
+//reload loads the file, replaces the settings and notifies the subscribers
+func (sw *SettingsWatcher) reload(filename string, stop chan struct{}) {
+	var buffer int
+
+	sw.settings.Read(func(si *SettingsINI) {
+		buffer = si.buffer
+	})
+
+	loaded := NewSettingsINI(buffer)
+
+	if err := loaded.Load(filename); err != nil {
+		if sw.OnError != nil {
+			sw.OnError(err)
+		}
+
+		return
+	}
+
+	_, changes := sw.settings.replace(loaded)
+
+	if len(changes) == 0 {
+		return
+	}
+
+	sw.lock.Lock()
+	subscriptions := append([]*settingsSubscription(nil), sw.subscriptions...)
+	sw.lock.Unlock()
+
+	for _, subscription := range subscriptions {
+		select {
+		case subscription.changes <- changes:
+		case <-subscription.done:
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package fio
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+//settingsNotifier uses inotify to receive notifications about changes to the
+//directory containing a file. Events receives a value (without blocking the
+//notifier) whenever the file is written, created, moved or deleted.
+type settingsNotifier struct {
+	fd     int
+	watch  int
+	events chan struct{}
+}
+
+//newSettingsNotifier starts watching the directory containing the file
+func newSettingsNotifier(filename string) (*settingsNotifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+
+	if err != nil {
+		return nil, Error{ErrorTypeInvalidArgument, "SettingsWatcher", "Failed to initialize inotify"}
+	}
+
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE)
+	watch, err := syscall.InotifyAddWatch(fd, filepath.Dir(filename), mask)
+
+	if err != nil {
+		syscall.Close(fd)
+		return nil, Error{ErrorTypeInvalidArgument, "SettingsWatcher", "Failed to watch the directory of the file"}
+	}
+
+	notifier := &settingsNotifier{fd, watch, make(chan struct{}, 1)}
+	go notifier.read(filepath.Base(filename))
+
+	return notifier, nil
+}
+
+//read reads the inotify events until the watch is removed
+func (sn *settingsNotifier) read(name string) {
+	defer close(sn.events)
+	defer syscall.Close(sn.fd)
+
+	buffer := make([]byte, 4096)
+
+	for {
+		count, err := syscall.Read(sn.fd, buffer)
+
+		if err == syscall.EINTR {
+			continue
+		}
+
+		if err != nil || count <= 0 {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(event.Len)
+
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				return
+			}
+
+			if offset > count || strings.TrimRight(string(buffer[start:offset]), "\x00") != name {
+				continue
+			}
+
+			select {
+			case sn.events <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+//Close stops watching, which causes the reading goroutine to exit
+func (sn *settingsNotifier) Close() {
+	syscall.InotifyRmWatch(sn.fd, uint32(sn.watch))
+}
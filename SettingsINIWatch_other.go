@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package fio
+
+//settingsNotifier is not available on this platform, SettingsWatcher only
+//polls the file
+type settingsNotifier struct {
+	events chan struct{}
+}
+
+//newSettingsNotifier always fails on this platform
+func newSettingsNotifier(filename string) (*settingsNotifier, error) {
+	return nil, Error{ErrorTypeInvalidArgument, "SettingsWatcher", "File notifications are not supported on this platform"}
+}
+
+//Close does nothing on this platform
+func (sn *settingsNotifier) Close() {
+}
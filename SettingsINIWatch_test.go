@@ -0,0 +1,127 @@
+package fio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testSettingsWatcherReceive(t *testing.T, changes <-chan []SettingsChange) []SettingsChange {
+	select {
+	case result := <-changes:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for changes\n")
+	}
+
+	return nil
+}
+
+func TestSettingsWatcher(t *testing.T) {
+	filename := testSettingsINIWrite(t, "watched.ini", "[pool]\nsize = 4\nname = a\n")
+	si := NewSettingsINI(16)
+
+	if err := si.Load(filename); err != nil {
+		t.Fatalf("Failed to load settings, error: %s\n", err.Error())
+	}
+
+	settings := NewSyncSettingsINI(si)
+	watcher := NewSettingsWatcher(settings, 10*time.Millisecond, 50*time.Millisecond)
+	errors := make(chan error, 4)
+	watcher.OnError = func(err error) {
+		errors <- err
+	}
+
+	changes, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Failed to start watcher, error: %s\n", err.Error())
+	}
+
+	defer watcher.Stop()
+
+	//save by writing a new file and renaming it over the original
+	temporary := filepath.Join(filepath.Dir(filename), "watched.ini.tmp")
+	os.WriteFile(temporary, []byte("[pool]\nsize = 8\n[log]\nlevel = debug\n"), 0644)
+
+	if err := os.Rename(temporary, filename); err != nil {
+		t.Fatalf("Failed to rename file, error: %s\n", err.Error())
+	}
+
+	expected := []SettingsChange{
+		{SettingsAdded, "log", "level", "", "debug"},
+		{SettingsRemoved, "pool", "name", "a", ""},
+		{SettingsModified, "pool", "size", "4", "8"},
+	}
+
+	if result := testSettingsWatcherReceive(t, changes); !reflect.DeepEqual(result, expected) {
+		t.Errorf("%v != %v\n", result, expected)
+	}
+
+	if value, _ := settings.Get("pool", "size"); value != "8" {
+		t.Errorf("Expected the reloaded size 8, got '%s'\n", value)
+	}
+
+	//a broken file is reported and does not replace the settings
+	os.WriteFile(filename, []byte("[pool\n"), 0644)
+
+	select {
+	case <-errors:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the load error\n")
+	}
+
+	if value, _ := settings.Get("pool", "size"); value != "8" {
+		t.Errorf("Expected the settings to be kept, got '%s'\n", value)
+	}
+
+	//several quick writes result in a single reload
+	for _, size := range []string{"9", "10", "11"} {
+		os.WriteFile(filename, []byte("[pool]\nsize = "+size+"\n[log]\nlevel = debug\n"), 0644)
+	}
+
+	expected = []SettingsChange{{SettingsModified, "pool", "size", "8", "11"}}
+
+	if result := testSettingsWatcherReceive(t, changes); !reflect.DeepEqual(result, expected) {
+		t.Errorf("%v != %v\n", result, expected)
+	}
+
+	if err := watcher.Start(); err == nil {
+		t.Errorf("Expected starting the watcher twice to fail\n")
+	}
+}
+
+func TestSettingsWatcherReloadRace(t *testing.T) {
+	filename := testSettingsINIWrite(t, "raced.ini", "[pool]\nsize = 4\n")
+	settings := NewSyncSettingsINI(NewSettingsINI(16))
+	watcher := NewSettingsWatcher(settings, time.Second, time.Second)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	//writes through the wrapper while reloading, run with -race to check
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 200; i++ {
+			settings.Set("pool", "size", strconv.Itoa(i))
+			settings.Add("pool", "extra"+strconv.Itoa(i), "x")
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		watcher.reload(filename, stop)
+	}
+
+	<-done
+	close(stop)
+}
+
+func TestSettingsChangeType(t *testing.T) {
+	if SettingsAdded.String() != "added" || SettingsRemoved.String() != "removed" || SettingsModified.String() != "modified" {
+		t.Errorf("Unexpected change type strings\n")
+	}
+}
@@ -2,6 +2,7 @@ package fio
 
 import (
 	"os"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -175,3 +176,74 @@ func TestSettingsINI(t *testing.T) {
 	//delete the file
 	os.Remove(testSettingsINIFilename)
 }
+
+func TestSettingsINIEditing(t *testing.T) {
+	var settings Settinger = NewSettingsINI(16)
+	si := settings.(*SettingsINI)
+	si.Add("", "a", "1")
+	si.Add("server", "port", "80")
+	si.Add("server", "host", "localhost")
+	si.Add("log", "level", "debug")
+
+	if names := si.HeaderNames(); !reflect.DeepEqual(names, []string{"", "log", "server"}) {
+		t.Errorf("Unexpected headers %q\n", names)
+	}
+
+	if keys := si.Keys("server"); !reflect.DeepEqual(keys, []string{"host", "port"}) {
+		t.Errorf("Unexpected keys %q\n", keys)
+	}
+
+	var pairs []string
+
+	for name, value := range si.AllValues("server") {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	if !reflect.DeepEqual(pairs, []string{"host=localhost", "port=80"}) {
+		t.Errorf("Unexpected values %q\n", pairs)
+	}
+
+	for i, name := range si.AllHeaders() {
+		if i == 1 && name != "log" {
+			t.Errorf("Expected header 1 to be 'log', got '%s'\n", name)
+		}
+	}
+
+	if err := si.RenameValue("server", "host", "address"); err != nil || !si.ValueExists("server", "address") || si.ValueExists("server", "host") {
+		t.Errorf("Failed to rename value (error: %v)\n", err)
+	}
+
+	if err := si.RenameValue("server", "address", "port"); err == nil {
+		t.Errorf("Expected renaming to an existing name to fail\n")
+	}
+
+	if err := si.RenameHeader("server", "http"); err != nil || si.HeaderExists("server") {
+		t.Errorf("Failed to rename header (error: %v)\n", err)
+	}
+
+	if value, _ := si.Get("http", "port"); value != "80" {
+		t.Errorf("Expected the renamed header to keep its values, got '%s'\n", value)
+	}
+
+	if err := si.RenameHeader("http", "log"); err == nil {
+		t.Errorf("Expected renaming to an existing header to fail\n")
+	}
+
+	if err := si.DeleteValue("http", "port"); err != nil || si.ValueExists("http", "port") || !si.HeaderExists("http") {
+		t.Errorf("Failed to delete value (error: %v)\n", err)
+	}
+
+	if err := si.DeleteValue("http", "port"); err == nil {
+		t.Errorf("Expected deleting a missing value to fail\n")
+	}
+
+	if err := si.DeleteHeader("log"); err != nil || si.HeaderExists("log") {
+		t.Errorf("Failed to delete header (error: %v)\n", err)
+	}
+
+	if err := si.DeleteHeader("log"); err == nil {
+		t.Errorf("Expected deleting a missing header to fail\n")
+	}
+
+	var _ Settinger = NewSyncSettingsINI(si)
+}
@@ -10,11 +10,14 @@ import (
 
 //The SpreadsheetDelim type represents spreadsheet-like files wherein columns
 //within a row are seperated by a common delimeter and the rows themselves are
-//seperated by a newline character.
+//seperated by a newline character. If Header is true the first row contains the
+//column names, and the table operations (such as sorting and filtering) leave
+//that row in place.
 type SpreadsheetDelim struct {
 	buffer    int
 	Filename  string
 	delimeter string
+	Header    bool
 	Data      [][]string
 }
 
@@ -23,7 +26,50 @@ type SpreadsheetDelim struct {
 //grow to the largest row in the file) and the delimeter string to seperate
 //column values by.
 func NewSpreadsheetDelim(buffer int, delimeter string) *SpreadsheetDelim {
-	return &SpreadsheetDelim{buffer, "", delimeter, nil}
+	return &SpreadsheetDelim{buffer, "", delimeter, false, nil}
+}
+
+//ColumnIndex returns the index of the column with the specified name within the
+//header row. If the spreadsheet has no header or the header does not contain
+//the name the function will return false.
+func (sd *SpreadsheetDelim) ColumnIndex(name string) (int, bool) {
+	if !sd.Header || len(sd.Data) == 0 {
+		return 0, false
+	}
+
+	for i, column := range sd.Data[0] {
+		if column == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+//Copy returns a deep copy of the spreadsheet
+func (sd *SpreadsheetDelim) Copy() *SpreadsheetDelim {
+	result := &SpreadsheetDelim{sd.buffer, sd.Filename, sd.delimeter, sd.Header, nil}
+
+	if sd.Data != nil {
+		result.Data = make([][]string, len(sd.Data))
+
+		for i, row := range sd.Data {
+			result.Data[i] = make([]string, len(row))
+			copy(result.Data[i], row)
+		}
+	}
+
+	return result
+}
+
+//body returns the rows of the spreadsheet, excluding the header row if the
+//spreadsheet has a header
+func (sd *SpreadsheetDelim) body() [][]string {
+	if sd.Header && len(sd.Data) != 0 {
+		return sd.Data[1:]
+	}
+
+	return sd.Data
 }
 
 //Load will load data from a delimeted spreadsheet into the SpreadsheetDelim
@@ -262,3 +308,18 @@ func (sd *SpreadsheetDelim) GetFloat64(row, col int) (float64, bool, error) {
 
 	return 0, false, nil
 }
+
+//SpreadsheetDelimSheet wraps a SpreadsheetDelim instance such that it
+//implements the Spreadsheeter interface, which requires a Load(...) method that
+//only accepts a filename. Loading through the wrapper replaces the previously
+//loaded data and does not skip any columns or rows.
+type SpreadsheetDelimSheet struct {
+	*SpreadsheetDelim
+}
+
+//Load will replace the data within the wrapped SpreadsheetDelim instance with
+//the data loaded from the specified file. See SpreadsheetDelim.Load(...).
+func (sds SpreadsheetDelimSheet) Load(filename string) error {
+	sds.Data = nil
+	return sds.SpreadsheetDelim.Load(filename, 0, 0)
+}
@@ -0,0 +1,751 @@
+package fio
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//RowChangeType is the type used to describe how a row changed between two
+//versions of a spreadsheet
+type RowChangeType byte
+
+//The various changes to use in conjunction with the RowChangeType type
+const (
+	RowInserted RowChangeType = iota //the row only exists in the new spreadsheet
+	RowDeleted                       //the row only exists in the old spreadsheet
+	RowModified                      //the row exists in both spreadsheets, with different values
+)
+
+//The strings used to describe the RowChangeType value when it is printed
+const (
+	stringRowInserted = "inserted"
+	stringRowDeleted  = "deleted"
+	stringRowModified = "modified"
+)
+
+func (rct RowChangeType) String() string {
+	switch rct {
+	case RowInserted:
+		return stringRowInserted
+	case RowDeleted:
+		return stringRowDeleted
+	case RowModified:
+		return stringRowModified
+	}
+
+	return "UNKNOWN"
+}
+
+//ColumnChangeType is the type used to describe how a column changed between
+//two versions of a spreadsheet
+type ColumnChangeType byte
+
+//The various changes to use in conjunction with the ColumnChangeType type
+const (
+	ColumnAdded   ColumnChangeType = iota //the column only exists in the new spreadsheet
+	ColumnRemoved                         //the column only exists in the old spreadsheet
+	ColumnMoved                           //the column changed its position relative to the other columns
+)
+
+//The strings used to describe the ColumnChangeType value when it is printed
+const (
+	stringColumnAdded   = "added"
+	stringColumnRemoved = "removed"
+	stringColumnMoved   = "moved"
+)
+
+func (cct ColumnChangeType) String() string {
+	switch cct {
+	case ColumnAdded:
+		return stringColumnAdded
+	case ColumnRemoved:
+		return stringColumnRemoved
+	case ColumnMoved:
+		return stringColumnMoved
+	}
+
+	return "UNKNOWN"
+}
+
+//ColumnChange describes a change of a single column. Old and New are the
+//indices of the column within both spreadsheets, or -1 if the column does not
+//exist in that spreadsheet.
+type ColumnChange struct {
+	Type ColumnChangeType
+	Name string
+	Old  int
+	New  int
+}
+
+//CellChange describes a changed value within a modified row
+type CellChange struct {
+	Column string
+	Old    string
+	New    string
+}
+
+//RowChange describes a change of a single row, identified by the values of its
+//key columns. Old is nil for inserted rows and New is nil for deleted rows,
+//Cells contains the changed values of modified rows.
+type RowChange struct {
+	Type  RowChangeType
+	Key   []string
+	Old   []string
+	New   []string
+	Cells []CellChange
+}
+
+//SpreadsheetDiffOptions contains the options used while comparing two
+//spreadsheets. Keys contains the key columns within the old spreadsheet, which
+//have to identify the rows uniquely. Compare is the comparison used to order
+//and match the keys.
+//
+//If Header is true the first row of both spreadsheets contains the column
+//names, and the columns are matched by name: the key columns are looked up in
+//the new spreadsheet by their names, and the columns that are added, removed
+//or moved are reported as schema changes. Otherwise the columns are matched by
+//index and named by their index, the number of columns is taken from the first
+//row. An empty spreadsheet is considered to have the columns of the other
+//spreadsheet.
+type SpreadsheetDiffOptions struct {
+	Keys    []int
+	Compare CompareType
+	Header  bool
+}
+
+//The SpreadsheetDiffHandler interface receives the results of a comparison of
+//two spreadsheets, see DiffRows(...). WriteColumns is called once with the
+//column names of both spreadsheets and the schema changes, WriteChange is
+//called for every changed row in the order of the keys and Flush is called
+//after the last row.
+type SpreadsheetDiffHandler interface {
+	WriteColumns(old, new []string, changes []ColumnChange) error
+	WriteChange(change RowChange) error
+	Flush() error
+}
+
+//diffColumns determines the column names of a spreadsheet from its first row
+func diffColumns(first []string, header bool) []string {
+	if header {
+		return first
+	}
+
+	names := make([]string, len(first))
+
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+
+	return names
+}
+
+//diffColumnNames determines the column names of both spreadsheets from their
+//first rows, where a nil row indicates an empty spreadsheet. An empty
+//spreadsheet (without even a header row) is considered to have the columns of
+//the other spreadsheet, such that all rows of the other spreadsheet are
+//reported as inserted or deleted.
+func diffColumnNames(old, new []string, header bool) ([]string, []string) {
+	switch {
+	case old == nil:
+		old = new
+	case new == nil:
+		new = old
+	}
+
+	return diffColumns(old, header), diffColumns(new, header)
+}
+
+//diffColumnIndex returns the index of the first column with the name, or -1
+//if no column has the name
+func diffColumnIndex(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//diffColumnChanges determines the added, removed and moved columns. A column
+//is moved if it is not part of the longest sequence of shared columns that
+//keeps its order.
+func diffColumnChanges(old, new []string) []ColumnChange {
+	var changes []ColumnChange
+	var shared []int
+
+	for i, name := range new {
+		if o := diffColumnIndex(old, name); o < 0 {
+			changes = append(changes, ColumnChange{ColumnAdded, name, -1, i})
+		} else {
+			shared = append(shared, i)
+		}
+	}
+
+	for i, name := range old {
+		if diffColumnIndex(new, name) < 0 {
+			changes = append(changes, ColumnChange{ColumnRemoved, name, i, -1})
+		}
+	}
+
+	//find the longest increasing subsequence of old indices
+	lengths := make([]int, len(shared))
+	previous := make([]int, len(shared))
+	last := -1
+
+	for i := range shared {
+		lengths[i], previous[i] = 1, -1
+
+		for j := 0; j < i; j++ {
+			if diffColumnIndex(old, new[shared[j]]) < diffColumnIndex(old, new[shared[i]]) && lengths[j]+1 > lengths[i] {
+				lengths[i], previous[i] = lengths[j]+1, j
+			}
+		}
+
+		if last < 0 || lengths[i] > lengths[last] {
+			last = i
+		}
+	}
+
+	ordered := make([]bool, len(shared))
+
+	for i := last; i >= 0; i = previous[i] {
+		ordered[i] = true
+	}
+
+	for i, col := range shared {
+		if !ordered[i] {
+			changes = append(changes, ColumnChange{ColumnMoved, new[col], diffColumnIndex(old, new[col]), col})
+		}
+	}
+
+	return changes
+}
+
+//spreadsheetDiffer compares the rows of two spreadsheets
+type spreadsheetDiffer struct {
+	old     *joinInput
+	new     *joinInput
+	names   []string
+	shared  [][2]int //the old and new index of the columns in both spreadsheets
+	options SpreadsheetDiffOptions
+}
+
+//diffNewKeys returns the key columns within the new spreadsheet
+func diffNewKeys(options SpreadsheetDiffOptions, old, new []string) ([]int, error) {
+	if len(options.Keys) == 0 {
+		return nil, Error{ErrorTypeInvalidArgument, "Diff", "No key columns specified"}
+	}
+
+	keys := make([]int, len(options.Keys))
+
+	for i, key := range options.Keys {
+		if key < 0 {
+			return nil, Error{ErrorTypeInvalidArgument, "Diff", "Negative key column specified"}
+		}
+
+		keys[i] = key
+
+		//both spreadsheets are empty if there are no column names
+		if options.Header && len(old) != 0 {
+			name := rowValue(old, key)
+
+			if keys[i] = diffColumnIndex(new, name); keys[i] < 0 {
+				return nil, Error{ErrorTypeNotFound, "Diff", "Key column '" + name + "' does not exist in the new spreadsheet"}
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+//next reads the next row of an input, returning an error if its key equals the
+//key of the previous row
+func (sd *spreadsheetDiffer) next(input *joinInput) error {
+	previous := input.key
+
+	if err := input.next(sd.options.Compare); err != nil {
+		return err
+	}
+
+	if input.row != nil && previous != nil && compareKeyValues(input.key, previous, sd.options.Compare) == 0 {
+		return Error{ErrorTypeInvalidArgument, "Diff", "Row " + strconv.Itoa(input.count-1) + " of the " + input.name + " input has a duplicate key"}
+	}
+
+	return nil
+}
+
+//compare returns the change of two rows sharing a key, or false if the rows
+//are equal
+func (sd *spreadsheetDiffer) compare(old, new []string) (RowChange, bool) {
+	change := RowChange{RowModified, joinKeyValues(new, sd.new.keys), old, new, nil}
+
+	for _, col := range sd.shared {
+		if a, b := rowValue(old, col[0]), rowValue(new, col[1]); a != b {
+			change.Cells = append(change.Cells, CellChange{sd.names[col[1]], a, b})
+		}
+	}
+
+	return change, len(change.Cells) != 0
+}
+
+//DiffRows compares the rows read from the old and new readers and passes the
+//differences to the handler, see SpreadsheetDiffOptions. Both inputs have to be
+//sorted on their key columns using the Compare comparison (in ascending order),
+//such that only a single row of each input is held in memory and inputs of any
+//size can be compared. Rows are compared by the exact values of the columns
+//that exist in both inputs.
+func DiffRows(old, new RowReader, handler SpreadsheetDiffHandler, options SpreadsheetDiffOptions) error {
+	sd := &spreadsheetDiffer{&joinInput{old, options.Keys, nil, nil, 0, true, "old", "Diff"}, &joinInput{new, nil, nil, nil, 0, true, "new", "Diff"}, nil, nil, options}
+
+	//read the headers, or the first rows to determine the number of columns
+	if err := sd.old.next(options.Compare); err != nil {
+		return err
+	}
+
+	if err := sd.new.next(options.Compare); err != nil {
+		return err
+	}
+
+	oldNames, newNames := diffColumnNames(sd.old.row, sd.new.row, options.Header)
+	keys, err := diffNewKeys(options, oldNames, newNames)
+
+	if err != nil {
+		return err
+	}
+
+	sd.new.keys, sd.names = keys, newNames
+
+	for i, name := range newNames {
+		if o := diffColumnIndex(oldNames, name); o >= 0 {
+			sd.shared = append(sd.shared, [2]int{o, i})
+		}
+	}
+
+	if err = handler.WriteColumns(oldNames, newNames, diffColumnChanges(oldNames, newNames)); err != nil {
+		return err
+	}
+
+	if options.Header {
+		sd.old.row, sd.old.key, sd.new.row, sd.new.key = nil, nil, nil, nil
+
+		if err = sd.next(sd.old); err != nil {
+			return err
+		}
+
+		if err = sd.next(sd.new); err != nil {
+			return err
+		}
+	} else {
+		sd.old.key = joinKeyValues(sd.old.row, sd.old.keys)
+		sd.new.key = joinKeyValues(sd.new.row, sd.new.keys)
+	}
+
+	for sd.old.row != nil || sd.new.row != nil {
+		var c int
+
+		switch {
+		case sd.old.row == nil:
+			c = 1
+		case sd.new.row == nil:
+			c = -1
+		default:
+			c = compareKeyValues(sd.old.key, sd.new.key, options.Compare)
+		}
+
+		switch {
+		case c < 0:
+			err = handler.WriteChange(RowChange{RowDeleted, sd.old.key, sd.old.row, nil, nil})
+		case c > 0:
+			err = handler.WriteChange(RowChange{RowInserted, sd.new.key, nil, sd.new.row, nil})
+		default:
+			if change, changed := sd.compare(sd.old.row, sd.new.row); changed {
+				err = handler.WriteChange(change)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if c <= 0 {
+			if err = sd.next(sd.old); err != nil {
+				return err
+			}
+		}
+
+		if c >= 0 {
+			if err = sd.next(sd.new); err != nil {
+				return err
+			}
+		}
+	}
+
+	return handler.Flush()
+}
+
+//SpreadsheetDiff contains the differences between two spreadsheets, as
+//collected by DiffSpreadsheets(...). It implements the SpreadsheetDiffHandler
+//interface, such that it can also collect the results of DiffRows(...). The
+//differences can be written as a patch using WritePatch(...) or as a text
+//report using Report(...).
+type SpreadsheetDiff struct {
+	OldColumns []string
+	NewColumns []string
+	Columns    []ColumnChange
+	Rows       []RowChange
+}
+
+//WriteColumns stores the column names and schema changes
+func (sd *SpreadsheetDiff) WriteColumns(old, new []string, changes []ColumnChange) error {
+	sd.OldColumns = append([]string(nil), old...)
+	sd.NewColumns = append([]string(nil), new...)
+	sd.Columns = changes
+	return nil
+}
+
+//WriteChange stores a copy of the row change
+func (sd *SpreadsheetDiff) WriteChange(change RowChange) error {
+	if change.Old != nil {
+		change.Old = append([]string(nil), change.Old...)
+	}
+
+	if change.New != nil {
+		change.New = append([]string(nil), change.New...)
+	}
+
+	sd.Rows = append(sd.Rows, change)
+	return nil
+}
+
+//Flush does nothing, it is required by the SpreadsheetDiffHandler interface
+func (sd *SpreadsheetDiff) Flush() error {
+	return nil
+}
+
+//Empty returns true if the spreadsheets are equal
+func (sd *SpreadsheetDiff) Empty() bool {
+	return len(sd.Columns) == 0 && len(sd.Rows) == 0
+}
+
+//replay passes the differences to another handler
+func (sd *SpreadsheetDiff) replay(handler SpreadsheetDiffHandler) error {
+	if err := handler.WriteColumns(sd.OldColumns, sd.NewColumns, sd.Columns); err != nil {
+		return err
+	}
+
+	for _, change := range sd.Rows {
+		if err := handler.WriteChange(change); err != nil {
+			return err
+		}
+	}
+
+	return handler.Flush()
+}
+
+//WritePatch writes the differences as a patch, see SpreadsheetPatchWriter
+func (sd *SpreadsheetDiff) WritePatch(w RowWriter) error {
+	return sd.replay(NewSpreadsheetPatchWriter(w))
+}
+
+//Report writes the differences as a text report, see SpreadsheetDiffReport
+func (sd *SpreadsheetDiff) Report(w io.Writer, color bool) error {
+	return sd.replay(NewSpreadsheetDiffReport(w, color))
+}
+
+//DiffSpreadsheets compares two spreadsheets on their key columns, see
+//SpreadsheetDiffOptions. The rows of both spreadsheets are sorted first, so
+//unlike DiffRows(...) the order of the rows does not matter. The spreadsheets
+//themselves are not changed. To compare SpreadsheetDelim instances, wrap them
+//in a SpreadsheetDelimSheet.
+func DiffSpreadsheets(old, new Spreadsheeter, options SpreadsheetDiffOptions) (*SpreadsheetDiff, error) {
+	oldData, newData := spreadsheetData(old), spreadsheetData(new)
+	var oldFirst, newFirst []string
+
+	if len(oldData) != 0 {
+		oldFirst = oldData[0]
+	}
+
+	if len(newData) != 0 {
+		newFirst = newData[0]
+	}
+
+	oldNames, newNames := diffColumnNames(oldFirst, newFirst, options.Header)
+	keys, err := diffNewKeys(options, oldNames, newNames)
+
+	if err != nil {
+		return nil, err
+	}
+
+	oldData = joinSorted(oldData, options.Keys, options.Header, options.Compare)
+	newData = joinSorted(newData, keys, options.Header, options.Compare)
+	result := &SpreadsheetDiff{}
+
+	if err = DiffRows(&spreadsheetDelimRows{oldData, 0}, &spreadsheetDelimRows{newData, 0}, result, options); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+//The markers used within the first column of a patch
+const (
+	patchSchema   = "!"
+	patchHeader   = "@@"
+	patchInserted = "+++"
+	patchDeleted  = "---"
+	patchModified = "->"
+	patchMoved    = ":"
+)
+
+//SpreadsheetPatchWriter writes the differences between two spreadsheets as a
+//patch, which is a spreadsheet itself. Its first column contains a marker, the
+//remaining columns are the columns of the new spreadsheet followed by the
+//removed columns of the old spreadsheet.
+//
+//If the columns changed the patch starts with a '!' row, which marks added
+//columns with '+++', removed columns with '---' and moved columns with ':'.
+//The next row starts with '@@' and contains the column names. Inserted rows
+//start with '+++' and contain the new values, deleted rows start with '---'
+//and contain the old values. Modified rows start with '->' and contain the new
+//values, changed values are written as the old and new value seperated by
+//'->'.
+//
+//All values except the markers are escaped, such that the seperator of a
+//changed value is never part of a value: backslashes are written as '\\' and
+//'>' characters as '\>'. A value is unescaped by removing the backslash before
+//every escaped character, see UnescapePatchValue(...). New instances should be
+//created using NewSpreadsheetPatchWriter(...).
+type SpreadsheetPatchWriter struct {
+	w       RowWriter
+	columns [][2]int //the old and new index of every patch column, -1 if missing
+	names   []string
+}
+
+//patchEscaper escapes the values written to a patch
+var patchEscaper = strings.NewReplacer(`\`, `\\`, ">", `\>`)
+
+//UnescapePatchValue returns the original value of an escaped patch value, see
+//SpreadsheetPatchWriter. A changed value has to be split at its unescaped '->'
+//seperator first.
+func UnescapePatchValue(value string) string {
+	var result strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+
+		result.WriteByte(value[i])
+	}
+
+	return result.String()
+}
+
+//NewSpreadsheetPatchWriter creates a new SpreadsheetPatchWriter writing the
+//patch rows to the specified writer and returns its pointer
+func NewSpreadsheetPatchWriter(w RowWriter) *SpreadsheetPatchWriter {
+	return &SpreadsheetPatchWriter{w, nil, nil}
+}
+
+//WriteColumns writes the schema and header rows of the patch
+func (spw *SpreadsheetPatchWriter) WriteColumns(old, new []string, changes []ColumnChange) error {
+	spw.columns, spw.names = nil, nil
+
+	for i, name := range new {
+		spw.columns = append(spw.columns, [2]int{diffColumnIndex(old, name), i})
+		spw.names = append(spw.names, name)
+	}
+
+	for i, name := range old {
+		if diffColumnIndex(new, name) < 0 {
+			spw.columns = append(spw.columns, [2]int{i, -1})
+			spw.names = append(spw.names, name)
+		}
+	}
+
+	if len(changes) != 0 {
+		schema := make([]string, len(spw.columns)+1)
+		schema[0] = patchSchema
+
+		for _, change := range changes {
+			switch change.Type {
+			case ColumnAdded:
+				schema[change.New+1] = patchInserted
+			case ColumnMoved:
+				schema[change.New+1] = patchMoved
+			case ColumnRemoved:
+				for i, column := range spw.columns {
+					if column[0] == change.Old && column[1] < 0 {
+						schema[i+1] = patchDeleted
+					}
+				}
+			}
+		}
+
+		if err := spw.w.WriteRow(schema); err != nil {
+			return err
+		}
+	}
+
+	header := []string{patchHeader}
+
+	for _, name := range spw.names {
+		header = append(header, patchEscaper.Replace(name))
+	}
+
+	return spw.w.WriteRow(header)
+}
+
+//WriteChange writes a single patch row
+func (spw *SpreadsheetPatchWriter) WriteChange(change RowChange) error {
+	row := make([]string, len(spw.columns)+1)
+
+	switch change.Type {
+	case RowInserted:
+		row[0] = patchInserted
+	case RowDeleted:
+		row[0] = patchDeleted
+	default:
+		row[0] = patchModified
+	}
+
+	for i, column := range spw.columns {
+		oldValue, newValue := "", ""
+
+		if column[0] >= 0 && change.Old != nil {
+			oldValue = rowValue(change.Old, column[0])
+		}
+
+		if column[1] >= 0 && change.New != nil {
+			newValue = rowValue(change.New, column[1])
+		}
+
+		oldValue, newValue = patchEscaper.Replace(oldValue), patchEscaper.Replace(newValue)
+
+		switch {
+		case change.Type == RowDeleted || column[1] < 0:
+			row[i+1] = oldValue
+		case change.Type == RowModified && column[0] >= 0 && oldValue != newValue:
+			row[i+1] = oldValue + patchModified + newValue
+		default:
+			row[i+1] = newValue
+		}
+	}
+
+	return spw.w.WriteRow(row)
+}
+
+//Flush does nothing, as the rows are written to the RowWriter directly. If the
+//RowWriter buffers its rows it has to be flushed seperately.
+func (spw *SpreadsheetPatchWriter) Flush() error {
+	return nil
+}
+
+//The ANSI escape sequences used to color the report
+const (
+	diffColorInserted = "\x1b[32m"
+	diffColorDeleted  = "\x1b[31m"
+	diffColorModified = "\x1b[33m"
+	diffColorReset    = "\x1b[0m"
+)
+
+//SpreadsheetDiffReport writes the differences between two spreadsheets as a
+//human-readable text report. Every schema change and changed row is written on
+//a single line, prefixed by '+' (added), '-' (removed) or '~' (modified or
+//moved) and followed by a summary line. Rows are identified by their key
+//values in square brackets. If Color is true the lines are colored using ANSI
+//escape sequences, for display in a terminal. New instances should be created
+//using NewSpreadsheetDiffReport(...).
+type SpreadsheetDiffReport struct {
+	writer   *bufio.Writer
+	Color    bool
+	old      []string
+	new      []string
+	inserted int
+	deleted  int
+	modified int
+}
+
+//NewSpreadsheetDiffReport creates a new SpreadsheetDiffReport writing to the
+//specified writer and returns its pointer
+func NewSpreadsheetDiffReport(w io.Writer, color bool) *SpreadsheetDiffReport {
+	return &SpreadsheetDiffReport{bufio.NewWriter(w), color, nil, nil, 0, 0, 0}
+}
+
+//line writes a single line of the report using the specified color
+func (sdr *SpreadsheetDiffReport) line(color, text string) {
+	if sdr.Color {
+		text = color + text + diffColorReset
+	}
+
+	sdr.writer.WriteString(text + "\n")
+}
+
+//values describes the values of a row as comma-seperated 'column=value' pairs
+func (sdr *SpreadsheetDiffReport) values(names, row []string) string {
+	pairs := make([]string, len(names))
+
+	for i, name := range names {
+		pairs[i] = name + "=" + rowValue(row, i)
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+//WriteColumns writes the schema changes
+func (sdr *SpreadsheetDiffReport) WriteColumns(old, new []string, changes []ColumnChange) error {
+	sdr.old, sdr.new = old, new
+	sdr.inserted, sdr.deleted, sdr.modified = 0, 0, 0
+
+	for _, change := range changes {
+		switch change.Type {
+		case ColumnAdded:
+			sdr.line(diffColorInserted, "+ column "+change.Name)
+		case ColumnRemoved:
+			sdr.line(diffColorDeleted, "- column "+change.Name)
+		default:
+			sdr.line(diffColorModified, "~ column "+change.Name+" moved from "+strconv.Itoa(change.Old)+" to "+strconv.Itoa(change.New))
+		}
+	}
+
+	return nil
+}
+
+//WriteChange writes a single changed row
+func (sdr *SpreadsheetDiffReport) WriteChange(change RowChange) error {
+	key := "[" + strings.Join(change.Key, ", ") + "] "
+
+	switch change.Type {
+	case RowInserted:
+		sdr.inserted++
+		sdr.line(diffColorInserted, "+ "+key+sdr.values(sdr.new, change.New))
+	case RowDeleted:
+		sdr.deleted++
+		sdr.line(diffColorDeleted, "- "+key+sdr.values(sdr.old, change.Old))
+	default:
+		sdr.modified++
+		cells := make([]string, len(change.Cells))
+
+		for i, cell := range change.Cells {
+			cells[i] = cell.Column + ": " + cell.Old + " -> " + cell.New
+		}
+
+		sdr.line(diffColorModified, "~ "+key+strings.Join(cells, ", "))
+	}
+
+	return nil
+}
+
+//Flush writes the summary line and flushes the buffered report
+func (sdr *SpreadsheetDiffReport) Flush() error {
+	sdr.writer.WriteString(strconv.Itoa(sdr.inserted) + " inserted, " + strconv.Itoa(sdr.deleted) + " deleted, " + strconv.Itoa(sdr.modified) + " modified\n")
+
+	if err := sdr.writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetDiffReport", "Failed to write the report"}
+	}
+
+	return nil
+}
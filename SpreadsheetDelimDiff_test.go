@@ -0,0 +1,186 @@
+package fio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSpreadsheetDelimDiffSheets() (SpreadsheetDelimSheet, SpreadsheetDelimSheet) {
+	old := NewSpreadsheetDelim(16, ",")
+	old.Data = [][]string{
+		{"id", "name", "city", "zip"},
+		{"3", "dan", "Gouda", "2800"},
+		{"1", "ann", "Leiden", "2300"},
+		{"2", "bob", "Delft", "2600"},
+	}
+
+	new := NewSpreadsheetDelim(16, ",")
+	new.Data = [][]string{
+		{"name", "id", "city", "amount"},
+		{"ann", "1", "Breda", "10"},
+		{"eve", "4", "Ede", "5"},
+		{"dan", "3", "Gouda", "7"},
+	}
+
+	return SpreadsheetDelimSheet{old}, SpreadsheetDelimSheet{new}
+}
+
+func TestDiffSpreadsheets(t *testing.T) {
+	old, new := testSpreadsheetDelimDiffSheets()
+	diff, err := DiffSpreadsheets(old, new, SpreadsheetDiffOptions{Keys: []int{0}, Compare: CompareNumeric, Header: true})
+
+	if err != nil {
+		t.Fatalf("Failed to compare spreadsheets, error: %s\n", err.Error())
+	}
+
+	columns := []ColumnChange{
+		{ColumnAdded, "amount", -1, 3},
+		{ColumnRemoved, "zip", 3, -1},
+		{ColumnMoved, "id", 0, 1},
+	}
+
+	if !reflect.DeepEqual(diff.Columns, columns) {
+		t.Errorf("%v != %v\n", diff.Columns, columns)
+	}
+
+	rows := []RowChange{
+		{RowModified, []string{"1"}, []string{"1", "ann", "Leiden", "2300"}, []string{"ann", "1", "Breda", "10"}, []CellChange{{"city", "Leiden", "Breda"}}},
+		{RowDeleted, []string{"2"}, []string{"2", "bob", "Delft", "2600"}, nil, nil},
+		{RowInserted, []string{"4"}, nil, []string{"eve", "4", "Ede", "5"}, nil},
+	}
+
+	if !reflect.DeepEqual(diff.Rows, rows) {
+		t.Errorf("%v != %v\n", diff.Rows, rows)
+	}
+
+	//the patch
+	patch := NewSpreadsheetDelim(16, ",")
+
+	if err = diff.WritePatch(patch); err != nil {
+		t.Errorf("Failed to write the patch, error: %s\n", err.Error())
+	}
+
+	expected := [][]string{
+		{"!", "", ":", "", "+++", "---"},
+		{"@@", "name", "id", "city", "amount", "zip"},
+		{"->", "ann", "1", "Leiden->Breda", "10", "2300"},
+		{"---", "bob", "2", "Delft", "", "2600"},
+		{"+++", "eve", "4", "Ede", "5", ""},
+	}
+
+	if !reflect.DeepEqual(patch.Data, expected) {
+		t.Errorf("%v != %v\n", patch.Data, expected)
+	}
+
+	//the report, with and without colors
+	var buffer bytes.Buffer
+	diff.Report(&buffer, false)
+	report := "+ column amount\n- column zip\n~ column id moved from 0 to 1\n" +
+		"~ [1] city: Leiden -> Breda\n- [2] id=2, name=bob, city=Delft, zip=2600\n+ [4] name=eve, id=4, city=Ede, amount=5\n" +
+		"1 inserted, 1 deleted, 1 modified\n"
+
+	if buffer.String() != report {
+		t.Errorf("Unexpected report:\n%s\n", buffer.String())
+	}
+
+	buffer.Reset()
+	diff.Report(&buffer, true)
+
+	if !strings.HasPrefix(buffer.String(), "\x1b[32m+ column amount\x1b[0m\n") {
+		t.Errorf("Expected a colored report, got %q\n", buffer.String())
+	}
+
+	//comparing a spreadsheet to itself
+	if diff, err = DiffSpreadsheets(old, old, SpreadsheetDiffOptions{Keys: []int{0}, Header: true}); err != nil || !diff.Empty() {
+		t.Errorf("Expected no differences, got %v (error: %v)\n", diff, err)
+	}
+
+	//comparing to an empty spreadsheet reports all rows as inserted or deleted
+	empty := SpreadsheetDelimSheet{NewSpreadsheetDelim(16, ",")}
+
+	if diff, err = DiffSpreadsheets(empty, new, SpreadsheetDiffOptions{Keys: []int{1}, Header: true}); err != nil || len(diff.Columns) != 0 || len(diff.Rows) != 3 || diff.Rows[0].Type != RowInserted {
+		t.Errorf("Unexpected differences with an empty old spreadsheet %v (error: %v)\n", diff, err)
+	}
+
+	if diff, err = DiffSpreadsheets(old, empty, SpreadsheetDiffOptions{Keys: []int{0}, Header: true}); err != nil || len(diff.Columns) != 0 || len(diff.Rows) != 3 || diff.Rows[0].Type != RowDeleted {
+		t.Errorf("Unexpected differences with an empty new spreadsheet %v (error: %v)\n", diff, err)
+	}
+
+	if diff, err = DiffSpreadsheets(empty, empty, SpreadsheetDiffOptions{Keys: []int{0}, Header: true}); err != nil || !diff.Empty() {
+		t.Errorf("Expected no differences between empty spreadsheets, got %v (error: %v)\n", diff, err)
+	}
+
+	//invalid keys
+	if _, err = DiffSpreadsheets(old, new, SpreadsheetDiffOptions{Keys: []int{3}, Header: true}); err == nil {
+		t.Errorf("Expected a missing key column to fail\n")
+	}
+
+	if _, err = DiffSpreadsheets(old, new, SpreadsheetDiffOptions{Header: true}); err == nil {
+		t.Errorf("Expected missing key columns to fail\n")
+	}
+}
+
+func TestDiffRows(t *testing.T) {
+	old := "a,1,x\nb,2,y\nc,3,z\n"
+	new := "a,1,x\nb,5,y\nd,4,w\n"
+	diff := &SpreadsheetDiff{}
+
+	err := DiffRows(NewSpreadsheetDelimReader(strings.NewReader(old), 16, ","), NewSpreadsheetDelimReader(strings.NewReader(new), 16, ","), diff, SpreadsheetDiffOptions{Keys: []int{0}})
+
+	if err != nil {
+		t.Fatalf("Failed to compare rows, error: %s\n", err.Error())
+	}
+
+	if len(diff.Columns) != 0 || !reflect.DeepEqual(diff.NewColumns, []string{"0", "1", "2"}) {
+		t.Errorf("Unexpected columns %v\n", diff.NewColumns)
+	}
+
+	rows := []RowChange{
+		{RowModified, []string{"b"}, []string{"b", "2", "y"}, []string{"b", "5", "y"}, []CellChange{{"1", "2", "5"}}},
+		{RowDeleted, []string{"c"}, []string{"c", "3", "z"}, nil, nil},
+		{RowInserted, []string{"d"}, nil, []string{"d", "4", "w"}, nil},
+	}
+
+	if !reflect.DeepEqual(diff.Rows, rows) {
+		t.Errorf("%v != %v\n", diff.Rows, rows)
+	}
+
+	//unsorted inputs and duplicate keys are rejected
+	inputs := []string{"b,1\na,2\n", "a,1\na,2\n"}
+
+	for _, input := range inputs {
+		err = DiffRows(NewSpreadsheetDelimReader(strings.NewReader(input), 16, ","), NewSpreadsheetDelimReader(strings.NewReader(new), 16, ","), &SpreadsheetDiff{}, SpreadsheetDiffOptions{Keys: []int{0}})
+
+		if err == nil {
+			t.Errorf("Expected %q to be rejected\n", input)
+		}
+	}
+}
+
+func TestSpreadsheetPatchWriterEscape(t *testing.T) {
+	diff := &SpreadsheetDiff{}
+	options := SpreadsheetDiffOptions{Keys: []int{0}}
+	err := DiffRows(&spreadsheetDelimRows{[][]string{{"k", "a->b", `c\`}}, 0}, &spreadsheetDelimRows{[][]string{{"k", "a->b", "d"}}, 0}, diff, options)
+
+	if err != nil {
+		t.Fatalf("Failed to compare rows, error: %s\n", err.Error())
+	}
+
+	patch := NewSpreadsheetDelim(16, ",")
+	diff.WritePatch(patch)
+
+	//the unchanged value cannot be mistaken for a change
+	expected := []string{"->", "k", `a-\>b`, `c\\->d`}
+
+	if !reflect.DeepEqual(patch.Data[1], expected) {
+		t.Fatalf("%q != %q\n", patch.Data[1], expected)
+	}
+
+	changed := strings.SplitN(patch.Data[1][3], "->", 2)
+
+	if UnescapePatchValue(patch.Data[1][2]) != "a->b" || UnescapePatchValue(changed[0]) != `c\` || UnescapePatchValue(changed[1]) != "d" {
+		t.Errorf("Failed to unescape the patch values %q\n", patch.Data[1])
+	}
+}
@@ -0,0 +1,180 @@
+package fio
+
+//Width returns the number of columns of the widest row within the spreadsheet.
+//As rows can be ragged, other rows may contain less values.
+func (sd *SpreadsheetDelim) Width() int {
+	width := 0
+
+	for _, row := range sd.Data {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	return width
+}
+
+//InsertRow will insert a copy of the specified values as a new row before the
+//specified row. The row may be equal to the number of rows to append a row. The
+//function returns an error if the row is out of range.
+func (sd *SpreadsheetDelim) InsertRow(row int, values []string) error {
+	if row < 0 || row > len(sd.Data) {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Row to insert at is out of range"}
+	}
+
+	sd.Data = append(sd.Data, nil)
+	copy(sd.Data[row+1:], sd.Data[row:])
+	sd.Data[row] = append([]string{}, values...)
+	return nil
+}
+
+//DeleteRow will remove the specified row. The function returns an error if the
+//row does not exist.
+func (sd *SpreadsheetDelim) DeleteRow(row int) error {
+	if row < 0 || row >= len(sd.Data) {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Row to delete is out of range"}
+	}
+
+	sd.Data = append(sd.Data[:row], sd.Data[row+1:]...)
+	return nil
+}
+
+//InsertColumn will insert a new column containing the specified value before
+//the specified column. The column may be equal to the width of the spreadsheet
+//to append a column. Rows that are too short to contain a value before the
+//specified column are left unchanged, as they have no values to shift. The
+//function returns an error if the column is out of range.
+func (sd *SpreadsheetDelim) InsertColumn(col int, value string) error {
+	if col < 0 || col > sd.Width() {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Column to insert at is out of range"}
+	}
+
+	for i, row := range sd.Data {
+		if len(row) < col {
+			continue
+		}
+
+		row = append(row, "")
+		copy(row[col+1:], row[col:])
+		row[col] = value
+		sd.Data[i] = row
+	}
+
+	return nil
+}
+
+//DeleteColumn will remove the specified column from all rows. Rows that are too
+//short to contain the column are left unchanged. The function returns an error
+//if the column does not exist in any row.
+func (sd *SpreadsheetDelim) DeleteColumn(col int) error {
+	if col < 0 || col >= sd.Width() {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Column to delete is out of range"}
+	}
+
+	for i, row := range sd.Data {
+		if len(row) > col {
+			sd.Data[i] = append(row[:col], row[col+1:]...)
+		}
+	}
+
+	return nil
+}
+
+//MoveColumn will move the column at index from such that it ends up at index
+//to, shifting the columns in between. Rows that are too short to contain both
+//columns are padded with empty values first. The function returns an error if
+//either column is out of range.
+func (sd *SpreadsheetDelim) MoveColumn(from, to int) error {
+	width := sd.Width()
+
+	if from < 0 || from >= width || to < 0 || to >= width {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Column to move is out of range"}
+	}
+
+	if from == to {
+		return nil
+	}
+
+	last := from
+
+	if to > last {
+		last = to
+	}
+
+	for i, row := range sd.Data {
+		for len(row) <= last {
+			row = append(row, "")
+		}
+
+		value := row[from]
+
+		if from < to {
+			copy(row[from:to], row[from+1:to+1])
+		} else {
+			copy(row[to+1:from+1], row[to:from])
+		}
+
+		row[to] = value
+		sd.Data[i] = row
+	}
+
+	return nil
+}
+
+//SwapRows will exchange the two specified rows. The function returns an error
+//if either row does not exist.
+func (sd *SpreadsheetDelim) SwapRows(a, b int) error {
+	if a < 0 || a >= len(sd.Data) || b < 0 || b >= len(sd.Data) {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Row to swap is out of range"}
+	}
+
+	sd.Data[a], sd.Data[b] = sd.Data[b], sd.Data[a]
+	return nil
+}
+
+//Transpose will exchange the rows and columns of the spreadsheet, such that the
+//value at [r, c] ends up at [c, r]. The result contains one row for every
+//column of the widest row, values missing from ragged rows become empty values.
+func (sd *SpreadsheetDelim) Transpose() {
+	width := sd.Width()
+	data := make([][]string, width)
+
+	for col := range data {
+		data[col] = make([]string, len(sd.Data))
+
+		for row := range sd.Data {
+			if col < len(sd.Data[row]) {
+				data[col][row] = sd.Data[row][col]
+			}
+		}
+	}
+
+	sd.Data = data
+}
+
+//NormalizeWidth will pad all rows with empty values up to the specified width,
+//such that the spreadsheet is no longer ragged. If the width is zero the width
+//of the widest row is used. The function returns an error if the width is
+//negative or if a row is wider than the specified width, as values would be
+//lost.
+func (sd *SpreadsheetDelim) NormalizeWidth(width int) error {
+	widest := sd.Width()
+
+	if width == 0 {
+		width = widest
+	}
+
+	if width < 0 || width < widest {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Width is smaller than the widest row"}
+	}
+
+	for i, row := range sd.Data {
+		for len(row) < width {
+			row = append(row, "")
+		}
+
+		sd.Data[i] = row
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package fio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testSpreadsheetDelimEditCompare(operation string, sd *SpreadsheetDelim, expected [][]string, t *testing.T) {
+	if !reflect.DeepEqual(sd.Data, expected) {
+		t.Errorf("%s: %q != %q\n", operation, sd.Data, expected)
+	}
+}
+
+func TestSpreadsheetDelimEdit(t *testing.T) {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Data = [][]string{
+		{"a", "b", "c"},
+		{"d"},
+		{"e", "f"},
+	}
+
+	sd.InsertRow(1, []string{"x", "y"})
+	testSpreadsheetDelimEditCompare("InsertRow", sd, [][]string{{"a", "b", "c"}, {"x", "y"}, {"d"}, {"e", "f"}}, t)
+
+	sd.InsertRow(4, nil)
+	sd.DeleteRow(1)
+	testSpreadsheetDelimEditCompare("DeleteRow", sd, [][]string{{"a", "b", "c"}, {"d"}, {"e", "f"}, {}}, t)
+
+	sd.DeleteRow(3)
+	sd.InsertColumn(2, "n")
+	testSpreadsheetDelimEditCompare("InsertColumn", sd, [][]string{{"a", "b", "n", "c"}, {"d"}, {"e", "f", "n"}}, t)
+
+	sd.DeleteColumn(1)
+	testSpreadsheetDelimEditCompare("DeleteColumn", sd, [][]string{{"a", "n", "c"}, {"d"}, {"e", "n"}}, t)
+
+	sd.MoveColumn(2, 0)
+	testSpreadsheetDelimEditCompare("MoveColumn", sd, [][]string{{"c", "a", "n"}, {"", "d", ""}, {"", "e", "n"}}, t)
+
+	sd.MoveColumn(0, 2)
+	testSpreadsheetDelimEditCompare("MoveColumn", sd, [][]string{{"a", "n", "c"}, {"d", "", ""}, {"e", "n", ""}}, t)
+
+	sd.SwapRows(0, 2)
+	testSpreadsheetDelimEditCompare("SwapRows", sd, [][]string{{"e", "n", ""}, {"d", "", ""}, {"a", "n", "c"}}, t)
+
+	sd.Data[1] = []string{"d"}
+	sd.Transpose()
+	testSpreadsheetDelimEditCompare("Transpose", sd, [][]string{{"e", "d", "a"}, {"n", "", "n"}, {"", "", "c"}}, t)
+
+	sd.Data = [][]string{{"a"}, {"b", "c"}}
+	sd.NormalizeWidth(0)
+	testSpreadsheetDelimEditCompare("NormalizeWidth", sd, [][]string{{"a", ""}, {"b", "c"}}, t)
+
+	sd.NormalizeWidth(3)
+	testSpreadsheetDelimEditCompare("NormalizeWidth", sd, [][]string{{"a", "", ""}, {"b", "c", ""}}, t)
+
+	//check the out-of-range arguments
+	failures := [...]struct {
+		operation string
+		err       error
+	}{
+		{"InsertRow", sd.InsertRow(3, nil)},
+		{"InsertRow", sd.InsertRow(-1, nil)},
+		{"DeleteRow", sd.DeleteRow(2)},
+		{"InsertColumn", sd.InsertColumn(4, "")},
+		{"DeleteColumn", sd.DeleteColumn(3)},
+		{"MoveColumn", sd.MoveColumn(0, 3)},
+		{"SwapRows", sd.SwapRows(0, 2)},
+		{"NormalizeWidth", sd.NormalizeWidth(2)},
+	}
+
+	for _, failure := range failures {
+		if failure.err == nil {
+			t.Errorf("Expected %s with out-of-range arguments to fail\n", failure.operation)
+		} else if failure.err.(Error).t != ErrorTypeInvalidArgument {
+			t.Errorf("Expected %s to return an invalid argument error\n", failure.operation)
+		}
+	}
+
+	testSpreadsheetDelimEditCompare("Failures", sd, [][]string{{"a", "", ""}, {"b", "c", ""}}, t)
+}
@@ -0,0 +1,467 @@
+package fio
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//The Aggregator interface is implemented by the functions that summarize the
+//values of a column within a group. A new Aggregator is created for every
+//group, after which Add(...) is called for the value of every row within the
+//group. Add returns an error if the value cannot be aggregated.
+type Aggregator interface {
+	Add(value string) error
+	Result() string
+}
+
+//Aggregation describes a single summarizing column of the result of a
+//grouping. Column is the column whose values are aggregated, New creates an
+//Aggregator for a group. The resulting column is called Name, if Name is empty
+//it is derived from the Function and the name of the aggregated column, such as
+//'sum(amount)'. User-defined aggregations only need to provide New.
+type Aggregation struct {
+	Name     string
+	Function string
+	Column   int
+	New      func() Aggregator
+}
+
+//aggregatorFunc implements the Aggregator interface using two closures
+type aggregatorFunc struct {
+	add    func(value string) error
+	result func() string
+}
+
+func (af aggregatorFunc) Add(value string) error {
+	return af.add(value)
+}
+
+func (af aggregatorFunc) Result() string {
+	return af.result()
+}
+
+//formatFloat converts a float to its shortest string representation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+//parseAggregateFloat converts a value to a float for the aggregation functions.
+//Empty values are skipped, indicated by the boolean being false.
+func parseAggregateFloat(value string) (float64, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if len(value) == 0 {
+		return 0, false, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return 0, false, Error{ErrorTypeParsing, "Aggregate", "Value '" + value + "' is not a number"}
+	}
+
+	return f, true, nil
+}
+
+//AggregateSum sums the numeric values of the column, empty values are skipped
+func AggregateSum(column int) Aggregation {
+	return Aggregation{"", "sum", column, func() Aggregator {
+		sum := 0.0
+		return aggregatorFunc{func(value string) error {
+			f, ok, err := parseAggregateFloat(value)
+
+			if ok {
+				sum += f
+			}
+
+			return err
+		}, func() string {
+			return formatFloat(sum)
+		}}
+	}}
+}
+
+//AggregateCount counts the non-empty values of the column. If the column is
+//negative all rows are counted.
+func AggregateCount(column int) Aggregation {
+	return Aggregation{"", "count", column, func() Aggregator {
+		count := 0
+		return aggregatorFunc{func(value string) error {
+			if column < 0 || len(value) != 0 {
+				count++
+			}
+
+			return nil
+		}, func() string {
+			return strconv.Itoa(count)
+		}}
+	}}
+}
+
+//AggregateMean averages the numeric values of the column, empty values are
+//skipped. The result is empty if the group contains no values.
+func AggregateMean(column int) Aggregation {
+	return Aggregation{"", "mean", column, func() Aggregator {
+		sum, count := 0.0, 0
+		return aggregatorFunc{func(value string) error {
+			f, ok, err := parseAggregateFloat(value)
+
+			if ok {
+				sum += f
+				count++
+			}
+
+			return err
+		}, func() string {
+			if count == 0 {
+				return ""
+			}
+
+			return formatFloat(sum / float64(count))
+		}}
+	}}
+}
+
+//aggregateExtreme implements the minimum and maximum aggregations, keeping the
+//value for which the comparison to the current value returns sign
+func aggregateExtreme(function string, column int, compareType CompareType, sign int) Aggregation {
+	return Aggregation{"", function, column, func() Aggregator {
+		result, found := "", false
+		return aggregatorFunc{func(value string) error {
+			if len(value) == 0 {
+				return nil
+			}
+
+			if !compareConvertible(value, compareType, "") {
+				return Error{ErrorTypeParsing, "Aggregate", "Value '" + value + "' cannot be compared"}
+			}
+
+			if !found || CompareValues(value, result, compareType, "") == sign {
+				result, found = value, true
+			}
+
+			return nil
+		}, func() string {
+			return result
+		}}
+	}}
+}
+
+//AggregateMin returns the smallest non-empty value of the column, using the
+//specified comparison
+func AggregateMin(column int, compareType CompareType) Aggregation {
+	return aggregateExtreme("min", column, compareType, -1)
+}
+
+//AggregateMax returns the largest non-empty value of the column, using the
+//specified comparison
+func AggregateMax(column int, compareType CompareType) Aggregation {
+	return aggregateExtreme("max", column, compareType, 1)
+}
+
+//AggregateMedian returns the median of the numeric values of the column,
+//empty values are skipped. The result is empty if the group contains no values.
+func AggregateMedian(column int) Aggregation {
+	return Aggregation{"", "median", column, func() Aggregator {
+		var values []float64
+		return aggregatorFunc{func(value string) error {
+			f, ok, err := parseAggregateFloat(value)
+
+			if ok {
+				values = append(values, f)
+			}
+
+			return err
+		}, func() string {
+			if len(values) == 0 {
+				return ""
+			}
+
+			sort.Float64s(values)
+			middle := len(values) / 2
+
+			if len(values)%2 == 0 {
+				return formatFloat((values[middle-1] + values[middle]) / 2)
+			}
+
+			return formatFloat(values[middle])
+		}}
+	}}
+}
+
+//AggregateDistinctCount counts the distinct non-empty values of the column
+func AggregateDistinctCount(column int) Aggregation {
+	return Aggregation{"", "distinct", column, func() Aggregator {
+		seen := make(map[string]bool)
+		return aggregatorFunc{func(value string) error {
+			if len(value) != 0 {
+				seen[value] = true
+			}
+
+			return nil
+		}, func() string {
+			return strconv.Itoa(len(seen))
+		}}
+	}}
+}
+
+//AggregateFirst returns the value of the column in the first row of the group
+func AggregateFirst(column int) Aggregation {
+	return Aggregation{"", "first", column, func() Aggregator {
+		result, found := "", false
+		return aggregatorFunc{func(value string) error {
+			if !found {
+				result, found = value, true
+			}
+
+			return nil
+		}, func() string {
+			return result
+		}}
+	}}
+}
+
+//AggregateLast returns the value of the column in the last row of the group
+func AggregateLast(column int) Aggregation {
+	return Aggregation{"", "last", column, func() Aggregator {
+		result := ""
+		return aggregatorFunc{func(value string) error {
+			result = value
+			return nil
+		}, func() string {
+			return result
+		}}
+	}}
+}
+
+//Grouping groups the rows of a spreadsheet by the values of one or more key
+//columns, after which Aggregate(...) summarizes every group. Groupings are
+//created using SpreadsheetDelim.GroupBy(...) or GroupRows(...).
+type Grouping struct {
+	rows      RowReader
+	header    bool
+	keys      []int
+	streaming bool
+	compare   CompareType
+	buffer    int
+	delimeter string
+}
+
+//GroupBy groups the rows of the spreadsheet by the values of the specified key
+//columns. The rows do not have to be sorted, the groups appear in the result in
+//the order in which they are first encountered. If the spreadsheet has a
+//header its column names are used to name the result columns.
+func (sd *SpreadsheetDelim) GroupBy(keys ...int) *Grouping {
+	return &Grouping{sd.Rows(), sd.Header, keys, false, CompareLexical, sd.buffer, sd.delimeter}
+}
+
+//GroupRows groups the rows read from the reader by the values of the specified
+//key columns. The rows have to be sorted by the key columns using the
+//specified comparison (in ascending order), as a group is summarized as soon as
+//a row with different key values is read. This allows files of any size to be
+//grouped when streaming from a SpreadsheetDelimReader. A row with smaller key
+//values than the previous row causes an error. If header is true the first row
+//read contains the column names. The buffer size and delimeter are used for the
+//result of Aggregate(...).
+func GroupRows(r RowReader, buffer int, delimeter string, header bool, compareType CompareType, keys ...int) *Grouping {
+	return &Grouping{r, header, keys, true, compareType, buffer, delimeter}
+}
+
+//aggregateGroup holds the key values and the aggregators of a single group
+type aggregateGroup struct {
+	keys        []string
+	aggregators []Aggregator
+}
+
+//AggregateTo summarizes every group using the specified aggregations and writes
+//the result to the writer. The first row written is a header containing the
+//names of the key columns followed by the names of the aggregations. Every
+//following row contains the key values of a group followed by the aggregated
+//values. Errors returned by an aggregator are returned with the row at which
+//they occured.
+func (g *Grouping) AggregateTo(w RowWriter, aggregations ...Aggregation) error {
+	if len(g.keys) == 0 {
+		return Error{ErrorTypeInvalidArgument, "Grouping", "No key columns specified to group by"}
+	}
+
+	for _, aggregation := range aggregations {
+		if aggregation.New == nil {
+			return Error{ErrorTypeInvalidArgument, "Grouping", "Aggregation without New function specified"}
+		}
+	}
+
+	//determine the names of the result columns
+	var names []string
+	index := 0
+
+	if g.header {
+		var err error
+		names, err = g.rows.ReadRow()
+		index++
+
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	columnName := func(column int) string {
+		if column < 0 {
+			return "*"
+		}
+
+		if column < len(names) {
+			return names[column]
+		}
+
+		return strconv.Itoa(column)
+	}
+
+	var header []string
+
+	for _, key := range g.keys {
+		header = append(header, columnName(key))
+	}
+
+	for _, aggregation := range aggregations {
+		name := aggregation.Name
+
+		if len(name) == 0 {
+			name = aggregation.Function + "(" + columnName(aggregation.Column) + ")"
+		}
+
+		header = append(header, name)
+	}
+
+	if err := w.WriteRow(header); err != nil {
+		return err
+	}
+
+	//read and aggregate all rows
+	var order []*aggregateGroup
+	groups := make(map[string]*aggregateGroup)
+	var current *aggregateGroup
+
+	write := func(group *aggregateGroup) error {
+		row := append([]string(nil), group.keys...)
+
+		for _, aggregator := range group.aggregators {
+			row = append(row, aggregator.Result())
+		}
+
+		return w.WriteRow(row)
+	}
+
+	for ; ; index++ {
+		row, err := g.rows.ReadRow()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, len(g.keys))
+
+		for i, key := range g.keys {
+			keys[i] = rowValue(row, key)
+		}
+
+		//find the group of the row
+		var group *aggregateGroup
+
+		if g.streaming {
+			if current != nil && compareKeyValues(keys, current.keys, g.compare) < 0 {
+				return Error{ErrorTypeInvalidArgument, "Grouping", "Row " + strconv.Itoa(index) + " is not sorted on the key columns"}
+			}
+
+			if current != nil && !equalStrings(current.keys, keys) {
+				if err = write(current); err != nil {
+					return err
+				}
+
+				current = nil
+			}
+
+			group = current
+		} else {
+			group = groups[joinKey(keys)]
+		}
+
+		if group == nil {
+			group = &aggregateGroup{keys, make([]Aggregator, len(aggregations))}
+
+			for i, aggregation := range aggregations {
+				group.aggregators[i] = aggregation.New()
+			}
+
+			if g.streaming {
+				current = group
+			} else {
+				groups[joinKey(keys)] = group
+				order = append(order, group)
+			}
+		}
+
+		for i, aggregation := range aggregations {
+			if err = group.aggregators[i].Add(rowValue(row, aggregation.Column)); err != nil {
+				return Error{ErrorTypeParsing, "Grouping", "Failed to aggregate row " + strconv.Itoa(index) + ", column " + strconv.Itoa(aggregation.Column) + ": " + err.Error()}
+			}
+		}
+	}
+
+	if current != nil {
+		order = append(order, current)
+	}
+
+	for _, group := range order {
+		if err := write(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Aggregate summarizes every group using the specified aggregations and returns
+//the result as a new SpreadsheetDelim instance with a header. See
+//AggregateTo(...).
+func (g *Grouping) Aggregate(aggregations ...Aggregation) (*SpreadsheetDelim, error) {
+	result := NewSpreadsheetDelim(g.buffer, g.delimeter)
+	result.Header = true
+
+	if err := g.AggregateTo(result, aggregations...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+//equalStrings checks if two string slices contain the same values
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+//joinKey combines multiple values into a single unambiguous map key
+func joinKey(values []string) string {
+	var key strings.Builder
+
+	for _, value := range values {
+		key.WriteString(strconv.Quote(value))
+	}
+
+	return key.String()
+}
@@ -0,0 +1,116 @@
+package fio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSpreadsheetDelimGroupSheet() *SpreadsheetDelim {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"customer", "region", "amount"},
+		{"ann", "north", "10"},
+		{"bob", "south", "5"},
+		{"ann", "north", "2.5"},
+		{"cat", "north", ""},
+		{"bob", "south", "7"},
+		{"ann", "east", "1"},
+	}
+
+	return sd
+}
+
+func TestSpreadsheetDelimGroupBy(t *testing.T) {
+	sd := testSpreadsheetDelimGroupSheet()
+	result, err := sd.GroupBy(0).Aggregate(
+		AggregateSum(2),
+		AggregateCount(-1),
+		AggregateCount(2),
+		AggregateMean(2),
+		AggregateMin(2, CompareNumeric),
+		AggregateMax(2, CompareNumeric),
+		AggregateMedian(2),
+		AggregateDistinctCount(1),
+		AggregateFirst(1),
+		AggregateLast(1),
+	)
+
+	if err != nil {
+		t.Fatalf("Failed to aggregate, error: %s\n", err.Error())
+	}
+
+	expected := [][]string{
+		{"customer", "sum(amount)", "count(*)", "count(amount)", "mean(amount)", "min(amount)", "max(amount)", "median(amount)", "distinct(region)", "first(region)", "last(region)"},
+		{"ann", "13.5", "3", "3", "4.5", "1", "10", "2.5", "2", "north", "east"},
+		{"bob", "12", "2", "2", "6", "5", "7", "6", "1", "south", "south"},
+		{"cat", "0", "1", "0", "", "", "", "", "1", "north", "north"},
+	}
+
+	if !reflect.DeepEqual(result.Data, expected) || !result.Header {
+		t.Errorf("%q != %q\n", result.Data, expected)
+	}
+
+	//group by multiple keys with a user-defined aggregation
+	concat := Aggregation{Name: "all", Column: 2, New: func() Aggregator {
+		var values []string
+		return aggregatorFunc{func(value string) error {
+			values = append(values, value)
+			return nil
+		}, func() string {
+			return strings.Join(values, "+")
+		}}
+	}}
+
+	result, err = sd.GroupBy(1, 0).Aggregate(concat)
+	expected = [][]string{
+		{"region", "customer", "all"},
+		{"north", "ann", "10+2.5"},
+		{"south", "bob", "5+7"},
+		{"north", "cat", ""},
+		{"east", "ann", "1"},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+
+	//non-numeric values should fail
+	sd.Data[2][2] = "five"
+
+	if _, err = sd.GroupBy(0).Aggregate(AggregateSum(2)); err == nil {
+		t.Errorf("Expected summing a non-numeric value to fail\n")
+	}
+}
+
+func TestSpreadsheetDelimGroupRows(t *testing.T) {
+	input := "customer;amount\nann;1\nann;2\nbob;3\ncat;4\ncat;5\n"
+	reader := NewSpreadsheetDelimReader(strings.NewReader(input), 8, ";")
+
+	var output strings.Builder
+	writer := NewSpreadsheetDelimWriter(&output, ";")
+	err := GroupRows(reader, 8, ";", true, CompareLexical, 0).AggregateTo(writer, AggregateSum(1), AggregateCount(1))
+	writer.Flush()
+
+	expected := "customer;sum(amount);count(amount)\nann;3;2\nbob;3;1\ncat;9;2\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected streaming result (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	//the result of Aggregate(...) uses the specified delimeter
+	reader = NewSpreadsheetDelimReader(strings.NewReader(input), 8, ";")
+	result, err := GroupRows(reader, 8, ";", true, CompareLexical, 0).Aggregate(AggregateCount(1))
+
+	if err != nil || result.delimeter != ";" || result.buffer != 8 {
+		t.Errorf("Unexpected aggregated spreadsheet (error: %v)\n", err)
+	}
+
+	//unsorted rows are rejected instead of producing a group twice
+	reader = NewSpreadsheetDelimReader(strings.NewReader("b;1\na;2\nb;3\n"), 8, ";")
+
+	if _, err = GroupRows(reader, 8, ";", false, CompareLexical, 0).Aggregate(AggregateCount(1)); err == nil {
+		t.Errorf("Expected unsorted rows to be rejected\n")
+	}
+}
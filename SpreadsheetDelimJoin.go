@@ -0,0 +1,544 @@
+package fio
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//JoinType is the type used to specify which rows are written by a join
+type JoinType byte
+
+//The various joins to use in conjunction with the JoinType type
+const (
+	JoinInner JoinType = iota //only rows with a match in both inputs
+	JoinLeft                  //all rows of the left input, with the matching rows of the right input
+	JoinRight                 //all rows of the right input, with the matching rows of the left input
+	JoinFull                  //all rows of both inputs, matched where possible
+	JoinAnti                  //only the rows of the left input without a match in the right input
+)
+
+//JoinStrategy is the type used to specify how the rows of a join are matched
+type JoinStrategy byte
+
+//The various strategies to use in conjunction with the JoinStrategy type
+const (
+	JoinHash      JoinStrategy = iota //the right input is loaded into a hash table, the left input is streamed
+	JoinSortMerge                     //both inputs are streamed, they have to be sorted on the key columns
+)
+
+//JoinColumn selects a single column of the result of a join. Column is the
+//column within the left input, or within the right input if Right is true.
+type JoinColumn struct {
+	Right  bool
+	Column int
+}
+
+//JoinOptions contains the options used while joining two spreadsheets. The
+//zero value describes an inner hash join, LeftKeys and RightKeys have to be
+//specified and have to contain the same number of columns.
+//
+//The rows are matched on the key columns using the Compare comparison, such
+//that numerically compared keys '1' and '1.0' match using both strategies. The
+//sort-merge strategy requires both inputs to be sorted on the key columns using
+//the same comparison (in ascending order).
+//
+//If Columns is empty the result contains all columns of the left input,
+//followed by the non-key columns of the right input (which are omitted for
+//anti joins). The number of columns of each input is taken from its header, or
+//from its first row if Header is false. If a row of one of the inputs has no
+//match its key columns are filled using the key values of the other input,
+//while its remaining columns are empty.
+//
+//If Header is true the first row of both inputs contains the column names, and
+//the result starts with a header as well. Column names that occur more than
+//once within the result get the LeftSuffix or RightSuffix appended, depending
+//on the input they originate from. If neither suffix is specified '_left' and
+//'_right' are used.
+type JoinOptions struct {
+	Type        JoinType
+	Strategy    JoinStrategy
+	LeftKeys    []int
+	RightKeys   []int
+	Compare     CompareType
+	Header      bool
+	LeftSuffix  string
+	RightSuffix string
+	Columns     []JoinColumn
+}
+
+//The suffixes used for clashing column names when no suffixes are specified
+const (
+	joinLeftSuffix  = "_left"
+	joinRightSuffix = "_right"
+)
+
+//joinInput reads the rows of one of the inputs of a join
+type joinInput struct {
+	rows   RowReader
+	keys   []int
+	row    []string //the current row, nil after the last row
+	key    []string //the key values of the current row
+	count  int      //the number of rows read so far
+	sorted bool     //check if the rows are sorted on their keys
+	name   string
+	source string //the source of the returned errors
+}
+
+//joinKeyValues returns the values of the key columns of a row
+func joinKeyValues(row []string, keys []int) []string {
+	values := make([]string, len(keys))
+
+	for i, key := range keys {
+		values[i] = rowValue(row, key)
+	}
+
+	return values
+}
+
+//compareKeyValues compares two lists of key values using the specified
+//comparison, where the first value is the most significant
+func compareKeyValues(a, b []string, compareType CompareType) int {
+	for i := range a {
+		if c := CompareValues(a[i], b[i], compareType, ""); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+//normalizeKeyValue converts a key value to a form in which values that are
+//equal according to the comparison are identical, such that they can be
+//hashed. Values that cannot be converted are compared as they are.
+func normalizeKeyValue(value string, compareType CompareType) string {
+	switch compareType {
+	case CompareNumeric:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			if f == 0 {
+				f = 0 //-0 equals 0
+			}
+
+			return formatFloat(f)
+		}
+	case CompareDate:
+		if t, ok := compareParseDate(value, ""); ok {
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+	case CompareNatural:
+		//remove the leading zeroes of the runs of digits
+		var b strings.Builder
+
+		for i := 0; i < len(value); i++ {
+			if isDigit(value[i]) && (i == 0 || !isDigit(value[i-1])) {
+				j := i
+
+				for j < len(value)-1 && value[j] == '0' && isDigit(value[j+1]) {
+					j++
+				}
+
+				i = j
+			}
+
+			b.WriteByte(value[i])
+		}
+
+		return b.String()
+	}
+
+	return value
+}
+
+//joinHashKey returns the hash table key of a list of key values, such that key
+//values that are equal according to the comparison share the same key
+func joinHashKey(values []string, compareType CompareType) string {
+	normalized := make([]string, len(values))
+
+	for i, value := range values {
+		normalized[i] = normalizeKeyValue(value, compareType)
+	}
+
+	return joinKey(normalized)
+}
+
+//next reads the next row of the input, returning an error if the input should
+//be sorted and the row has a smaller key than the previous row
+func (ji *joinInput) next(compareType CompareType) error {
+	row, err := ji.rows.ReadRow()
+
+	if err == io.EOF {
+		ji.row, ji.key = nil, nil
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	key := joinKeyValues(row, ji.keys)
+
+	if ji.sorted && ji.row != nil && compareKeyValues(key, ji.key, compareType) < 0 {
+		return Error{ErrorTypeInvalidArgument, ji.source, "Row " + strconv.Itoa(ji.count) + " of the " + ji.name + " input is not sorted on the key columns"}
+	}
+
+	ji.row, ji.key = row, key
+	ji.count++
+	return nil
+}
+
+//joinWriter builds the rows of the result of a join
+type joinWriter struct {
+	w       RowWriter
+	options JoinOptions
+	columns []JoinColumn
+}
+
+//newJoinWriter determines the columns of the result and writes the header if
+//the inputs have one. The first rows of both inputs are used to determine the
+//number of columns when no columns are specified.
+func newJoinWriter(w RowWriter, options JoinOptions, left, right []string) (*joinWriter, error) {
+	jw := &joinWriter{w, options, options.Columns}
+
+	if len(jw.columns) == 0 {
+		for col := range left {
+			jw.columns = append(jw.columns, JoinColumn{false, col})
+		}
+
+		if options.Type != JoinAnti {
+			for col := range right {
+				if !joinIsKey(col, options.RightKeys) {
+					jw.columns = append(jw.columns, JoinColumn{true, col})
+				}
+			}
+		}
+	}
+
+	for _, column := range jw.columns {
+		if column.Column < 0 {
+			return nil, Error{ErrorTypeInvalidArgument, "Join", "Negative output column specified"}
+		}
+	}
+
+	if !options.Header {
+		return jw, nil
+	}
+
+	//name the columns, adding suffixes to names that occur more than once
+	leftSuffix, rightSuffix := options.LeftSuffix, options.RightSuffix
+
+	if len(leftSuffix) == 0 && len(rightSuffix) == 0 {
+		leftSuffix, rightSuffix = joinLeftSuffix, joinRightSuffix
+	}
+
+	names := make([]string, len(jw.columns))
+	occurences := make(map[string]int)
+
+	for i, column := range jw.columns {
+		if column.Right {
+			names[i] = rowValue(right, column.Column)
+		} else {
+			names[i] = rowValue(left, column.Column)
+		}
+
+		occurences[names[i]]++
+	}
+
+	for i, column := range jw.columns {
+		if occurences[names[i]] < 2 {
+			continue
+		}
+
+		if column.Right {
+			names[i] += rightSuffix
+		} else {
+			names[i] += leftSuffix
+		}
+	}
+
+	return jw, w.WriteRow(names)
+}
+
+//joinIsKey checks if the column is one of the key columns
+func joinIsKey(col int, keys []int) bool {
+	for _, key := range keys {
+		if key == col {
+			return true
+		}
+	}
+
+	return false
+}
+
+//write writes the combination of a left and right row, either of which may be
+//nil if it has no match
+func (jw *joinWriter) write(left, right []string) error {
+	row := make([]string, len(jw.columns))
+
+	for i, column := range jw.columns {
+		source, other := left, right
+		keys, otherKeys := jw.options.LeftKeys, jw.options.RightKeys
+
+		if column.Right {
+			source, other = right, left
+			keys, otherKeys = otherKeys, keys
+		}
+
+		if source != nil {
+			row[i] = rowValue(source, column.Column)
+			continue
+		}
+
+		//fill the key columns of the missing row from the other row
+		for k, key := range keys {
+			if key == column.Column {
+				row[i] = rowValue(other, otherKeys[k])
+				break
+			}
+		}
+	}
+
+	return jw.w.WriteRow(row)
+}
+
+//JoinRows joins the rows read from the left and right readers on their key
+//columns and writes the result to the writer, see JoinOptions. The hash
+//strategy loads the right input into memory and keeps the order of the left
+//input, the rows of the right input without a match are written last. The
+//sort-merge strategy only holds the rows of the right input that share a single
+//key in memory, such that inputs of any size can be joined, and writes the
+//rows in the order of their keys. Within a key the rows are combined in their
+//original order.
+func JoinRows(left, right RowReader, w RowWriter, options JoinOptions) error {
+	if len(options.LeftKeys) == 0 || len(options.LeftKeys) != len(options.RightKeys) {
+		return Error{ErrorTypeInvalidArgument, "Join", "The same (non-zero) number of left and right key columns has to be specified"}
+	}
+
+	for i := range options.LeftKeys {
+		if options.LeftKeys[i] < 0 || options.RightKeys[i] < 0 {
+			return Error{ErrorTypeInvalidArgument, "Join", "Negative key column specified"}
+		}
+	}
+
+	sorted := options.Strategy == JoinSortMerge
+	leftInput := &joinInput{left, options.LeftKeys, nil, nil, 0, sorted, "left", "Join"}
+	rightInput := &joinInput{right, options.RightKeys, nil, nil, 0, sorted, "right", "Join"}
+
+	//read the headers, or the first rows to determine the number of columns
+	if err := leftInput.next(options.Compare); err != nil {
+		return err
+	}
+
+	if err := rightInput.next(options.Compare); err != nil {
+		return err
+	}
+
+	jw, err := newJoinWriter(w, options, leftInput.row, rightInput.row)
+
+	if err != nil {
+		return err
+	}
+
+	if options.Header {
+		leftInput.row, rightInput.row = nil, nil
+
+		if err = leftInput.next(options.Compare); err != nil {
+			return err
+		}
+
+		if err = rightInput.next(options.Compare); err != nil {
+			return err
+		}
+	}
+
+	if options.Strategy == JoinSortMerge {
+		return joinSortMerge(leftInput, rightInput, jw)
+	}
+
+	return joinHash(leftInput, rightInput, jw)
+}
+
+//joinHash implements the hash strategy of JoinRows(...)
+func joinHash(left, right *joinInput, jw *joinWriter) error {
+	//load the right input into a hash table
+	var rows [][]string
+	table := make(map[string][]int)
+
+	for right.row != nil {
+		key := joinHashKey(right.key, jw.options.Compare)
+		table[key] = append(table[key], len(rows))
+		rows = append(rows, right.row)
+
+		if err := right.next(jw.options.Compare); err != nil {
+			return err
+		}
+	}
+
+	matched := make([]bool, len(rows))
+	joinType := jw.options.Type
+
+	//stream the left input
+	for left.row != nil {
+		matches := table[joinHashKey(left.key, jw.options.Compare)]
+
+		if len(matches) == 0 {
+			if joinType == JoinLeft || joinType == JoinFull || joinType == JoinAnti {
+				if err := jw.write(left.row, nil); err != nil {
+					return err
+				}
+			}
+		} else if joinType != JoinAnti {
+			for _, match := range matches {
+				matched[match] = true
+
+				if err := jw.write(left.row, rows[match]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := left.next(jw.options.Compare); err != nil {
+			return err
+		}
+	}
+
+	if joinType != JoinRight && joinType != JoinFull {
+		return nil
+	}
+
+	for i, row := range rows {
+		if !matched[i] {
+			if err := jw.write(nil, row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//joinSortMerge implements the sort-merge strategy of JoinRows(...)
+func joinSortMerge(left, right *joinInput, jw *joinWriter) error {
+	joinType := jw.options.Type
+	compareType := jw.options.Compare
+	writeLeft := joinType == JoinLeft || joinType == JoinFull || joinType == JoinAnti
+	writeRight := joinType == JoinRight || joinType == JoinFull
+
+	for left.row != nil || right.row != nil {
+		var c int
+
+		switch {
+		case left.row == nil:
+			c = 1
+		case right.row == nil:
+			c = -1
+		default:
+			c = compareKeyValues(left.key, right.key, compareType)
+		}
+
+		//rows without a match
+		if c < 0 {
+			if writeLeft {
+				if err := jw.write(left.row, nil); err != nil {
+					return err
+				}
+			}
+
+			if err := left.next(compareType); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if c > 0 {
+			if writeRight {
+				if err := jw.write(nil, right.row); err != nil {
+					return err
+				}
+			}
+
+			if err := right.next(compareType); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		//collect all right rows sharing the key
+		key := right.key
+		var group [][]string
+
+		for right.row != nil && compareKeyValues(right.key, key, compareType) == 0 {
+			group = append(group, right.row)
+
+			if err := right.next(compareType); err != nil {
+				return err
+			}
+		}
+
+		//combine them with all left rows sharing the key
+		for left.row != nil && compareKeyValues(left.key, key, compareType) == 0 {
+			if joinType != JoinAnti {
+				for _, row := range group {
+					if err := jw.write(left.row, row); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := left.next(compareType); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//joinSorted returns a copy of the rows sorted on the key columns, leaving the
+//header in place
+func joinSorted(data [][]string, keys []int, header bool, compareType CompareType) [][]string {
+	data = append([][]string(nil), data...)
+	body := data
+
+	if header && len(body) != 0 {
+		body = body[1:]
+	}
+
+	sort.SliceStable(body, func(i, j int) bool {
+		return compareKeyValues(joinKeyValues(body[i], keys), joinKeyValues(body[j], keys), compareType) < 0
+	})
+
+	return data
+}
+
+//Join joins two spreadsheets on their key columns and returns the result as a
+//new SpreadsheetDelim instance, see JoinRows(...) and JoinOptions. When using
+//the sort-merge strategy the rows of both spreadsheets are sorted first, the
+//spreadsheets themselves are not changed. If the left spreadsheet is a
+//SpreadsheetDelimSheet its buffer size and delimeter are used for the result.
+func Join(left, right Spreadsheeter, options JoinOptions) (*SpreadsheetDelim, error) {
+	leftData, rightData := spreadsheetData(left), spreadsheetData(right)
+
+	if options.Strategy == JoinSortMerge {
+		leftData = joinSorted(leftData, options.LeftKeys, options.Header, options.Compare)
+		rightData = joinSorted(rightData, options.RightKeys, options.Header, options.Compare)
+	}
+
+	result := NewSpreadsheetDelim(0, ",")
+
+	if sheet, ok := left.(SpreadsheetDelimSheet); ok {
+		result = NewSpreadsheetDelim(sheet.buffer, sheet.delimeter)
+	}
+
+	result.Header = options.Header
+	err := JoinRows(&spreadsheetDelimRows{leftData, 0}, &spreadsheetDelimRows{rightData, 0}, result, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package fio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSpreadsheetDelimJoinSheets() (SpreadsheetDelimSheet, SpreadsheetDelimSheet) {
+	orders := NewSpreadsheetDelim(16, ",")
+	orders.Data = [][]string{
+		{"id", "customer", "amount"},
+		{"1", "ann", "10"},
+		{"2", "bob", "5"},
+		{"3", "dan", "7"},
+		{"4", "ann", "2"},
+	}
+
+	customers := NewSpreadsheetDelim(16, ",")
+	customers.Data = [][]string{
+		{"name", "city", "amount"},
+		{"bob", "Delft", "100"},
+		{"ann", "Leiden", "200"},
+		{"eve", "Gouda", "300"},
+		{"ann", "Breda", "400"},
+	}
+
+	return SpreadsheetDelimSheet{orders}, SpreadsheetDelimSheet{customers}
+}
+
+func TestJoin(t *testing.T) {
+	header := []string{"id", "customer", "amount_left", "city", "amount_right"}
+	tests := [...]struct {
+		joinType JoinType
+		expected [][]string
+	}{
+		{JoinInner, [][]string{
+			header,
+			{"1", "ann", "10", "Leiden", "200"},
+			{"1", "ann", "10", "Breda", "400"},
+			{"2", "bob", "5", "Delft", "100"},
+			{"4", "ann", "2", "Leiden", "200"},
+			{"4", "ann", "2", "Breda", "400"},
+		}},
+		{JoinLeft, [][]string{
+			header,
+			{"1", "ann", "10", "Leiden", "200"},
+			{"1", "ann", "10", "Breda", "400"},
+			{"2", "bob", "5", "Delft", "100"},
+			{"3", "dan", "7", "", ""},
+			{"4", "ann", "2", "Leiden", "200"},
+			{"4", "ann", "2", "Breda", "400"},
+		}},
+		{JoinRight, [][]string{
+			header,
+			{"1", "ann", "10", "Leiden", "200"},
+			{"1", "ann", "10", "Breda", "400"},
+			{"2", "bob", "5", "Delft", "100"},
+			{"4", "ann", "2", "Leiden", "200"},
+			{"4", "ann", "2", "Breda", "400"},
+			{"", "eve", "", "Gouda", "300"},
+		}},
+		{JoinFull, [][]string{
+			header,
+			{"1", "ann", "10", "Leiden", "200"},
+			{"1", "ann", "10", "Breda", "400"},
+			{"2", "bob", "5", "Delft", "100"},
+			{"3", "dan", "7", "", ""},
+			{"4", "ann", "2", "Leiden", "200"},
+			{"4", "ann", "2", "Breda", "400"},
+			{"", "eve", "", "Gouda", "300"},
+		}},
+		{JoinAnti, [][]string{
+			{"id", "customer", "amount"},
+			{"3", "dan", "7"},
+		}},
+	}
+
+	for _, test := range tests {
+		left, right := testSpreadsheetDelimJoinSheets()
+		options := JoinOptions{Type: test.joinType, LeftKeys: []int{1}, RightKeys: []int{0}, Header: true}
+		result, err := Join(left, right, options)
+
+		if err != nil {
+			t.Errorf("Join type %d: failed to join, error: %s\n", test.joinType, err.Error())
+		} else if !reflect.DeepEqual(result.Data, test.expected) {
+			t.Errorf("Join type %d: %q != %q\n", test.joinType, result.Data, test.expected)
+		}
+
+		//the sort-merge strategy should produce the same rows, ordered by key
+		options.Strategy = JoinSortMerge
+		result, err = Join(left, right, options)
+
+		if err != nil {
+			t.Errorf("Join type %d: failed to sort-merge join, error: %s\n", test.joinType, err.Error())
+		} else if !reflect.DeepEqual(testJoinSorted(result.Data), testJoinSorted(test.expected)) {
+			t.Errorf("Join type %d: sort-merge %q != %q\n", test.joinType, result.Data, test.expected)
+		}
+	}
+
+	//select the output columns and suffixes
+	left, right := testSpreadsheetDelimJoinSheets()
+	result, err := Join(left, right, JoinOptions{
+		Type:        JoinFull,
+		LeftKeys:    []int{1},
+		RightKeys:   []int{0},
+		Header:      true,
+		RightSuffix: ".c",
+		Columns:     []JoinColumn{{false, 1}, {true, 2}, {false, 2}},
+	})
+
+	expected := [][]string{
+		{"customer", "amount.c", "amount"},
+		{"ann", "200", "10"},
+		{"ann", "400", "10"},
+		{"bob", "100", "5"},
+		{"dan", "", "7"},
+		{"ann", "200", "2"},
+		{"ann", "400", "2"},
+		{"eve", "300", ""},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+
+	if _, err = Join(left, right, JoinOptions{LeftKeys: []int{1}}); err == nil {
+		t.Errorf("Expected joining with a different number of keys to fail\n")
+	}
+}
+
+func testJoinSorted(data [][]string) [][]string {
+	return joinSorted(data, []int{1, 0, 3}, true, CompareLexical)
+}
+
+func TestJoinRows(t *testing.T) {
+	left := NewSpreadsheetDelimReader(strings.NewReader("a;1\nb;2\nb;3\nd;4\n"), 8, ";")
+	right := NewSpreadsheetDelimReader(strings.NewReader("b;x\nc;y\nd;z\nd;w\n"), 8, ";")
+
+	var output strings.Builder
+	writer := NewSpreadsheetDelimWriter(&output, ";")
+	err := JoinRows(left, right, writer, JoinOptions{Type: JoinFull, Strategy: JoinSortMerge, LeftKeys: []int{0}, RightKeys: []int{0}})
+	writer.Flush()
+
+	expected := "a;1;\nb;2;x\nb;3;x\nc;;y\nd;4;z\nd;4;w\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected sort-merge result (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	//numerically equal keys match using both strategies
+	for _, strategy := range []JoinStrategy{JoinHash, JoinSortMerge} {
+		left = NewSpreadsheetDelimReader(strings.NewReader("1;a\n2.50;b\n"), 8, ";")
+		right = NewSpreadsheetDelimReader(strings.NewReader("1.0;x\n2.5;y\n"), 8, ";")
+		output.Reset()
+		writer = NewSpreadsheetDelimWriter(&output, ";")
+		err = JoinRows(left, right, writer, JoinOptions{Strategy: strategy, Compare: CompareNumeric, LeftKeys: []int{0}, RightKeys: []int{0}})
+		writer.Flush()
+
+		if expected = "1;a;x\n2.50;b;y\n"; err != nil || output.String() != expected {
+			t.Errorf("Unexpected numeric result (strategy %d, error: %v):\n%s\nexpected:\n%s\n", strategy, err, output.String(), expected)
+		}
+	}
+
+	//unsorted input should fail
+	left = NewSpreadsheetDelimReader(strings.NewReader("b;1\na;2\n"), 8, ";")
+	right = NewSpreadsheetDelimReader(strings.NewReader("a;x\n"), 8, ";")
+	err = JoinRows(left, right, NewSpreadsheetDelim(8, ";"), JoinOptions{Strategy: JoinSortMerge, LeftKeys: []int{0}, RightKeys: []int{0}})
+
+	if err == nil {
+		t.Errorf("Expected joining unsorted input to fail\n")
+	}
+}
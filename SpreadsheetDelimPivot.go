@@ -0,0 +1,183 @@
+package fio
+
+import (
+	"sort"
+	"strconv"
+)
+
+//The names of the columns created by Melt(...)
+const (
+	MeltVariableColumn = "variable"
+	MeltValueColumn    = "value"
+)
+
+//pivotSingle is the aggregation used by Pivot(...) when no aggregation is
+//specified, it fails if a combination occurs more than once
+func pivotSingle(column int) Aggregation {
+	return Aggregation{"", "value", column, func() Aggregator {
+		result, found := "", false
+		return aggregatorFunc{func(value string) error {
+			if found {
+				return Error{ErrorTypeInvalidArgument, "Pivot", "Multiple values for the same combination, an aggregation is required"}
+			}
+
+			result, found = value, true
+			return nil
+		}, func() string {
+			return result
+		}}
+	}}
+}
+
+//columnName returns the name of a column within the header, or its index if
+//the spreadsheet has no header
+func (sd *SpreadsheetDelim) columnName(col int) string {
+	if sd.Header && len(sd.Data) != 0 && col >= 0 && col < len(sd.Data[0]) {
+		return sd.Data[0][col]
+	}
+
+	return strconv.Itoa(col)
+}
+
+//Pivot converts the spreadsheet from long to wide form. The result contains one
+//row for every distinct combination of the values of the index columns, in the
+//order in which they are first encountered, and one column for every distinct
+//value of the columns column. The cells contain the values of the values column
+//for the combination, aggregated using the aggregation (such as AggregateSum(...)
+//or AggregateMax(...)) whose Column is replaced by values. If the aggregation is
+//the zero value every combination may only occur once. Combinations that do not
+//occur are filled with the fill value.
+//
+//The result starts with a header containing the names of the index columns
+//(or their indices if the spreadsheet has no header), followed by the distinct
+//values of the columns column in natural order, see CompareNatural.
+func (sd *SpreadsheetDelim) Pivot(index []int, columns, values int, aggregate Aggregation, fill string) (*SpreadsheetDelim, error) {
+	if len(index) == 0 {
+		return nil, Error{ErrorTypeInvalidArgument, "Pivot", "No index columns specified"}
+	}
+
+	for _, col := range append([]int{columns, values}, index...) {
+		if col < 0 {
+			return nil, Error{ErrorTypeInvalidArgument, "Pivot", "Negative column specified"}
+		}
+	}
+
+	if aggregate.New == nil {
+		aggregate = pivotSingle(values)
+	}
+
+	aggregate.Column = values
+
+	//aggregate the values of every combination of index and column values
+	keys := append(append([]int(nil), index...), columns)
+	grouped, err := sd.GroupBy(keys...).Aggregate(aggregate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//collect the rows and the new columns
+	var rows [][]string
+	rowIndices := make(map[string]int)
+	cells := make(map[string]string)
+	columnSet := make(map[string]bool)
+	var newColumns []string
+
+	for _, row := range grouped.body() {
+		rowKey := joinKey(row[:len(index)])
+		column := row[len(index)]
+
+		if _, ok := rowIndices[rowKey]; !ok {
+			rowIndices[rowKey] = len(rows)
+			rows = append(rows, row[:len(index)])
+		}
+
+		if !columnSet[column] {
+			columnSet[column] = true
+			newColumns = append(newColumns, column)
+		}
+
+		cells[joinKey([]string{rowKey, column})] = row[len(index)+1]
+	}
+
+	sort.SliceStable(newColumns, func(i, j int) bool {
+		return CompareValues(newColumns[i], newColumns[j], CompareNatural, "") < 0
+	})
+
+	//build the result
+	result := NewSpreadsheetDelim(sd.buffer, sd.delimeter)
+	result.Header = true
+	header := make([]string, 0, len(index)+len(newColumns))
+
+	for _, col := range index {
+		header = append(header, sd.columnName(col))
+	}
+
+	result.Data = append(result.Data, append(header, newColumns...))
+
+	for _, keyValues := range rows {
+		rowKey := joinKey(keyValues)
+		row := append(make([]string, 0, len(index)+len(newColumns)), keyValues...)
+
+		for _, column := range newColumns {
+			value, ok := cells[joinKey([]string{rowKey, column})]
+
+			if !ok {
+				value = fill
+			}
+
+			row = append(row, value)
+		}
+
+		result.Data = append(result.Data, row)
+	}
+
+	return result, nil
+}
+
+//Melt converts the spreadsheet from wide to long form. Every row is converted to
+//one row per value column, containing the values of the id columns followed by
+//the name of the value column and its value. If no value columns are specified
+//all columns that are not id columns are used. The rows are written in the
+//order of the original rows, and within a row in the order of the value
+//columns.
+//
+//The result starts with a header containing the names of the id columns (or
+//their indices if the spreadsheet has no header), followed by the
+//MeltVariableColumn and MeltValueColumn names. The variable names are taken
+//from the header in the same manner.
+func (sd *SpreadsheetDelim) Melt(idVars, valueVars []int) (*SpreadsheetDelim, error) {
+	for _, col := range append(append([]int(nil), idVars...), valueVars...) {
+		if col < 0 {
+			return nil, Error{ErrorTypeInvalidArgument, "Melt", "Negative column specified"}
+		}
+	}
+
+	if len(valueVars) == 0 {
+		for col := 0; col < sd.Width(); col++ {
+			if !joinIsKey(col, idVars) {
+				valueVars = append(valueVars, col)
+			}
+		}
+	}
+
+	result := NewSpreadsheetDelim(sd.buffer, sd.delimeter)
+	result.Header = true
+	header := make([]string, 0, len(idVars)+2)
+
+	for _, col := range idVars {
+		header = append(header, sd.columnName(col))
+	}
+
+	result.Data = append(result.Data, append(header, MeltVariableColumn, MeltValueColumn))
+
+	for _, row := range sd.body() {
+		ids := joinKeyValues(row, idVars)
+
+		for _, col := range valueVars {
+			result.Data = append(result.Data, append(append(make([]string, 0, len(ids)+2), ids...), sd.columnName(col), rowValue(row, col)))
+		}
+	}
+
+	return result, nil
+}
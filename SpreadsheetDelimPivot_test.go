@@ -0,0 +1,94 @@
+package fio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpreadsheetDelimPivot(t *testing.T) {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"region", "month", "amount"},
+		{"north", "m10", "1"},
+		{"south", "m2", "2"},
+		{"north", "m2", "3"},
+		{"north", "m10", "4"},
+	}
+
+	result, err := sd.Pivot([]int{0}, 1, 2, AggregateSum(2), "-")
+	expected := [][]string{
+		{"region", "m2", "m10"},
+		{"north", "3", "5"},
+		{"south", "2", "-"},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) || !result.Header {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+
+	//aggregations taking additional arguments can be used as well
+	result, err = sd.Pivot([]int{0}, 1, 2, AggregateMax(0, CompareNumeric), "")
+	expected = [][]string{
+		{"region", "m2", "m10"},
+		{"north", "3", "4"},
+		{"south", "2", ""},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+
+	//without an aggregation duplicate combinations should fail
+	if _, err = sd.Pivot([]int{0}, 1, 2, Aggregation{}, ""); err == nil {
+		t.Errorf("Expected pivoting duplicate combinations without aggregation to fail\n")
+	}
+
+	sd.Data = sd.Data[:4]
+	result, err = sd.Pivot([]int{0}, 1, 2, Aggregation{}, "")
+	expected = [][]string{
+		{"region", "m2", "m10"},
+		{"north", "3", "1"},
+		{"south", "2", ""},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+}
+
+func TestSpreadsheetDelimMelt(t *testing.T) {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"region", "jan", "feb"},
+		{"north", "1", "2"},
+		{"south", "3"},
+	}
+
+	result, err := sd.Melt([]int{0}, nil)
+	expected := [][]string{
+		{"region", "variable", "value"},
+		{"north", "jan", "1"},
+		{"north", "feb", "2"},
+		{"south", "jan", "3"},
+		{"south", "feb", ""},
+	}
+
+	if err != nil || !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", result.Data, expected, err)
+	}
+
+	//pivoting the melted table restores the values, with the value columns in
+	//natural order and missing values as empty cells
+	wide, err := result.Pivot([]int{0}, 1, 2, Aggregation{}, "")
+	expected = [][]string{
+		{"region", "feb", "jan"},
+		{"north", "2", "1"},
+		{"south", "", "3"},
+	}
+
+	if err != nil || !reflect.DeepEqual(wide.Data, expected) {
+		t.Errorf("%q != %q (error: %v)\n", wide.Data, expected, err)
+	}
+}
@@ -0,0 +1,380 @@
+package fio
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ColumnType is the type used to describe the type of the values within a
+//column of a spreadsheet
+type ColumnType byte
+
+//The various column types to use in conjunction with the ColumnType type
+const (
+	ColumnString ColumnType = iota //any value
+	ColumnInt                      //64-bit integers
+	ColumnFloat                    //floating point numbers
+	ColumnBool                     //true or false, case-insensitive
+	ColumnTime                     //dates and/or times, see SchemaColumn.Layout
+)
+
+//The strings used to describe the ColumnType value when it is printed
+const (
+	stringColumnString = "string"
+	stringColumnInt    = "int"
+	stringColumnFloat  = "float"
+	stringColumnBool   = "bool"
+	stringColumnTime   = "time"
+)
+
+func (ct ColumnType) String() string {
+	switch ct {
+	case ColumnString:
+		return stringColumnString
+	case ColumnInt:
+		return stringColumnInt
+	case ColumnFloat:
+		return stringColumnFloat
+	case ColumnBool:
+		return stringColumnBool
+	case ColumnTime:
+		return stringColumnTime
+	}
+
+	return "UNKNOWN"
+}
+
+//SchemaTimeLayouts contains the time layouts that are detected while inferring
+//a schema, in order of preference
+var SchemaTimeLayouts = []string{
+	CellDateTimeLayout,
+	CellDateLayout,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"02/01/2006",
+	"01/02/2006",
+	"02-01-2006",
+	"15:04:05",
+}
+
+//SchemaColumn describes a single column of a spreadsheet. Name is the name of
+//the column within the header, it is not checked if it is empty. Empty values
+//are only allowed if Nullable is true. Layout is the time layout used to parse
+//the values of ColumnTime columns, CellDateTimeLayout is used if it is empty.
+type SchemaColumn struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool
+	Layout   string
+}
+
+//parse checks if the value is of the type of the column, returning the parsed
+//value (int64, float64, bool, time.Time or string) if it is. Empty values are
+//returned as nil.
+func (sc SchemaColumn) parse(value string) (interface{}, error) {
+	if len(value) == 0 {
+		if !sc.Nullable {
+			return nil, Error{ErrorTypeParsing, "Schema", "Value is empty, but the column is not nullable"}
+		}
+
+		return nil, nil
+	}
+
+	var result interface{}
+	var err error
+
+	switch sc.Type {
+	case ColumnInt:
+		result, err = strconv.ParseInt(value, 10, 64)
+	case ColumnFloat:
+		result, err = strconv.ParseFloat(value, 64)
+	case ColumnBool:
+		switch {
+		case strings.EqualFold(value, "true"):
+			result = true
+		case strings.EqualFold(value, "false"):
+			result = false
+		default:
+			err = strconv.ErrSyntax
+		}
+	case ColumnTime:
+		layout := sc.Layout
+
+		if len(layout) == 0 {
+			layout = CellDateTimeLayout
+		}
+
+		result, err = time.Parse(layout, value)
+	default:
+		result = value
+	}
+
+	if err != nil {
+		return nil, Error{ErrorTypeParsing, "Schema", "Value '" + value + "' is not of type " + sc.Type.String()}
+	}
+
+	return result, nil
+}
+
+//Schema describes the columns of a spreadsheet. A schema is either inferred
+//from the values of a spreadsheet using InferSchema(...), or declared
+//explicitly.
+type Schema struct {
+	Columns []SchemaColumn
+}
+
+//schemaCandidate keeps track of the types a column can still have while
+//inferring a schema
+type schemaCandidate struct {
+	integer  bool
+	float    bool
+	boolean  bool
+	layouts  []string
+	nullable bool
+	values   bool //true once a non-empty value is encountered
+}
+
+//add removes the types the value does not fit
+func (sc *schemaCandidate) add(value string) {
+	if len(value) == 0 {
+		sc.nullable = true
+		return
+	}
+
+	sc.values = true
+	number := inferCellType(value) == CellTypeNumber
+
+	if sc.integer {
+		_, err := strconv.ParseInt(value, 10, 64)
+		sc.integer = number && err == nil
+	}
+
+	sc.float = sc.float && number
+	sc.boolean = sc.boolean && (strings.EqualFold(value, "true") || strings.EqualFold(value, "false"))
+	layouts := sc.layouts[:0]
+
+	for _, layout := range sc.layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			layouts = append(layouts, layout)
+		}
+	}
+
+	sc.layouts = layouts
+}
+
+//column returns the most specific type the column fits
+func (sc *schemaCandidate) column(name string) SchemaColumn {
+	column := SchemaColumn{name, ColumnString, sc.nullable, ""}
+
+	switch {
+	case !sc.values:
+		column.Nullable = true
+	case sc.integer:
+		column.Type = ColumnInt
+	case sc.float:
+		column.Type = ColumnFloat
+	case sc.boolean:
+		column.Type = ColumnBool
+	case len(sc.layouts) != 0:
+		column.Type, column.Layout = ColumnTime, sc.layouts[0]
+	}
+
+	return column
+}
+
+//InferSchema proposes a schema for the rows read from the reader, by checking
+//which types fit all values of a column. Integers are preferred over floats,
+//followed by booleans, times (using the first of the SchemaTimeLayouts that
+//fits all values) and strings. Numbers with meaningful leading zeroes (such as
+//zip codes) are strings. A column is nullable if it contains an empty value or
+//if a row is too short to contain it. If header is true the first row contains
+//the names of the columns. If sampleRows is larger than zero only that many
+//rows (excluding the header) are read.
+func InferSchema(r RowReader, header bool, sampleRows int) (Schema, error) {
+	var names []string
+	var candidates []*schemaCandidate
+
+	for count := 0; sampleRows <= 0 || count < sampleRows; {
+		row, err := r.ReadRow()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return Schema{}, err
+		}
+
+		if header && names == nil {
+			names = append([]string{}, row...)
+			continue
+		}
+
+		//columns that are added later are nullable, as earlier rows lack them
+		for len(candidates) < len(row) {
+			candidates = append(candidates, &schemaCandidate{true, true, true, append([]string(nil), SchemaTimeLayouts...), count != 0, false})
+		}
+
+		for i, candidate := range candidates {
+			if i < len(row) {
+				candidate.add(row[i])
+			} else {
+				candidate.nullable = true
+			}
+		}
+
+		count++
+	}
+
+	schema := Schema{}
+
+	for i := 0; i < len(candidates) || i < len(names); i++ {
+		if i >= len(candidates) {
+			schema.Columns = append(schema.Columns, SchemaColumn{names[i], ColumnString, true, ""})
+			continue
+		}
+
+		schema.Columns = append(schema.Columns, candidates[i].column(rowValue(names, i)))
+	}
+
+	return schema, nil
+}
+
+//InferSchema proposes a schema for the spreadsheet, see InferSchema(...). All
+//rows are scanned, if the spreadsheet has a header it is used to name the
+//columns.
+func (sd *SpreadsheetDelim) InferSchema() Schema {
+	schema, _ := InferSchema(sd.Rows(), sd.Header, 0)
+	return schema
+}
+
+//Validate checks if the spreadsheet matches the schema: if the spreadsheet has
+//a header the names of the columns have to match the (non-empty) names within
+//the schema, rows may not contain more columns than the schema, and every value
+//has to be of the type of its column. The returned error describes the first
+//mismatch.
+func (s Schema) Validate(sd *SpreadsheetDelim) error {
+	if sd.Header && len(sd.Data) != 0 {
+		for i, column := range s.Columns {
+			if name := rowValue(sd.Data[0], i); len(column.Name) != 0 && name != column.Name {
+				return Error{ErrorTypeParsing, "Schema", "Column " + strconv.Itoa(i) + " is named '" + name + "' instead of '" + column.Name + "'"}
+			}
+		}
+	}
+
+	offset := len(sd.Data) - len(sd.body())
+
+	for i, row := range sd.body() {
+		if len(row) > len(s.Columns) {
+			return Error{ErrorTypeParsing, "Schema", "Row " + strconv.Itoa(i+offset) + " contains more columns than the schema"}
+		}
+
+		for col, column := range s.Columns {
+			if _, err := column.parse(rowValue(row, col)); err != nil {
+				return Error{ErrorTypeParsing, "Schema", "Row " + strconv.Itoa(i+offset) + ", column '" + sd.columnName(col) + "': " + err.(Error).Message}
+			}
+		}
+	}
+
+	return nil
+}
+
+//typedColumn parses all values of a column (excluding the header) using the
+//column description, calling store for every non-empty value
+func (sd *SpreadsheetDelim) typedColumn(col int, column SchemaColumn, store func(row int, value interface{})) error {
+	if col < 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Negative column specified"}
+	}
+
+	column.Nullable = true
+	offset := len(sd.Data) - len(sd.body())
+
+	for i, row := range sd.body() {
+		value, err := column.parse(rowValue(row, col))
+
+		if err != nil {
+			return Error{ErrorTypeParsing, "SpreadsheetDelim", "Row " + strconv.Itoa(i+offset) + ", column '" + sd.columnName(col) + "': " + err.(Error).Message}
+		}
+
+		if value != nil {
+			store(i, value)
+		}
+	}
+
+	return nil
+}
+
+//Int64Column returns the values of the column (excluding the header) as
+//integers. Empty values are returned as zero, see Nulls(...). The function
+//returns an error if a value is not an integer.
+func (sd *SpreadsheetDelim) Int64Column(col int) ([]int64, error) {
+	values := make([]int64, len(sd.body()))
+	err := sd.typedColumn(col, SchemaColumn{Type: ColumnInt}, func(row int, value interface{}) {
+		values[row] = value.(int64)
+	})
+
+	return values, err
+}
+
+//Float64Column returns the values of the column (excluding the header) as
+//floats. Empty values are returned as zero, see Nulls(...). The function
+//returns an error if a value is not a number.
+func (sd *SpreadsheetDelim) Float64Column(col int) ([]float64, error) {
+	values := make([]float64, len(sd.body()))
+	err := sd.typedColumn(col, SchemaColumn{Type: ColumnFloat}, func(row int, value interface{}) {
+		values[row] = value.(float64)
+	})
+
+	return values, err
+}
+
+//BoolColumn returns the values of the column (excluding the header) as
+//booleans. Empty values are returned as false, see Nulls(...). The function
+//returns an error if a value is not true or false.
+func (sd *SpreadsheetDelim) BoolColumn(col int) ([]bool, error) {
+	values := make([]bool, len(sd.body()))
+	err := sd.typedColumn(col, SchemaColumn{Type: ColumnBool}, func(row int, value interface{}) {
+		values[row] = value.(bool)
+	})
+
+	return values, err
+}
+
+//TimeColumn returns the values of the column (excluding the header) as times,
+//parsed using the specified layout. Empty values are returned as the zero
+//time, see Nulls(...). The function returns an error if a value does not match
+//the layout.
+func (sd *SpreadsheetDelim) TimeColumn(col int, layout string) ([]time.Time, error) {
+	values := make([]time.Time, len(sd.body()))
+	err := sd.typedColumn(col, SchemaColumn{Type: ColumnTime, Layout: layout}, func(row int, value interface{}) {
+		values[row] = value.(time.Time)
+	})
+
+	return values, err
+}
+
+//StringColumn returns the values of the column (excluding the header)
+func (sd *SpreadsheetDelim) StringColumn(col int) []string {
+	values := make([]string, len(sd.body()))
+
+	for i, row := range sd.body() {
+		values[i] = rowValue(row, col)
+	}
+
+	return values
+}
+
+//Nulls returns for every row (excluding the header) if the value of the column
+//is empty, which is the case if the row is too short to contain the column
+func (sd *SpreadsheetDelim) Nulls(col int) []bool {
+	nulls := make([]bool, len(sd.body()))
+
+	for i, row := range sd.body() {
+		nulls[i] = len(rowValue(row, col)) == 0
+	}
+
+	return nulls
+}
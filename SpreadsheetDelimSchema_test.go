@@ -0,0 +1,127 @@
+package fio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSpreadsheetDelimSchemaSheet() *SpreadsheetDelim {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"id", "price", "active", "date", "zip", "note", "time"},
+		{"1", "2.5", "true", "31/12/2024", "01234", "", "2024-01-02 10:00:00"},
+		{"2", "3", "FALSE", "01/02/2025", "12345", "x", "2024-01-03 00:00:00"},
+		{"-3", "1e3", "true", "13/02/2025", "00001"},
+	}
+
+	return sd
+}
+
+func TestSpreadsheetDelimInferSchema(t *testing.T) {
+	schema := testSpreadsheetDelimSchemaSheet().InferSchema()
+	expected := Schema{[]SchemaColumn{
+		{"id", ColumnInt, false, ""},
+		{"price", ColumnFloat, false, ""},
+		{"active", ColumnBool, false, ""},
+		{"date", ColumnTime, false, "02/01/2006"},
+		{"zip", ColumnString, false, ""},
+		{"note", ColumnString, true, ""},
+		{"time", ColumnTime, true, CellDateTimeLayout},
+	}}
+
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("%v != %v\n", schema, expected)
+	}
+
+	//infer from a sample of a stream
+	reader := NewSpreadsheetDelimReader(strings.NewReader("a;b\n1;x\n2;y\nz;3\n"), 8, ";")
+	schema, err := InferSchema(reader, true, 2)
+	expected = Schema{[]SchemaColumn{{"a", ColumnInt, false, ""}, {"b", ColumnString, false, ""}}}
+
+	if err != nil || !reflect.DeepEqual(schema, expected) {
+		t.Errorf("%v != %v (error: %v)\n", schema, expected, err)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	sd := testSpreadsheetDelimSchemaSheet()
+	schema := sd.InferSchema()
+
+	if err := schema.Validate(sd); err != nil {
+		t.Errorf("Expected the inferred schema to validate, error: %s\n", err.Error())
+	}
+
+	failures := [...]func(sd *SpreadsheetDelim){
+		func(sd *SpreadsheetDelim) { sd.Data[0][1] = "cost" },
+		func(sd *SpreadsheetDelim) { sd.Data[1][0] = "1.5" },
+		func(sd *SpreadsheetDelim) { sd.Data[2][2] = "yes" },
+		func(sd *SpreadsheetDelim) { sd.Data[3][3] = "2025-02-13" },
+		func(sd *SpreadsheetDelim) { sd.Data[3][0] = "" },
+		func(sd *SpreadsheetDelim) { sd.Data[3] = append(sd.Data[3], "", "", "extra") },
+	}
+
+	for i, failure := range failures {
+		sd = testSpreadsheetDelimSchemaSheet()
+		failure(sd)
+
+		if err := schema.Validate(sd); err == nil {
+			t.Errorf("Failure %d: expected validation to fail\n", i)
+		}
+	}
+
+	//time columns without a layout use CellDateTimeLayout
+	sd = NewSpreadsheetDelim(16, ",")
+	sd.Data = [][]string{{"2024-01-02 03:04:05"}}
+	schema = Schema{[]SchemaColumn{{Type: ColumnTime}}}
+
+	if err := schema.Validate(sd); err != nil {
+		t.Errorf("Expected the time to be valid, error: %s\n", err.Error())
+	}
+
+	if sd.Data[0][0] = "2024-01-02"; schema.Validate(sd) == nil {
+		t.Errorf("Expected a date without time to be rejected\n")
+	}
+}
+
+func TestSpreadsheetDelimTypedColumns(t *testing.T) {
+	sd := testSpreadsheetDelimSchemaSheet()
+
+	ints, err := sd.Int64Column(0)
+
+	if err != nil || !reflect.DeepEqual(ints, []int64{1, 2, -3}) {
+		t.Errorf("Unexpected int column %v (error: %v)\n", ints, err)
+	}
+
+	floats, err := sd.Float64Column(1)
+
+	if err != nil || !reflect.DeepEqual(floats, []float64{2.5, 3, 1000}) {
+		t.Errorf("Unexpected float column %v (error: %v)\n", floats, err)
+	}
+
+	bools, err := sd.BoolColumn(2)
+
+	if err != nil || !reflect.DeepEqual(bools, []bool{true, false, true}) {
+		t.Errorf("Unexpected bool column %v (error: %v)\n", bools, err)
+	}
+
+	times, err := sd.TimeColumn(6, CellDateTimeLayout)
+
+	if err != nil || !times[0].Equal(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)) || !times[2].IsZero() {
+		t.Errorf("Unexpected time column %v (error: %v)\n", times, err)
+	}
+
+	if nulls := sd.Nulls(5); !reflect.DeepEqual(nulls, []bool{true, false, true}) {
+		t.Errorf("Unexpected nulls %v\n", nulls)
+	}
+
+	if strs := sd.StringColumn(4); !reflect.DeepEqual(strs, []string{"01234", "12345", "00001"}) {
+		t.Errorf("Unexpected string column %v\n", strs)
+	}
+
+	if _, err = sd.Int64Column(1); err == nil {
+		t.Errorf("Expected converting floats to integers to fail\n")
+	}
+}
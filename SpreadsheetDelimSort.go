@@ -0,0 +1,357 @@
+package fio
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//CompareType is the type used to specify how the values of a column are
+//compared while sorting
+type CompareType byte
+
+//The various comparisons to use in conjunction with the CompareType type
+const (
+	CompareLexical CompareType = iota //byte-wise string comparison
+	CompareNumeric                    //values are compared as floating point numbers
+	CompareDate                       //values are compared as dates, see SortKey.Layout
+	CompareNatural                    //strings are compared with embedded numbers compared by value ('a2' < 'a10')
+)
+
+//SortKey describes a single column to sort on. Values that cannot be converted
+//to the type indicated by Type (such as empty values when comparing numbers)
+//always sort after the values that can be converted, regardless of the
+//direction. Layout is the time layout used by CompareDate, if it is empty the
+//CellDateTimeLayout, CellDateLayout and time.RFC3339 layouts are tried.
+type SortKey struct {
+	Column     int
+	Type       CompareType
+	Descending bool
+	Layout     string
+}
+
+//compareParseDate converts a value to a time using the specified layout, or
+//using the default layouts if the layout is empty
+func compareParseDate(value, layout string) (time.Time, bool) {
+	if len(layout) != 0 {
+		t, err := time.Parse(layout, value)
+		return t, err == nil
+	}
+
+	for _, layout := range [...]string{CellDateTimeLayout, CellDateLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+//compareNatural compares two strings, comparing runs of digits by their
+//numeric value
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		ca, cb := a[i], b[j]
+
+		if isDigit(ca) && isDigit(cb) {
+			//find the runs of digits, ignoring leading zeroes
+			si, sj := i, j
+
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+
+			na := strings.TrimLeft(a[si:i], "0")
+			nb := strings.TrimLeft(b[sj:j], "0")
+
+			if len(na) != len(nb) {
+				return compareInt(len(na), len(nb))
+			}
+
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+
+			continue
+		}
+
+		if ca != cb {
+			return compareInt(int(ca), int(cb))
+		}
+
+		i++
+		j++
+	}
+
+	return compareInt(len(a)-i, len(b)-j)
+}
+
+//isDigit checks if a byte is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+//compareInt returns -1, 0 or 1 if a is smaller than, equal to or larger than b
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+//CompareValues compares two values using the specified comparison, returning
+//-1, 0 or 1 if a is smaller than, equal to or larger than b. Values that
+//cannot be converted are larger than all values that can be converted, and
+//are compared lexically amongst each other. The layout is only used by
+//CompareDate, see SortKey.
+func CompareValues(a, b string, compareType CompareType, layout string) int {
+	switch compareType {
+	case CompareNumeric:
+		fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+		fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+
+		switch {
+		case errA == nil && errB == nil:
+			if fa < fb {
+				return -1
+			} else if fa > fb {
+				return 1
+			}
+
+			return 0
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		}
+	case CompareDate:
+		ta, okA := compareParseDate(a, layout)
+		tb, okB := compareParseDate(b, layout)
+
+		switch {
+		case okA && okB:
+			return ta.Compare(tb)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+	case CompareNatural:
+		return compareNatural(a, b)
+	}
+
+	return strings.Compare(a, b)
+}
+
+//compareConvertible checks if a value can be converted to the type used by the
+//specified comparison
+func compareConvertible(value string, compareType CompareType, layout string) bool {
+	switch compareType {
+	case CompareNumeric:
+		return isNumeric(value)
+	case CompareDate:
+		_, ok := compareParseDate(value, layout)
+		return ok
+	}
+
+	return true
+}
+
+//rowValue returns the value at the specified column of a row, or an empty
+//value if the row does not contain the column
+func rowValue(row []string, col int) string {
+	if col >= 0 && col < len(row) {
+		return row[col]
+	}
+
+	return ""
+}
+
+//compareRows compares two rows on the specified keys
+func compareRows(a, b []string, keys []SortKey) int {
+	for _, key := range keys {
+		va, vb := rowValue(a, key.Column), rowValue(b, key.Column)
+		c := CompareValues(va, vb, key.Type, key.Layout)
+
+		if c == 0 {
+			continue
+		}
+
+		//values that can't be converted remain last when sorting descending
+		if key.Descending && compareConvertible(va, key.Type, key.Layout) == compareConvertible(vb, key.Type, key.Layout) {
+			return -c
+		}
+
+		return c
+	}
+
+	return 0
+}
+
+//Sort will sort the rows of the spreadsheet on the specified keys, where the
+//first key is the most significant. The sort is stable, so rows with equal keys
+//keep their order. If the spreadsheet has a header it remains the first row.
+//The function returns an error if no keys or a negative column are specified.
+func (sd *SpreadsheetDelim) Sort(keys ...SortKey) error {
+	if len(keys) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "No keys specified to sort on"}
+	}
+
+	for _, key := range keys {
+		if key.Column < 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetDelim", "Negative column specified to sort on"}
+		}
+	}
+
+	body := sd.body()
+	sort.SliceStable(body, func(i, j int) bool {
+		return compareRows(body[i], body[j], keys) < 0
+	})
+
+	return nil
+}
+
+//SpreadsheetDelimView is a view on a subset of the rows of a SpreadsheetDelim
+//instance, as returned by FilterView(...). The view refers to the rows of the
+//source, so setting a value through the view changes the source. Changing the
+//rows of the source (for instance by sorting it) invalidates the view.
+type SpreadsheetDelimView struct {
+	Source  *SpreadsheetDelim
+	Indices []int //the indices of the rows within the source, excluding the header
+}
+
+//Len returns the number of rows within the view, excluding the header
+func (sdv *SpreadsheetDelimView) Len() int {
+	return len(sdv.Indices)
+}
+
+//Get will retrieve a value from the location of the specified row and column
+//of the view, where row 0 is the first row after the header. In case the
+//location does not exist the function will return false.
+func (sdv *SpreadsheetDelimView) Get(row, col int) (string, bool) {
+	if row < 0 || row >= len(sdv.Indices) {
+		return "", false
+	}
+
+	return sdv.Source.Get(sdv.Indices[row], col)
+}
+
+//Set will set a value at the location of the specified row and column of the
+//view, changing the source. Intermediate columns will be created if the
+//specified column does not exist yet, but the row has to exist within the
+//view.
+func (sdv *SpreadsheetDelimView) Set(row, col int, value string) error {
+	if row < 0 || row >= len(sdv.Indices) {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetDelimView", "Row is not part of the view"}
+	}
+
+	return sdv.Source.Set(sdv.Indices[row], col, value)
+}
+
+//Rows returns a RowReader reading the header of the source (if it has one)
+//followed by the rows within the view
+func (sdv *SpreadsheetDelimView) Rows() RowReader {
+	var data [][]string
+
+	if sdv.Source.Header && len(sdv.Source.Data) != 0 {
+		data = append(data, sdv.Source.Data[0])
+	}
+
+	for _, index := range sdv.Indices {
+		data = append(data, sdv.Source.Data[index])
+	}
+
+	return &spreadsheetDelimRows{data, 0}
+}
+
+//Sheet returns a new SpreadsheetDelim instance containing a copy of the header
+//of the source and the rows within the view
+func (sdv *SpreadsheetDelimView) Sheet() *SpreadsheetDelim {
+	result := NewSpreadsheetDelim(sdv.Source.buffer, sdv.Source.delimeter)
+	result.Header = sdv.Source.Header
+	rows := sdv.Rows()
+
+	for row, err := rows.ReadRow(); err == nil; row, err = rows.ReadRow() {
+		result.WriteRow(row)
+	}
+
+	return result
+}
+
+//FilterView returns a view on the rows for which the predicate returns true.
+//The header row is not passed to the predicate.
+func (sd *SpreadsheetDelim) FilterView(predicate func(row []string) bool) *SpreadsheetDelimView {
+	view := &SpreadsheetDelimView{sd, nil}
+	offset := len(sd.Data) - len(sd.body())
+
+	for i, row := range sd.body() {
+		if predicate(row) {
+			view.Indices = append(view.Indices, i+offset)
+		}
+	}
+
+	return view
+}
+
+//Filter returns a new SpreadsheetDelim instance containing a copy of the header
+//and of the rows for which the predicate returns true. The header row is not
+//passed to the predicate.
+func (sd *SpreadsheetDelim) Filter(predicate func(row []string) bool) *SpreadsheetDelim {
+	return sd.FilterView(predicate).Sheet()
+}
+
+//Deduplicate will remove all rows of which the values in the specified columns
+//are equal to those of an earlier row, keeping the first occurence. If no
+//columns are specified the complete rows are compared. Missing values of ragged
+//rows are considered to be empty. The function returns the number of removed
+//rows.
+func (sd *SpreadsheetDelim) Deduplicate(columns ...int) int {
+	seen := make(map[string]bool)
+	body := sd.body()
+	kept := body[:0]
+
+	for _, row := range body {
+		var key string
+
+		if len(columns) == 0 {
+			//trailing empty values should not make rows different
+			last := len(row)
+
+			for last > 0 && len(row[last-1]) == 0 {
+				last--
+			}
+
+			key = joinKey(row[:last])
+		} else {
+			values := make([]string, len(columns))
+
+			for i, col := range columns {
+				values[i] = rowValue(row, col)
+			}
+
+			key = joinKey(values)
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		kept = append(kept, row)
+	}
+
+	removed := len(body) - len(kept)
+	sd.Data = sd.Data[:len(sd.Data)-removed]
+	return removed
+}
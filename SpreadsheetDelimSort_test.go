@@ -0,0 +1,126 @@
+package fio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testSpreadsheetDelimSortSheet() *SpreadsheetDelim {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"file", "size", "date"},
+		{"img10.png", "100", "2024-03-01"},
+		{"img2.png", "20", "2023-12-31"},
+		{"img1.png", "", "unknown"},
+		{"IMG3.png", "100", "2024-01-15 08:00:00"},
+		{"img2.png", "3.5"},
+	}
+
+	return sd
+}
+
+func testSpreadsheetDelimSortColumn(sd *SpreadsheetDelim, col int) []string {
+	var values []string
+
+	for _, row := range sd.Data {
+		values = append(values, rowValue(row, col))
+	}
+
+	return values
+}
+
+func TestSpreadsheetDelimSort(t *testing.T) {
+	tests := [...]struct {
+		keys     []SortKey
+		col      int
+		expected []string
+	}{
+		{[]SortKey{{0, CompareLexical, false, ""}}, 0, []string{"file", "IMG3.png", "img1.png", "img10.png", "img2.png", "img2.png"}},
+		{[]SortKey{{0, CompareNatural, false, ""}}, 0, []string{"file", "IMG3.png", "img1.png", "img2.png", "img2.png", "img10.png"}},
+		{[]SortKey{{1, CompareNumeric, false, ""}}, 1, []string{"size", "3.5", "20", "100", "100", ""}},
+		{[]SortKey{{1, CompareNumeric, true, ""}}, 1, []string{"size", "100", "100", "20", "3.5", ""}},
+		{[]SortKey{{1, CompareNumeric, true, ""}, {0, CompareLexical, false, ""}}, 0, []string{"file", "IMG3.png", "img10.png", "img2.png", "img2.png", "img1.png"}},
+		{[]SortKey{{2, CompareDate, true, ""}}, 2, []string{"date", "2024-03-01", "2024-01-15 08:00:00", "2023-12-31", "unknown", ""}},
+	}
+
+	for i, test := range tests {
+		sd := testSpreadsheetDelimSortSheet()
+		err := sd.Sort(test.keys...)
+
+		if err != nil {
+			t.Errorf("Test %d: failed to sort, error: %s\n", i, err.Error())
+		}
+
+		if values := testSpreadsheetDelimSortColumn(sd, test.col); !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("Test %d: %q != %q\n", i, values, test.expected)
+		}
+	}
+
+	//the sort should be stable
+	sd := testSpreadsheetDelimSortSheet()
+	sd.Sort(SortKey{0, CompareLexical, false, ""})
+
+	if sd.Data[4][1] != "20" || sd.Data[5][1] != "3.5" {
+		t.Errorf("Sorting is not stable\n")
+	}
+
+	if err := sd.Sort(); err == nil {
+		t.Errorf("Expected sorting without keys to fail\n")
+	}
+}
+
+func TestSpreadsheetDelimFilter(t *testing.T) {
+	sd := testSpreadsheetDelimSortSheet()
+	large := func(row []string) bool {
+		return isNumeric(rowValue(row, 1)) && CompareValues(rowValue(row, 1), "50", CompareNumeric, "") > 0
+	}
+
+	filtered := sd.Filter(large)
+	expected := [][]string{{"file", "size", "date"}, {"img10.png", "100", "2024-03-01"}, {"IMG3.png", "100", "2024-01-15 08:00:00"}}
+
+	if !reflect.DeepEqual(filtered.Data, expected) || !filtered.Header {
+		t.Errorf("Filter: %q != %q\n", filtered.Data, expected)
+	}
+
+	//changing the filtered sheet should not change the source
+	filtered.Set(1, 0, "changed")
+
+	if sd.Data[1][0] != "img10.png" {
+		t.Errorf("Filter did not copy the rows\n")
+	}
+
+	//changing the view should change the source
+	view := sd.FilterView(large)
+
+	if view.Len() != 2 {
+		t.Fatalf("View contains %d rows instead of 2\n", view.Len())
+	}
+
+	view.Set(1, 0, "changed")
+
+	if value, _ := view.Get(1, 0); value != "changed" || sd.Data[4][0] != "changed" {
+		t.Errorf("View did not change the source\n")
+	}
+}
+
+func TestSpreadsheetDelimDeduplicate(t *testing.T) {
+	sd := testSpreadsheetDelimSortSheet()
+
+	if removed := sd.Deduplicate(0); removed != 1 || len(sd.Data) != 5 || sd.Data[2][1] != "20" {
+		t.Errorf("Deduplicate on column 0 removed %d rows: %q\n", removed, sd.Data)
+	}
+
+	sd = testSpreadsheetDelimSortSheet()
+
+	if removed := sd.Deduplicate(1); removed != 1 || sd.Data[4][0] != "img2.png" {
+		t.Errorf("Deduplicate on column 1 removed %d rows: %q\n", removed, sd.Data)
+	}
+
+	//complete rows should ignore trailing empty values
+	sd.Data = [][]string{{"h"}, {"a", "b"}, {"a", "b", ""}, {"a"}}
+
+	if removed := sd.Deduplicate(); removed != 1 || len(sd.Data) != 3 {
+		t.Errorf("Deduplicate on complete rows removed %d rows: %q\n", removed, sd.Data)
+	}
+}
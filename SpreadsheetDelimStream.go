@@ -0,0 +1,139 @@
+package fio
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//SpreadsheetDelimReader reads the rows of a delimited spreadsheet one by one,
+//without holding the complete spreadsheet in memory. It implements the
+//RowReader interface. Empty lines are skipped, similar to
+//SpreadsheetDelim.Load(...). New instances should be created using the
+//NewSpreadsheetDelimReader(...) function.
+type SpreadsheetDelimReader struct {
+	reader    *bufio.Reader
+	buffer    []byte
+	delimeter string
+	eof       bool
+	Row       int //the number of lines read so far
+}
+
+//NewSpreadsheetDelimReader creates a new SpreadsheetDelimReader reading from
+//the specified reader and returns its pointer. The buffer size will grow to
+//the largest row in the file, the delimeter is used to seperate column values.
+func NewSpreadsheetDelimReader(r io.Reader, buffer int, delimeter string) *SpreadsheetDelimReader {
+	return &SpreadsheetDelimReader{bufio.NewReader(r), make([]byte, 0, buffer), delimeter, false, 0}
+}
+
+//ReadRow returns the values of the next non-empty row. After the last row has
+//been read the returned error will be io.EOF.
+func (sdr *SpreadsheetDelimReader) ReadRow() ([]string, error) {
+	for !sdr.eof {
+		//read a new line
+		var err error
+		sdr.buffer = sdr.buffer[:0]
+		sdr.eof, err = ReadBufferedLine(sdr.reader, &sdr.buffer)
+
+		if err != nil {
+			return nil, Error{ErrorTypeLoading, "SpreadsheetDelimReader", "Failed to read a new line"}
+		}
+
+		sdr.Row++
+
+		//check if the line contains any data at all
+		if len(sdr.buffer) == 0 {
+			continue
+		}
+
+		return strings.Split(string(sdr.buffer), sdr.delimeter), nil
+	}
+
+	return nil, io.EOF
+}
+
+//SpreadsheetDelimWriter writes the rows of a delimited spreadsheet one by one,
+//without holding the complete spreadsheet in memory. It implements the
+//RowWriter interface. The written data is buffered, so Flush() should be
+//called after the last row is written. New instances should be created using
+//the NewSpreadsheetDelimWriter(...) function.
+type SpreadsheetDelimWriter struct {
+	writer    *bufio.Writer
+	delimeter string
+	Row       int //the number of rows written so far
+}
+
+//NewSpreadsheetDelimWriter creates a new SpreadsheetDelimWriter writing to
+//the specified writer and returns its pointer. The delimeter is used to
+//seperate column values.
+func NewSpreadsheetDelimWriter(w io.Writer, delimeter string) *SpreadsheetDelimWriter {
+	return &SpreadsheetDelimWriter{bufio.NewWriter(w), delimeter, 0}
+}
+
+//WriteRow writes a single row. As the delimited format does not support any
+//form of quoting, values containing the delimeter or a newline character cause
+//an error to be returned.
+func (sdw *SpreadsheetDelimWriter) WriteRow(row []string) error {
+	for i, value := range row {
+		if (len(sdw.delimeter) != 0 && strings.Contains(value, sdw.delimeter)) || strings.ContainsAny(value, "\r\n") {
+			return Error{ErrorTypeSaving, "SpreadsheetDelimWriter", "Value at row " + strconv.Itoa(sdw.Row) + ", column " + strconv.Itoa(i) + " contains a delimeter or newline"}
+		}
+	}
+
+	for i, value := range row {
+		if i != 0 {
+			sdw.writer.WriteString(sdw.delimeter)
+		}
+
+		sdw.writer.WriteString(value)
+	}
+
+	//bufio.Writer remembers errors, so only the last write has to be checked
+	if err := sdw.writer.WriteByte('\n'); err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetDelimWriter", "Failed to write row " + strconv.Itoa(sdw.Row)}
+	}
+
+	sdw.Row++
+	return nil
+}
+
+//Flush writes any buffered data to the underlying writer
+func (sdw *SpreadsheetDelimWriter) Flush() error {
+	if err := sdw.writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetDelimWriter", "Failed to flush the buffered rows"}
+	}
+
+	return nil
+}
+
+//spreadsheetDelimRows implements the RowReader interface on top of the rows of
+//a loaded SpreadsheetDelim instance
+type spreadsheetDelimRows struct {
+	data [][]string
+	row  int
+}
+
+func (sdr *spreadsheetDelimRows) ReadRow() ([]string, error) {
+	if sdr.row >= len(sdr.data) {
+		return nil, io.EOF
+	}
+
+	sdr.row++
+	return sdr.data[sdr.row-1], nil
+}
+
+//Rows returns a RowReader reading the rows of the spreadsheet, such that the
+//functions operating on streams of rows can also be used on a loaded
+//spreadsheet. The returned rows are not copied, changing their values changes
+//the spreadsheet.
+func (sd *SpreadsheetDelim) Rows() RowReader {
+	return &spreadsheetDelimRows{sd.Data, 0}
+}
+
+//WriteRow appends a copy of the row to the spreadsheet, such that the
+//spreadsheet implements the RowWriter interface.
+func (sd *SpreadsheetDelim) WriteRow(row []string) error {
+	sd.Data = append(sd.Data, append([]string(nil), row...))
+	return nil
+}
@@ -0,0 +1,66 @@
+package fio
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSpreadsheetDelimReader(t *testing.T) {
+	reader := NewSpreadsheetDelimReader(strings.NewReader("a,b\n\nc,d"), 2, ",")
+	expected := [][]string{{"a", "b"}, {"c", "d"}}
+
+	for i, row := range expected {
+		values, err := reader.ReadRow()
+
+		if err != nil || strings.Join(values, "|") != strings.Join(row, "|") {
+			t.Errorf("Row %d: %q != %q (error: %v)\n", i, values, row, err)
+		}
+	}
+
+	if _, err := reader.ReadRow(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last row, got %v\n", err)
+	}
+}
+
+func TestSpreadsheetDelimWriter(t *testing.T) {
+	var output strings.Builder
+	writer := NewSpreadsheetDelimWriter(&output, ",")
+
+	if err := writer.WriteRow([]string{"a", "b"}); err != nil {
+		t.Errorf("Failed to write row, error: %s\n", err.Error())
+	}
+
+	if err := writer.WriteRow([]string{"a,b"}); err == nil {
+		t.Errorf("Expected a value containing the delimeter to fail\n")
+	}
+
+	writer.Flush()
+
+	if output.String() != "a,b\n" || writer.Row != 1 {
+		t.Errorf("Unexpected output %q after %d rows\n", output.String(), writer.Row)
+	}
+}
+
+func TestSpreadsheetDelimStreamJSONL(t *testing.T) {
+	input := `{"id":1,"tags":["x","y"],"points":[1,null,{"a":2}]}` + "\n" + `{"id":2,"tags":[]}` + "\n"
+
+	//arrays are JSON text by default, which cannot be written comma-delimited
+	var output strings.Builder
+	writer := NewSpreadsheetDelimWriter(&output, ",")
+
+	if err := ReadJSONL(strings.NewReader(input), writer, JSONLOptions{}); err == nil {
+		t.Errorf("Expected arrays containing commas to be rejected by the writer\n")
+	}
+
+	output.Reset()
+	writer = NewSpreadsheetDelimWriter(&output, ",")
+	err := ReadJSONL(strings.NewReader(input), writer, JSONLOptions{ArraySeparator: "|"})
+	writer.Flush()
+
+	expected := "id,tags,points\n1,x|y,1||{\"a\":2}\n2,,\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected rows (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+}
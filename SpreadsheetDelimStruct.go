@@ -0,0 +1,547 @@
+package fio
+
+import (
+	"encoding"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//structField describes a struct field that is mapped to a column
+type structField struct {
+	name   string //the name of the column
+	path   string //the name of the field, including the names of embedded structs
+	index  []int  //the index of the field for reflect.Value.FieldByIndex
+	column int    //the column specified using the index option, or -1
+	layout string //the time layout specified using the layout option
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+//structSupported checks if values of the type can be converted to and from
+//strings
+func structSupported(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType || reflect.PtrTo(t).Implements(textUnmarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+//parseStructTag splits a csv struct tag into the column name and its options.
+//As time layouts may contain commas, the layout option has to be the last
+//option and consumes the rest of the tag.
+func parseStructTag(tag string, field *structField) error {
+	parts := strings.Split(tag, ",")
+
+	if len(parts[0]) != 0 {
+		field.name = parts[0]
+	}
+
+	for i := 1; i < len(parts); i++ {
+		switch {
+		case strings.HasPrefix(parts[i], "index="):
+			column, err := strconv.Atoi(strings.TrimPrefix(parts[i], "index="))
+
+			if err != nil || column < 0 {
+				return Error{ErrorTypeInvalidArgument, "RowDecoder", "Invalid index option in tag of field " + field.path}
+			}
+
+			field.column = column
+		case strings.HasPrefix(parts[i], "layout="):
+			field.layout = strings.TrimPrefix(strings.Join(parts[i:], ","), "layout=")
+			return nil
+		default:
+			return Error{ErrorTypeInvalidArgument, "RowDecoder", "Unknown option '" + parts[i] + "' in tag of field " + field.path}
+		}
+	}
+
+	return nil
+}
+
+//structFields returns the fields of a struct type that are mapped to columns,
+//including the fields of embedded structs. Unexported fields and fields tagged
+//with `csv:"-"` are skipped.
+func structFields(t reflect.Type, index []int, prefix string) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, Error{ErrorTypeInvalidArgument, "RowDecoder", "Rows can only be mapped to structs"}
+	}
+
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("csv")
+
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		fieldType := f.Type
+
+		//flatten embedded structs without a column name
+		if f.Anonymous && (!tagged || strings.Split(tag, ",")[0] == "") {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct && !structSupported(fieldType) {
+				if f.Type.Kind() == reflect.Ptr && !f.IsExported() {
+					continue
+				}
+
+				embedded, err := structFields(fieldType, fieldIndex, prefix+f.Name+".")
+
+				if err != nil {
+					return nil, err
+				}
+
+				fields = append(fields, embedded...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		field := structField{f.Name, prefix + f.Name, fieldIndex, -1, ""}
+
+		if err := parseStructTag(tag, &field); err != nil {
+			return nil, err
+		}
+
+		if !structSupported(f.Type) {
+			return nil, Error{ErrorTypeInvalidArgument, "RowDecoder", "Field " + field.path + " has unsupported type " + f.Type.String()}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+//structFieldValue returns the value of the field, allocating nil embedded
+//structs if allocate is true. If allocate is false and an embedded struct is
+//nil, false is returned.
+func structFieldValue(v reflect.Value, index []int, allocate bool) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i != 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !allocate {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(fieldIndex)
+	}
+
+	return v, true
+}
+
+//structSetValue converts a string to the type of the value and stores it.
+//Empty strings result in nil pointers and zero values.
+func structSetValue(v reflect.Value, s, layout string) error {
+	if v.Kind() == reflect.Ptr {
+		if len(s) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if len(s) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		var t time.Time
+		var err error
+
+		if len(layout) != 0 {
+			t, err = time.Parse(layout, s)
+		} else if t, err = parseCellTime(s); err != nil {
+			t, err = time.Parse(time.RFC3339Nano, s)
+		}
+
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+
+		return err
+	}
+
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	}
+
+	return nil
+}
+
+//structFormatValue converts a value to a string. Nil pointers and zero times
+//result in empty strings.
+func structFormatValue(v reflect.Value, layout string) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+
+		switch {
+		case t.IsZero():
+			return "", nil
+		case len(layout) != 0:
+			return t.Format(layout), nil
+		}
+
+		return formatCellTime(t), nil
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	if v.CanAddr() {
+		if marshaler, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			text, err := marshaler.MarshalText()
+			return string(text), err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+
+	return "", Error{ErrorTypeInvalidArgument, "RowEncoder", "Unsupported type " + v.Type().String()}
+}
+
+//RowDecoder decodes the rows read from a RowReader into structs of type T, one
+//row at a time. The columns are mapped to the exported fields of T using
+//`csv:"name"` struct tags, fields without a tag use their own name and fields
+//tagged with `csv:"-"` are skipped. The fields of embedded structs are mapped
+//as if they were fields of T. The tag may contain the following options after
+//the name:
+//
+//	index=N     the field is mapped to column N, regardless of the header
+//	layout=...  the time layout of a time.Time field, has to be the last option
+//
+//If the rows have a header the columns are matched to the fields by name
+//(case-insensitive if no exact match exists), fields without a matching column
+//are left unchanged. Without a header the fields are mapped to the columns in
+//the order in which they are declared.
+//
+//Fields can be strings, booleans, integers, floats, time.Time values (without
+//a layout CellDateTimeLayout, CellDateLayout and time.RFC3339 are accepted),
+//types implementing encoding.TextUnmarshaler or pointers to any of these.
+//Empty values result in nil pointers and zero values. New instances should be
+//created using NewRowDecoder(...).
+type RowDecoder[T any] struct {
+	reader  RowReader
+	fields  []structField
+	columns []int //the column of every field, or -1
+	Row     int   //the number of rows read so far, including the header
+}
+
+//NewRowDecoder creates a new RowDecoder reading from the specified reader and
+//returns its pointer. If header is true the first row is read immediately to
+//map the columns to the fields.
+func NewRowDecoder[T any](r RowReader, header bool) (*RowDecoder[T], error) {
+	var zero T
+	fields, err := structFields(reflect.TypeOf(zero), nil, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := &RowDecoder[T]{r, fields, make([]int, len(fields)), 0}
+
+	for i, field := range fields {
+		decoder.columns[i] = i
+
+		if field.column >= 0 {
+			decoder.columns[i] = field.column
+		}
+	}
+
+	if !header {
+		return decoder, nil
+	}
+
+	names, err := r.ReadRow()
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	decoder.Row++
+
+	for i, field := range fields {
+		if field.column >= 0 {
+			continue
+		}
+
+		decoder.columns[i] = -1
+
+		for col, name := range names {
+			if name == field.name {
+				decoder.columns[i] = col
+				break
+			}
+
+			if decoder.columns[i] < 0 && strings.EqualFold(name, field.name) {
+				decoder.columns[i] = col
+			}
+		}
+	}
+
+	return decoder, nil
+}
+
+//Decode reads the next row and decodes it into a new value. After the last
+//row the returned error is io.EOF. Conversion errors name the row, column and
+//field.
+func (rd *RowDecoder[T]) Decode() (T, error) {
+	var result T
+	row, err := rd.reader.ReadRow()
+
+	if err != nil {
+		return result, err
+	}
+
+	rd.Row++
+	v := reflect.ValueOf(&result).Elem()
+
+	for i, field := range rd.fields {
+		col := rd.columns[i]
+
+		if col < 0 {
+			continue
+		}
+
+		fieldValue, _ := structFieldValue(v, field.index, true)
+
+		if err = structSetValue(fieldValue, rowValue(row, col), field.layout); err != nil {
+			return result, Error{ErrorTypeParsing, "RowDecoder", "Row " + strconv.Itoa(rd.Row-1) + ", column " + strconv.Itoa(col) + " ('" + field.name + "'), field " + field.path + ": cannot convert '" + rowValue(row, col) + "'"}
+		}
+	}
+
+	return result, nil
+}
+
+//DecodeRows decodes all rows read from the reader into structs of type T, see
+//RowDecoder
+func DecodeRows[T any](r RowReader, header bool) ([]T, error) {
+	decoder, err := NewRowDecoder[T](r, header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+
+	for {
+		value, err := decoder.Decode()
+
+		if err == io.EOF {
+			return result, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, value)
+	}
+}
+
+//RowEncoder encodes structs of type T into rows written to a RowWriter, one
+//struct at a time. The fields are mapped to columns in the same manner as
+//RowDecoder does without a header: in the order in which they are declared,
+//unless the index option specifies a column. New instances should be created
+//using NewRowEncoder(...).
+type RowEncoder[T any] struct {
+	writer  RowWriter
+	fields  []structField
+	columns []int
+	width   int
+	header  bool //true until the header is written
+}
+
+//NewRowEncoder creates a new RowEncoder writing to the specified writer and
+//returns its pointer. If header is true a header containing the column names
+//is written before the first row.
+func NewRowEncoder[T any](w RowWriter, header bool) (*RowEncoder[T], error) {
+	var zero T
+	fields, err := structFields(reflect.TypeOf(zero), nil, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := &RowEncoder[T]{w, fields, make([]int, len(fields)), 0, header}
+	used := make(map[int]bool)
+
+	for i, field := range fields {
+		encoder.columns[i] = i
+
+		if field.column >= 0 {
+			encoder.columns[i] = field.column
+		}
+
+		if used[encoder.columns[i]] {
+			return nil, Error{ErrorTypeInvalidArgument, "RowEncoder", "Multiple fields are mapped to column " + strconv.Itoa(encoder.columns[i])}
+		}
+
+		used[encoder.columns[i]] = true
+
+		if encoder.columns[i] >= encoder.width {
+			encoder.width = encoder.columns[i] + 1
+		}
+	}
+
+	return encoder, nil
+}
+
+//writeHeader writes the column names if no header is written yet
+func (re *RowEncoder[T]) writeHeader() error {
+	if !re.header {
+		return nil
+	}
+
+	re.header = false
+	names := make([]string, re.width)
+
+	for i, field := range re.fields {
+		names[re.columns[i]] = field.name
+	}
+
+	return re.writer.WriteRow(names)
+}
+
+//Encode converts the value to a row and writes it
+func (re *RowEncoder[T]) Encode(value T) error {
+	if err := re.writeHeader(); err != nil {
+		return err
+	}
+
+	row := make([]string, re.width)
+	v := reflect.ValueOf(&value).Elem()
+
+	for i, field := range re.fields {
+		fieldValue, ok := structFieldValue(v, field.index, false)
+
+		if !ok {
+			continue
+		}
+
+		s, err := structFormatValue(fieldValue, field.layout)
+
+		if err != nil {
+			return Error{ErrorTypeSaving, "RowEncoder", "Field " + field.path + ": " + err.Error()}
+		}
+
+		row[re.columns[i]] = s
+	}
+
+	return re.writer.WriteRow(row)
+}
+
+//EncodeRows encodes all values into rows written to the writer, see
+//RowEncoder. If header is true the header is written even if there are no
+//values.
+func EncodeRows[T any](w RowWriter, values []T, header bool) error {
+	encoder, err := NewRowEncoder[T](w, header)
+
+	if err != nil {
+		return err
+	}
+
+	if err = encoder.writeHeader(); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err = encoder.Encode(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package fio
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testStructLevel int
+
+func (l testStructLevel) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(l))), nil
+}
+
+func (l *testStructLevel) UnmarshalText(text []byte) error {
+	if strings.Trim(string(text), "*") != "" {
+		return Error{ErrorTypeParsing, "testStructLevel", "Invalid level"}
+	}
+
+	*l = testStructLevel(len(text))
+	return nil
+}
+
+type testStructBase struct {
+	ID int `csv:"id"`
+}
+
+type testStructRecord struct {
+	testStructBase
+	Name    string          `csv:"name"`
+	Price   *float64        `csv:"price"`
+	Date    time.Time       `csv:"date,layout=Jan 2, 2006"`
+	Level   testStructLevel `csv:"level"`
+	Active  bool
+	ignored string
+	Skip    string `csv:"-"`
+}
+
+func TestDecodeRows(t *testing.T) {
+	input := "Active;level;date;name;price;id\ntrue;**;Mar 4, 2024;ann;2.5;1\nfalse;;;bob;;2\n"
+	records, err := DecodeRows[testStructRecord](NewSpreadsheetDelimReader(strings.NewReader(input), 16, ";"), true)
+
+	if err != nil {
+		t.Fatalf("Failed to decode rows, error: %s\n", err.Error())
+	}
+
+	price := 2.5
+	expected := []testStructRecord{
+		{testStructBase{1}, "ann", &price, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), 2, true, "", ""},
+		{testStructBase{2}, "bob", nil, time.Time{}, 0, false, "", ""},
+	}
+
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("%+v != %+v\n", records, expected)
+	}
+
+	//without a header the fields are mapped in order of declaration
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Data = [][]string{{"3", "cat", "1", "Jan 1, 2000", "*", "TRUE"}}
+	records, err = DecodeRows[testStructRecord](sd.Rows(), false)
+
+	if err != nil || len(records) != 1 || records[0].ID != 3 || *records[0].Price != 1 || records[0].Level != 1 || !records[0].Active {
+		t.Errorf("Unexpected records %+v (error: %v)\n", records, err)
+	}
+}
+
+func TestRowDecoderErrors(t *testing.T) {
+	decoder, err := NewRowDecoder[testStructRecord](NewSpreadsheetDelimReader(strings.NewReader("id,price\n1,2\n2,x\n"), 16, ","), true)
+
+	if err != nil {
+		t.Fatalf("Failed to create decoder, error: %s\n", err.Error())
+	}
+
+	if record, err := decoder.Decode(); err != nil || record.ID != 1 || *record.Price != 2 {
+		t.Errorf("Unexpected record %+v (error: %v)\n", record, err)
+	}
+
+	_, err = decoder.Decode()
+
+	if err == nil || !strings.Contains(err.Error(), "Row 2") || !strings.Contains(err.Error(), "column 1") || !strings.Contains(err.Error(), "Price") {
+		t.Errorf("Expected an error naming row, column and field, got: %v\n", err)
+	}
+
+	if _, err = decoder.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got: %v\n", err)
+	}
+
+	type unsupported struct {
+		Values []int
+	}
+
+	if _, err = NewRowDecoder[unsupported](NewSpreadsheetDelim(16, ",").Rows(), false); err == nil {
+		t.Errorf("Expected an unsupported field type to fail\n")
+	}
+
+	if _, err = NewRowDecoder[int](NewSpreadsheetDelim(16, ",").Rows(), false); err == nil {
+		t.Errorf("Expected decoding into a non-struct to fail\n")
+	}
+}
+
+func TestEncodeRows(t *testing.T) {
+	price := 2.5
+	records := []testStructRecord{
+		{testStructBase{1}, "ann", &price, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), 2, true, "x", "y"},
+		{testStructBase{2}, "bob", nil, time.Time{}, 0, false, "", ""},
+	}
+
+	sd := NewSpreadsheetDelim(16, ",")
+
+	if err := EncodeRows(sd, records, true); err != nil {
+		t.Fatalf("Failed to encode rows, error: %s\n", err.Error())
+	}
+
+	expected := [][]string{
+		{"id", "name", "price", "date", "level", "Active"},
+		{"1", "ann", "2.5", "Mar 4, 2024", "**", "true"},
+		{"2", "bob", "", "", "", "false"},
+	}
+
+	if !reflect.DeepEqual(sd.Data, expected) {
+		t.Errorf("%q != %q\n", sd.Data, expected)
+	}
+
+	//decoding the encoded rows results in the original records
+	sd.Header = true
+	decoded, err := DecodeRows[testStructRecord](sd.Rows(), true)
+	records[0].ignored, records[0].Skip = "", ""
+
+	if err != nil || !reflect.DeepEqual(decoded, records) {
+		t.Errorf("%+v != %+v (error: %v)\n", decoded, records, err)
+	}
+
+	//explicit column indices
+	type indexed struct {
+		B string `csv:"b,index=2"`
+		A string `csv:"a,index=0"`
+	}
+
+	sd = NewSpreadsheetDelim(16, ",")
+
+	if err = EncodeRows(sd, []indexed{{"y", "x"}}, false); err != nil || !reflect.DeepEqual(sd.Data, [][]string{{"x", "", "y"}}) {
+		t.Errorf("Unexpected indexed rows %q (error: %v)\n", sd.Data, err)
+	}
+
+	type conflicting struct {
+		A string `csv:"a,index=1"`
+		B string
+	}
+
+	if _, err = NewRowEncoder[conflicting](sd, false); err == nil {
+		t.Errorf("Expected two fields mapped to the same column to fail\n")
+	}
+}
@@ -0,0 +1,161 @@
+package fio
+
+import (
+	"sync"
+)
+
+//SyncSpreadsheetDelim wraps a SpreadsheetDelim instance such that it can be
+//shared between goroutines. All methods are guarded by a read/write lock, the
+//wrapped instance should not be accessed directly after wrapping it. Readers
+//that need a consistent view of several values should use Read(...) or
+//Snapshot(), and related changes should be applied together using
+//Update(...). New instances should be created using NewSyncSpreadsheetDelim(...).
+type SyncSpreadsheetDelim struct {
+	lock  sync.RWMutex
+	sheet *SpreadsheetDelim
+}
+
+//NewSyncSpreadsheetDelim wraps the specified spreadsheet and returns the
+//pointer to the wrapper
+func NewSyncSpreadsheetDelim(sd *SpreadsheetDelim) *SyncSpreadsheetDelim {
+	return &SyncSpreadsheetDelim{sheet: sd}
+}
+
+//Load loads the file into a new SpreadsheetDelim instance (with the same
+//buffer size, delimeter and header setting) and replaces the wrapped
+//spreadsheet only if loading succeeds, see SpreadsheetDelim.Load(...). Unlike
+//SpreadsheetDelim.Load(...) the previous data is replaced rather than appended
+//to. Readers are not blocked while the file is read.
+func (s *SyncSpreadsheetDelim) Load(filename string, skipCols, skipRows int) error {
+	s.lock.RLock()
+	loaded := NewSpreadsheetDelim(s.sheet.buffer, s.sheet.delimeter)
+	loaded.Filename, loaded.Header = s.sheet.Filename, s.sheet.Header
+	s.lock.RUnlock()
+
+	if err := loaded.Load(filename, skipCols, skipRows); err != nil {
+		return err
+	}
+
+	s.Replace(loaded)
+	return nil
+}
+
+//Save stores the spreadsheet to a file, see SpreadsheetDelim.Save(...)
+func (s *SyncSpreadsheetDelim) Save(filename string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.Save(filename)
+}
+
+//Set see SpreadsheetDelim.Set(...)
+func (s *SyncSpreadsheetDelim) Set(row, col int, value string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sheet.Set(row, col, value)
+}
+
+//Get see SpreadsheetDelim.Get(...)
+func (s *SyncSpreadsheetDelim) Get(row, col int) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.Get(row, col)
+}
+
+//GetInt see SpreadsheetDelim.GetInt(...)
+func (s *SyncSpreadsheetDelim) GetInt(row, col int) (int, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.GetInt(row, col)
+}
+
+//GetUint see SpreadsheetDelim.GetUint(...)
+func (s *SyncSpreadsheetDelim) GetUint(row, col int) (uint, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.GetUint(row, col)
+}
+
+//GetFloat32 see SpreadsheetDelim.GetFloat32(...)
+func (s *SyncSpreadsheetDelim) GetFloat32(row, col int) (float32, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.GetFloat32(row, col)
+}
+
+//GetFloat64 see SpreadsheetDelim.GetFloat64(...)
+func (s *SyncSpreadsheetDelim) GetFloat64(row, col int) (float64, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.GetFloat64(row, col)
+}
+
+//WriteRow appends a row to the spreadsheet, see SpreadsheetDelim.WriteRow(...)
+func (s *SyncSpreadsheetDelim) WriteRow(row []string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sheet.WriteRow(row)
+}
+
+//Len returns the number of rows within the spreadsheet, including the header
+func (s *SyncSpreadsheetDelim) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.sheet.Data)
+}
+
+//Snapshot returns a deep copy of the spreadsheet, which is not affected by
+//later changes
+func (s *SyncSpreadsheetDelim) Snapshot() *SpreadsheetDelim {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.sheet.Copy()
+}
+
+//Read calls the function with the wrapped spreadsheet while holding the read
+//lock, such that all values read by the function are consistent. The function
+//should not change the spreadsheet or keep a reference to it.
+func (s *SyncSpreadsheetDelim) Read(read func(sd *SpreadsheetDelim)) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	read(s.sheet)
+}
+
+//Update applies a batch of changes atomically. The function is called with a
+//copy of the spreadsheet while holding the write lock, if it returns nil the
+//copy replaces the wrapped spreadsheet. If it returns an error the changes are
+//discarded and the error is returned. Other goroutines either see all or none
+//of the changes.
+func (s *SyncSpreadsheetDelim) Update(update func(sd *SpreadsheetDelim) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	updated := s.sheet.Copy()
+
+	if err := update(updated); err != nil {
+		return err
+	}
+
+	s.sheet = updated
+	return nil
+}
+
+//Replace replaces the wrapped spreadsheet and returns the previous one
+func (s *SyncSpreadsheetDelim) Replace(sd *SpreadsheetDelim) *SpreadsheetDelim {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	previous := s.sheet
+	s.sheet = sd
+	return previous
+}
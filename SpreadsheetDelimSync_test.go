@@ -0,0 +1,72 @@
+package fio
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncSpreadsheetDelim(t *testing.T) {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{{"from", "to"}}
+	s := NewSyncSpreadsheetDelim(sd)
+
+	var wait sync.WaitGroup
+
+	//every batch appends two rows, readers should always see an odd row count
+	for i := 0; i < 4; i++ {
+		wait.Add(2)
+
+		go func(i int) {
+			defer wait.Done()
+
+			for j := 0; j < 50; j++ {
+				value := strconv.Itoa(i*100 + j)
+				s.Update(func(sd *SpreadsheetDelim) error {
+					sd.WriteRow([]string{value, "x"})
+					return sd.WriteRow([]string{"x", value})
+				})
+			}
+		}(i)
+
+		go func() {
+			defer wait.Done()
+
+			for j := 0; j < 50; j++ {
+				if count := len(s.Snapshot().Data); count%2 != 1 {
+					t.Errorf("Snapshot contains a half-applied batch of %d rows\n", count)
+				}
+
+				s.Read(func(sd *SpreadsheetDelim) {
+					if len(sd.Data)%2 != 1 {
+						t.Errorf("Read a half-applied batch of %d rows\n", len(sd.Data))
+					}
+				})
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	if count := s.Len(); count != 401 {
+		t.Errorf("Expected 401 rows, got %d\n", count)
+	}
+
+	err := s.Update(func(sd *SpreadsheetDelim) error {
+		sd.Set(1, 0, "changed")
+		return Error{ErrorTypeInvalidArgument, "Test", "Batch failed"}
+	})
+
+	if value, _ := s.Get(1, 0); err == nil || value == "changed" {
+		t.Errorf("Expected the failed batch to be discarded (error: %v)\n", err)
+	}
+
+	snapshot := s.Snapshot()
+	s.Set(0, 0, "source")
+
+	if !reflect.DeepEqual(snapshot.Data[0], []string{"from", "to"}) {
+		t.Errorf("Expected the snapshot to be unaffected, got %q\n", snapshot.Data[0])
+	}
+}
@@ -0,0 +1,347 @@
+package fio
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//ColumnRule describes a rule the values of a single column have to adhere to.
+//New creates the function checking the values for a single validation, such
+//that rules keeping track of earlier values (such as RuleUnique) can be reused.
+//The check function returns an empty string if the value is valid and a
+//description of the violation otherwise. User-defined rules only need to
+//provide a Name and New.
+type ColumnRule struct {
+	Name string
+	New  func() func(value string) string
+}
+
+//RowRule describes a rule spanning multiple columns of a row. Columns are the
+//columns the violations are reported for, Check returns an empty string if the
+//row is valid and a description of the violation otherwise.
+type RowRule struct {
+	Name    string
+	Columns []int
+	Check   func(row []string) string
+}
+
+//ruleStateless creates a ColumnRule from a check function that does not keep
+//track of earlier values. Empty values are skipped, see RuleRequired.
+func ruleStateless(name string, check func(value string) string) ColumnRule {
+	return ColumnRule{name, func() func(value string) string {
+		return func(value string) string {
+			if len(value) == 0 {
+				return ""
+			}
+
+			return check(value)
+		}
+	}}
+}
+
+//RuleRequired rejects empty values. All other rules accept empty values, such
+//that optional columns can be validated.
+func RuleRequired() ColumnRule {
+	return ColumnRule{"required", func() func(value string) string {
+		return func(value string) string {
+			if len(strings.TrimSpace(value)) == 0 {
+				return "Value is required"
+			}
+
+			return ""
+		}
+	}}
+}
+
+//RuleRegex rejects values that do not completely match the regular expression
+func RuleRegex(expression *regexp.Regexp) ColumnRule {
+	complete := regexp.MustCompile("^(?:" + expression.String() + ")$")
+
+	return ruleStateless("regex", func(value string) string {
+		if !complete.MatchString(value) {
+			return "Value does not match '" + expression.String() + "'"
+		}
+
+		return ""
+	})
+}
+
+//RuleRange rejects values that are not numbers between minimum and maximum
+//(inclusive)
+func RuleRange(minimum, maximum float64) ColumnRule {
+	return ruleStateless("range", func(value string) string {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+
+		switch {
+		case err != nil:
+			return "Value is not a number"
+		case f < minimum || f > maximum:
+			return "Value is not between " + formatFloat(minimum) + " and " + formatFloat(maximum)
+		}
+
+		return ""
+	})
+}
+
+//RuleEnum rejects values that are not one of the specified values
+func RuleEnum(values ...string) ColumnRule {
+	allowed := make(map[string]bool)
+
+	for _, value := range values {
+		allowed[value] = true
+	}
+
+	return ruleStateless("enum", func(value string) string {
+		if !allowed[value] {
+			return "Value is not one of '" + strings.Join(values, "', '") + "'"
+		}
+
+		return ""
+	})
+}
+
+//RuleMaxLength rejects values consisting of more than length characters
+func RuleMaxLength(length int) ColumnRule {
+	return ruleStateless("max length", func(value string) string {
+		if utf8.RuneCountInString(value) > length {
+			return "Value is longer than " + strconv.Itoa(length) + " characters"
+		}
+
+		return ""
+	})
+}
+
+//RuleUnique rejects values that occur in an earlier row
+func RuleUnique() ColumnRule {
+	return ColumnRule{"unique", func() func(value string) string {
+		seen := make(map[string]bool)
+
+		return func(value string) string {
+			switch {
+			case len(value) == 0:
+				return ""
+			case seen[value]:
+				return "Value is not unique"
+			}
+
+			seen[value] = true
+			return ""
+		}
+	}}
+}
+
+//RuleForeignKey rejects values that do not occur in the specified column of
+//another spreadsheet (excluding its header). The values of the other
+//spreadsheet are collected once per validation.
+func RuleForeignKey(sheet *SpreadsheetDelim, column int) ColumnRule {
+	return ColumnRule{"foreign key", func() func(value string) string {
+		keys := make(map[string]bool)
+
+		for _, row := range sheet.body() {
+			keys[rowValue(row, column)] = true
+		}
+
+		return func(value string) string {
+			if len(value) != 0 && !keys[value] {
+				return "Value does not exist in the referenced sheet"
+			}
+
+			return ""
+		}
+	}}
+}
+
+//RuleCompare is a RowRule requiring the value of the left column to relate to
+//the value of the right column according to the operator (one of '<', '<=',
+//'=', '!=', '>=' or '>'), such as an end date not preceding a start date. The
+//values are compared using CompareValues(...), rows in which either value is
+//empty are skipped.
+func RuleCompare(left int, operator string, right int, compareType CompareType, layout string) RowRule {
+	accept := map[string]func(c int) bool{
+		"<":  func(c int) bool { return c < 0 },
+		"<=": func(c int) bool { return c <= 0 },
+		"=":  func(c int) bool { return c == 0 },
+		"!=": func(c int) bool { return c != 0 },
+		">=": func(c int) bool { return c >= 0 },
+		">":  func(c int) bool { return c > 0 },
+	}[operator]
+
+	return RowRule{"compare", []int{left, right}, func(row []string) string {
+		a, b := rowValue(row, left), rowValue(row, right)
+
+		switch {
+		case len(a) == 0 || len(b) == 0:
+			return ""
+		case accept == nil:
+			return "Unknown operator '" + operator + "'"
+		case !compareConvertible(a, compareType, layout) || !compareConvertible(b, compareType, layout):
+			return "Values '" + a + "' and '" + b + "' cannot be compared"
+		case !accept(CompareValues(a, b, compareType, layout)):
+			return "Value '" + a + "' is not " + operator + " '" + b + "'"
+		}
+
+		return ""
+	}}
+}
+
+//Violation describes a single value (or row) that does not adhere to a rule.
+//Row is the index of the row within the spreadsheet (the header being row 0 if
+//the spreadsheet has one), ColumnName is the name of the column within the
+//header or the index of the column if there is no header.
+type Violation struct {
+	Row        int
+	Column     int
+	ColumnName string
+	Rule       string
+	Value      string
+	Message    string
+}
+
+//ValidationReport lists all violations found by Validator.Validate(...), in
+//order of row and rule
+type ValidationReport struct {
+	Violations []Violation
+}
+
+//Valid returns true if no violations were found
+func (vr ValidationReport) Valid() bool {
+	return len(vr.Violations) == 0
+}
+
+//RejectedRows returns the indices of the rows containing at least one
+//violation, in ascending order
+func (vr ValidationReport) RejectedRows() []int {
+	var rows []int
+
+	for _, violation := range vr.Violations {
+		if len(rows) == 0 || rows[len(rows)-1] != violation.Row {
+			rows = append(rows, violation.Row)
+		}
+	}
+
+	return rows
+}
+
+//Sheet returns the violations as a new SpreadsheetDelim instance with a header
+//(row, column, name, rule, value and message), such that the report can be
+//saved using Save(...). As the delimited format does not support quoting, the
+//delimeter and newline characters within the values are replaced by spaces.
+func (vr ValidationReport) Sheet(buffer int, delimeter string) *SpreadsheetDelim {
+	result := NewSpreadsheetDelim(buffer, delimeter)
+	result.Header = true
+	result.WriteRow([]string{"row", "column", "name", "rule", "value", "message"})
+
+	replacements := []string{"\r\n", " ", "\n", " ", "\r", " "}
+
+	if len(delimeter) != 0 {
+		replacements = append([]string{delimeter, " "}, replacements...)
+	}
+
+	replacer := strings.NewReplacer(replacements...)
+
+	for _, violation := range vr.Violations {
+		row := []string{strconv.Itoa(violation.Row), strconv.Itoa(violation.Column), violation.ColumnName, violation.Rule, violation.Value, violation.Message}
+
+		for i := range row {
+			row[i] = replacer.Replace(row[i])
+		}
+
+		result.WriteRow(row)
+	}
+
+	return result
+}
+
+//Validator validates the rows of a spreadsheet using column and row rules.
+//New instances should be created using NewValidator(), after which the rules
+//are added using AddRule(...) and AddRowRule(...).
+type Validator struct {
+	columns []int
+	rules   []ColumnRule
+	rows    []RowRule
+}
+
+//NewValidator creates a new Validator without any rules and returns its
+//pointer
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+//AddRule adds rules for the values of the specified column. The validator is
+//returned such that calls can be chained.
+func (v *Validator) AddRule(column int, rules ...ColumnRule) *Validator {
+	for _, rule := range rules {
+		v.columns = append(v.columns, column)
+		v.rules = append(v.rules, rule)
+	}
+
+	return v
+}
+
+//AddRowRule adds rules spanning multiple columns. The validator is returned
+//such that calls can be chained.
+func (v *Validator) AddRowRule(rules ...RowRule) *Validator {
+	v.rows = append(v.rows, rules...)
+	return v
+}
+
+//Validate checks every row of the spreadsheet (excluding the header) against
+//all rules, reporting every violation instead of stopping at the first one
+func (v *Validator) Validate(sd *SpreadsheetDelim) ValidationReport {
+	checks := make([]func(value string) string, len(v.rules))
+
+	for i, rule := range v.rules {
+		checks[i] = rule.New()
+	}
+
+	report := ValidationReport{}
+	offset := len(sd.Data) - len(sd.body())
+
+	for i, row := range sd.body() {
+		for j, check := range checks {
+			value := rowValue(row, v.columns[j])
+
+			if message := check(value); len(message) != 0 {
+				report.Violations = append(report.Violations, Violation{i + offset, v.columns[j], sd.columnName(v.columns[j]), v.rules[j].Name, value, message})
+			}
+		}
+
+		for _, rule := range v.rows {
+			message := rule.Check(row)
+
+			if len(message) == 0 {
+				continue
+			}
+
+			for _, column := range rule.Columns {
+				report.Violations = append(report.Violations, Violation{i + offset, column, sd.columnName(column), rule.Name, rowValue(row, column), message})
+			}
+		}
+	}
+
+	return report
+}
+
+//Split validates the spreadsheet and returns two new SpreadsheetDelim
+//instances, the first containing the valid rows and the second containing the
+//rejected rows. Both contain a copy of the header, the report describes why
+//rows were rejected.
+func (v *Validator) Split(sd *SpreadsheetDelim) (*SpreadsheetDelim, *SpreadsheetDelim, ValidationReport) {
+	report := v.Validate(sd)
+	valid := &SpreadsheetDelimView{sd, nil}
+	rejected := &SpreadsheetDelimView{sd, report.RejectedRows()}
+	offset := len(sd.Data) - len(sd.body())
+
+	for i, index := 0, 0; i < len(sd.body()); i++ {
+		if index < len(rejected.Indices) && rejected.Indices[index] == i+offset {
+			index++
+			continue
+		}
+
+		valid.Indices = append(valid.Indices, i+offset)
+	}
+
+	return valid.Sheet(), rejected.Sheet(), report
+}
@@ -0,0 +1,138 @@
+package fio
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func testValidator() (*Validator, *SpreadsheetDelim) {
+	countries := NewSpreadsheetDelim(16, ",")
+	countries.Header = true
+	countries.Data = [][]string{{"code"}, {"NL"}, {"BE"}}
+
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{
+		{"id", "email", "age", "status", "country", "start", "end"},
+		{"1", "ann@example.com", "34", "active", "NL", "2024-01-01", "2024-02-01"},
+		{"2", "bob", "17", "active", "DE", "2024-01-01", "2023-12-31"},
+		{"1", "", "x", "gone", "", "2024-01-01"},
+		{"3", "cat@example.com", "50", "inactive", "BE", "2024-01-01", "2024-01-01"},
+	}
+
+	validator := NewValidator().
+		AddRule(0, RuleRequired(), RuleUnique()).
+		AddRule(1, RuleRequired(), RuleRegex(regexp.MustCompile(`[^@]+@[^@]+`)), RuleMaxLength(15)).
+		AddRule(2, RuleRange(18, 120)).
+		AddRule(3, RuleEnum("active", "inactive")).
+		AddRule(4, RuleForeignKey(countries, 0)).
+		AddRowRule(RuleCompare(6, ">=", 5, CompareDate, "2006-01-02"))
+
+	return validator, sd
+}
+
+func TestValidatorValidate(t *testing.T) {
+	validator, sd := testValidator()
+	report := validator.Validate(sd)
+
+	expected := []Violation{
+		{2, 1, "email", "regex", "bob", "Value does not match '[^@]+@[^@]+'"},
+		{2, 2, "age", "range", "17", "Value is not between 18 and 120"},
+		{2, 4, "country", "foreign key", "DE", "Value does not exist in the referenced sheet"},
+		{2, 6, "end", "compare", "2023-12-31", "Value '2023-12-31' is not >= '2024-01-01'"},
+		{2, 5, "start", "compare", "2024-01-01", "Value '2023-12-31' is not >= '2024-01-01'"},
+		{3, 0, "id", "unique", "1", "Value is not unique"},
+		{3, 1, "email", "required", "", "Value is required"},
+		{3, 2, "age", "range", "x", "Value is not a number"},
+		{3, 3, "status", "enum", "gone", "Value is not one of 'active', 'inactive'"},
+	}
+
+	if !reflect.DeepEqual(report.Violations, expected) {
+		t.Errorf("%v != %v\n", report.Violations, expected)
+	}
+
+	if report.Valid() || !reflect.DeepEqual(report.RejectedRows(), []int{2, 3}) {
+		t.Errorf("Unexpected rejected rows %v\n", report.RejectedRows())
+	}
+
+	//the rules can be reused, unique values are tracked per validation
+	if second := validator.Validate(sd); !reflect.DeepEqual(second.Violations, expected) {
+		t.Errorf("%v != %v\n", second.Violations, expected)
+	}
+
+	export := report.Sheet(16, ";")
+
+	if len(export.Data) != len(expected)+1 || !reflect.DeepEqual(export.Data[1], []string{"2", "1", "email", "regex", "bob", "Value does not match '[^@]+@[^@]+'"}) {
+		t.Errorf("Unexpected exported report %q\n", export.Data)
+	}
+
+	//the exported report keeps its columns after saving and loading it
+	filename := filepath.Join(t.TempDir(), "report.csv")
+
+	if err := report.Sheet(16, ",").Save(filename); err != nil {
+		t.Fatalf("Failed to save the report, error: %s\n", err.Error())
+	}
+
+	loaded := NewSpreadsheetDelim(16, ",")
+
+	if err := loaded.Load(filename, 0, 0); err != nil {
+		t.Fatalf("Failed to load the report, error: %s\n", err.Error())
+	}
+
+	if len(loaded.Data) != len(expected)+1 {
+		t.Fatalf("Unexpected loaded report %q\n", loaded.Data)
+	}
+
+	for i, row := range loaded.Data {
+		if len(row) != 6 {
+			t.Errorf("Row %d of the loaded report has %d columns\n", i, len(row))
+		}
+	}
+
+	if message := loaded.Data[len(expected)][5]; message != "Value is not one of 'active'  'inactive'" {
+		t.Errorf("Unexpected message %q\n", message)
+	}
+}
+
+func TestValidatorColumns(t *testing.T) {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Header = true
+	sd.Data = [][]string{{"id", "name"}, {"1", "ann"}}
+
+	//columns outside of the sheet have no name and no values
+	report := NewValidator().
+		AddRule(-1, RuleRequired()).
+		AddRowRule(RowRule{"both", []int{-2, 1}, func(row []string) string {
+			return "Invalid"
+		}}).
+		Validate(sd)
+
+	expected := []Violation{
+		{1, -1, "-1", "required", "", "Value is required"},
+		{1, -2, "-2", "both", "", "Invalid"},
+		{1, 1, "name", "both", "ann", "Invalid"},
+	}
+
+	if !reflect.DeepEqual(report.Violations, expected) {
+		t.Errorf("%v != %v\n", report.Violations, expected)
+	}
+}
+
+func TestValidatorSplit(t *testing.T) {
+	validator, sd := testValidator()
+	valid, rejected, report := validator.Split(sd)
+
+	if len(report.Violations) == 0 {
+		t.Errorf("Expected violations\n")
+	}
+
+	if !reflect.DeepEqual(valid.Data, [][]string{sd.Data[0], sd.Data[1], sd.Data[4]}) || !valid.Header {
+		t.Errorf("Unexpected valid rows %q\n", valid.Data)
+	}
+
+	if !reflect.DeepEqual(rejected.Data, [][]string{sd.Data[0], sd.Data[2], sd.Data[3]}) {
+		t.Errorf("Unexpected rejected rows %q\n", rejected.Data)
+	}
+}
@@ -0,0 +1,565 @@
+package fio
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//FixedAlign is the type used to define how a value is aligned within a column
+//of a fixed-width spreadsheet. It determines at which side the padding
+//characters are placed when saving, and from which side they are stripped when
+//loading.
+type FixedAlign byte
+
+//The various alignments to use in conjunction with the FixedAlign type
+const (
+	FixedAlignLeft  FixedAlign = iota //value at the start, padding at the end
+	FixedAlignRight                   //padding at the start, value at the end
+)
+
+//SpreadsheetFixedColumn describes a single column within a fixed-width
+//spreadsheet. Start and End are character offsets within a line, where Start is
+//the offset of the first character and End is the offset just past the last
+//character of the column. Padding is the character used to fill up values that
+//are shorter than the column width, a space is used if it is zero.
+type SpreadsheetFixedColumn struct {
+	Start   int
+	End     int
+	Padding rune
+	Align   FixedAlign
+}
+
+//Width returns the number of characters that fit within the column
+func (sfc SpreadsheetFixedColumn) Width() int {
+	return sfc.End - sfc.Start
+}
+
+//padding returns the padding character of the column, a space if none is
+//specified
+func (sfc SpreadsheetFixedColumn) padding() rune {
+	if sfc.Padding == 0 {
+		return ' '
+	}
+
+	return sfc.Padding
+}
+
+//validateSpreadsheetFixedColumns checks if the column offsets are valid and if
+//the columns do not overlap
+func validateSpreadsheetFixedColumns(columns []SpreadsheetFixedColumn) error {
+	sorted := append([]SpreadsheetFixedColumn(nil), columns...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	for i, column := range sorted {
+		if column.Start < 0 || column.End < column.Start {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "Invalid column offsets"}
+		}
+
+		if i != 0 && column.Start < sorted[i-1].End {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "Overlapping columns"}
+		}
+	}
+
+	return nil
+}
+
+//SpreadsheetFixedWidths creates a set of consecutive, left-aligned and space
+//padded column definitions from the specified column widths.
+func SpreadsheetFixedWidths(widths ...int) []SpreadsheetFixedColumn {
+	columns := make([]SpreadsheetFixedColumn, 0, len(widths))
+	offset := 0
+
+	for _, width := range widths {
+		columns = append(columns, SpreadsheetFixedColumn{offset, offset + width, ' ', FixedAlignLeft})
+		offset += width
+	}
+
+	return columns
+}
+
+//The SpreadsheetFixed type represents spreadsheet-like files wherein each
+//column occupies a fixed range of characters within a line and the rows
+//themselves are seperated by a newline character. It implements the
+//Spreadsheeter interface. New instances should be created using the
+//NewSpreadsheetFixed(...) function.
+type SpreadsheetFixed struct {
+	buffer   int
+	Filename string
+	Columns  []SpreadsheetFixedColumn
+	Data     [][]string
+}
+
+//NewSpreadsheetFixed will create a new instance of the SpreadsheetFixed type
+//and return its pointer. The user has to specify the buffer size (which will
+//grow to the largest row in the file) and the column definitions. The column
+//definitions may be nil if they are to be inferred later on using the
+//InferColumns(...) method.
+func NewSpreadsheetFixed(buffer int, columns []SpreadsheetFixedColumn) *SpreadsheetFixed {
+	return &SpreadsheetFixed{buffer, "", columns, nil}
+}
+
+//InferSpreadsheetFixedColumns will attempt to find the column boundaries within
+//the provided sample lines. Every character offset that contains whitespace in
+//all of the sample lines is considered to seperate two columns. A column whose
+//values all end at the same offset is considered to be right-aligned, all other
+//columns are considered to be left-aligned. The whitespace between two columns
+//is assigned to the column that would use it as padding.
+func InferSpreadsheetFixedColumns(sample []string) []SpreadsheetFixedColumn {
+	//convert all lines to runes and find the longest line
+	lines := make([][]rune, 0, len(sample))
+	maxLen := 0
+
+	for _, line := range sample {
+		runes := []rune(line)
+		lines = append(lines, runes)
+
+		if len(runes) > maxLen {
+			maxLen = len(runes)
+		}
+	}
+
+	//mark every offset that is whitespace within all lines
+	blank := make([]bool, maxLen)
+
+	for i := range blank {
+		blank[i] = true
+
+		for _, line := range lines {
+			if i < len(line) && !unicode.IsSpace(line[i]) {
+				blank[i] = false
+				break
+			}
+		}
+	}
+
+	//collect the runs of non-blank offsets, these are the columns
+	var columns []SpreadsheetFixedColumn
+
+	for i := 0; i < maxLen; i++ {
+		if blank[i] {
+			continue
+		}
+
+		start := i
+
+		for i < maxLen && !blank[i] {
+			i++
+		}
+
+		columns = append(columns, SpreadsheetFixedColumn{start, i, ' ', FixedAlignLeft})
+	}
+
+	//determine the alignment of each column
+	for i := range columns {
+		column := &columns[i]
+		sameStart, sameEnd := true, true
+
+		for _, line := range lines {
+			if column.Start >= len(line) {
+				continue
+			}
+
+			end := column.End
+
+			if end > len(line) {
+				end = len(line)
+			}
+
+			cell := line[column.Start:end]
+			trimmed := strings.TrimSpace(string(cell))
+
+			if len(trimmed) == 0 {
+				continue
+			}
+
+			if unicode.IsSpace(cell[0]) {
+				sameStart = false
+			}
+
+			if end != column.End || unicode.IsSpace(cell[len(cell)-1]) {
+				sameEnd = false
+			}
+		}
+
+		if sameEnd && !sameStart {
+			column.Align = FixedAlignRight
+		}
+	}
+
+	//hand out the whitespace between the columns
+	for i := range columns {
+		if columns[i].Align == FixedAlignLeft {
+			if i+1 < len(columns) {
+				columns[i].End = columns[i+1].Start
+			} else {
+				columns[i].End = maxLen
+			}
+		} else {
+			if i == 0 {
+				columns[i].Start = 0
+			} else if columns[i-1].Align == FixedAlignRight {
+				columns[i].Start = columns[i-1].End
+			}
+		}
+	}
+
+	return columns
+}
+
+//InferColumns will read up to sampleRows lines from the specified file and use
+//them to infer the column definitions of the spreadsheet using the
+//InferSpreadsheetFixedColumns(...) function. In case the filename is empty
+//the filename from the previous Load(...) call will be used.
+func (sf *SpreadsheetFixed) InferColumns(filename string, sampleRows int) error {
+	//check if a valid filename exists
+	if len(filename) == 0 {
+		if len(sf.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "No filename specified to infer columns from"}
+		}
+
+		filename = sf.Filename
+	}
+
+	if sampleRows <= 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "Number of sample rows should be positive"}
+	}
+
+	file, err := os.Open(filename)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to open file"}
+	}
+
+	reader := bufio.NewReader(file)
+	buffer := make([]byte, 0, sf.buffer)
+	sample := make([]string, 0, sampleRows)
+	eof := false
+
+	for !eof && len(sample) < sampleRows {
+		buffer = buffer[:0]
+		eof, err = ReadBufferedLine(reader, &buffer)
+
+		if err != nil {
+			file.Close()
+			return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to read a new line"}
+		}
+
+		if len(buffer) != 0 {
+			sample = append(sample, string(buffer))
+		}
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to close the file after sampling"}
+	}
+
+	if len(sample) == 0 {
+		return Error{ErrorTypeParsing, "SpreadsheetFixed", "File does not contain any lines to infer columns from"}
+	}
+
+	sf.Columns = InferSpreadsheetFixedColumns(sample)
+	return nil
+}
+
+//Load will load data from a fixed-width spreadsheet into the SpreadsheetFixed
+//instance, replacing any previously loaded data. The user can specify a
+//filename, if an empty one is specified then the filename from the previous
+//Load(...) call will be used. Padding characters are stripped from the values
+//according to the alignment of each column.
+func (sf *SpreadsheetFixed) Load(filename string) error {
+	//check if a valid filename exists
+	if len(filename) == 0 {
+		if len(sf.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "No filename specified to load"}
+		}
+
+		filename = sf.Filename
+	} else {
+		sf.Filename = filename
+	}
+
+	if len(sf.Columns) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "No columns defined to load"}
+	}
+
+	if err := validateSpreadsheetFixedColumns(sf.Columns); err != nil {
+		return err
+	}
+
+	//open the file, then create a buffer and a buffered reader
+	file, err := os.Open(filename)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to load file"}
+	}
+
+	reader := bufio.NewReader(file)
+	buffer := make([]byte, 0, sf.buffer)
+	sf.Data = nil
+	eof := false
+
+	for !eof {
+		//read a new line
+		buffer = buffer[:0]
+		eof, err = ReadBufferedLine(reader, &buffer)
+
+		if err != nil {
+			file.Close()
+			return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to read a new line"}
+		}
+
+		//check if the line contains any data at all
+		if len(buffer) == 0 {
+			continue
+		}
+
+		//cut the line into its columns
+		line := []rune(string(buffer))
+		row := make([]string, len(sf.Columns))
+
+		for i, column := range sf.Columns {
+			if column.Start >= len(line) {
+				continue
+			}
+
+			end := column.End
+
+			if end > len(line) {
+				end = len(line)
+			}
+
+			cell := string(line[column.Start:end])
+
+			if column.Align == FixedAlignRight {
+				row[i] = strings.TrimLeft(cell, string(column.padding()))
+			} else {
+				row[i] = strings.TrimRight(cell, string(column.padding()))
+			}
+		}
+
+		sf.Data = append(sf.Data, row)
+	}
+
+	//all data is succesfully loaded
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "SpreadsheetFixed", "Failed to close the file after loading"}
+	}
+
+	return nil
+}
+
+//Save will save the current contents from the SpreadsheetFixed type to a file.
+//A filename can be specified, if an empty filename is specified then the
+//filename used for the last call to the Load(...) function is used. Values that
+//are shorter than their column are padded, values that are longer than their
+//column or contain line breaks will cause an error to be returned.
+func (sf *SpreadsheetFixed) Save(filename string) error {
+	//check if a filename is specified
+	if len(filename) == 0 {
+		if len(sf.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "No filename specified to save"}
+		}
+
+		filename = sf.Filename
+	}
+
+	//validate the column definitions and determine the length of a full line
+	if err := validateSpreadsheetFixedColumns(sf.Columns); err != nil {
+		return err
+	}
+
+	lineLen := 0
+
+	for _, column := range sf.Columns {
+		if column.End > lineLen {
+			lineLen = column.End
+		}
+	}
+
+	//format all rows before touching the file, so no half-written file remains
+	//when a value does not fit
+	lines := make([]string, 0, len(sf.Data))
+	line := make([]rune, lineLen)
+
+	for _, row := range sf.Data {
+		if len(row) > len(sf.Columns) {
+			return Error{ErrorTypeSaving, "SpreadsheetFixed", "Row " + strconv.Itoa(len(lines)) + " contains more values than there are columns"}
+		}
+
+		for i := range line {
+			line[i] = ' '
+		}
+
+		for i, column := range sf.Columns {
+			var value []rune
+
+			if i < len(row) {
+				value = []rune(row[i])
+			}
+
+			if len(value) > column.Width() {
+				return Error{ErrorTypeSaving, "SpreadsheetFixed", "Value at row " + strconv.Itoa(len(lines)) + ", column " + strconv.Itoa(i) + " does not fit within the column width"}
+			}
+
+			if strings.ContainsAny(string(value), "\r\n") {
+				return Error{ErrorTypeSaving, "SpreadsheetFixed", "Value at row " + strconv.Itoa(len(lines)) + ", column " + strconv.Itoa(i) + " contains a line break"}
+			}
+
+			//pad the value on the side indicated by the alignment
+			padding := column.Width() - len(value)
+			offset := column.Start
+
+			if column.Align == FixedAlignRight {
+				for j := 0; j < padding; j++ {
+					line[offset] = column.padding()
+					offset++
+				}
+			}
+
+			offset += copy(line[offset:], value)
+
+			if column.Align != FixedAlignRight {
+				for j := 0; j < padding; j++ {
+					line[offset] = column.padding()
+					offset++
+				}
+			}
+		}
+
+		lines = append(lines, string(line)+"\n")
+	}
+
+	//open the file to save the data to
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetFixed", "Failed to create/open file for writing"}
+	}
+
+	for _, line := range lines {
+		_, err = file.WriteString(line)
+
+		if err != nil { //uses the fact that if err != nil, then nWritten < len(line)
+			file.Close()
+			return Error{ErrorTypeSaving, "SpreadsheetFixed", "Failed to write data row to file"}
+		}
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetFixed", "Failed to close the file after saving"}
+	}
+
+	return nil
+}
+
+//Set will set a specifed value at the location of the specified row and column.
+//Intermediate rows will be created if the specified row does not yet exist. The
+//column must be one of the defined columns.
+func (sf *SpreadsheetFixed) Set(row, col int, value string) error {
+	if row < 0 || col < 0 || col >= len(sf.Columns) {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetFixed", "Location lies outside of the defined columns"}
+	}
+
+	//append non-existant rows
+	for i := len(sf.Data); i <= row; i++ {
+		sf.Data = append(sf.Data, []string{})
+	}
+
+	//append non-existant columns
+	for i := len(sf.Data[row]); i <= col; i++ {
+		sf.Data[row] = append(sf.Data[row], "")
+	}
+
+	//set specified value
+	sf.Data[row][col] = value
+	return nil
+}
+
+//Get will retrieve a value from the location of the specified row and column.
+//In case the location does not exist within the current bounds of the
+//spreadsheet then the function will return false.
+func (sf *SpreadsheetFixed) Get(row, col int) (string, bool) {
+	if row < 0 || row >= len(sf.Data) {
+		return "", false
+	}
+
+	if col < 0 || col >= len(sf.Data[row]) {
+		return "", false
+	}
+
+	return sf.Data[row][col], true
+}
+
+//See Get(...), includes a conversion to int. In case the conversion fails the
+//error will be non-nil
+func (sf *SpreadsheetFixed) GetInt(row, col int) (int, bool, error) {
+	//use Get(...)
+	str, ok := sf.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to int
+		result, err := strconv.Atoi(str)
+		return result, true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to uint. In case the conversion fails the
+//error will be non-nil
+func (sf *SpreadsheetFixed) GetUint(row, col int) (uint, bool, error) {
+	//use Get(...)
+	str, ok := sf.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to uint
+		result, err := strconv.ParseUint(str, 10, strconv.IntSize)
+		return uint(result), true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float32. In case the conversion fails the
+//error will be non-nil
+func (sf *SpreadsheetFixed) GetFloat32(row, col int) (float32, bool, error) {
+	//use Get(...)
+	str, ok := sf.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float32
+		result, err := strconv.ParseFloat(str, 32)
+		return float32(result), true, err
+	}
+
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float64. In case the conversion fails the
+//error will be non-nil
+func (sf *SpreadsheetFixed) GetFloat64(row, col int) (float64, bool, error) {
+	//use Get(...)
+	str, ok := sf.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float64
+		result, err := strconv.ParseFloat(str, 64)
+		return result, true, err
+	}
+
+	return 0, false, nil
+}
@@ -0,0 +1,144 @@
+package fio
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+const testFilenameSpreadsheetFixed = "test_file.txt"
+
+func testSpreadsheetFixedOnce(rows, buffer int, t *testing.T) {
+	//create a fixed-width spreadsheet with a left and right aligned column
+	columns := SpreadsheetFixedWidths(8, 6, 4)
+	columns[1].Align = FixedAlignRight
+	columns[1].Padding = '0'
+
+	sf := NewSpreadsheetFixed(buffer, columns)
+
+	for row := 0; row < rows; row++ {
+		sf.Set(row, 0, "r"+strconv.Itoa(row))
+		sf.Set(row, 1, strconv.Itoa(row*7+1))
+		sf.Set(row, 2, "x")
+	}
+
+	err := sf.Save(testFilenameSpreadsheetFixed)
+
+	if err != nil {
+		t.Errorf("Failed to write to file, error: %s\n", err.Error())
+	}
+
+	defer func() { os.Remove(testFilenameSpreadsheetFixed) }()
+
+	//reload and check the values
+	sf2 := NewSpreadsheetFixed(buffer, columns)
+	err = sf2.Load(testFilenameSpreadsheetFixed)
+
+	if err != nil {
+		t.Errorf("Failed to reload the file, error: %s\n", err.Error())
+	}
+
+	for row := 0; row < rows; row++ {
+		if value, _ := sf2.Get(row, 0); value != "r"+strconv.Itoa(row) {
+			t.Errorf("'%s' [r:%d, c:0] != 'r%d'\n", value, row, row)
+		}
+
+		if value, _, err := sf2.GetInt(row, 1); err != nil || value != row*7+1 {
+			t.Errorf("%d [r:%d, c:1] != %d\n", value, row, row*7+1)
+		}
+	}
+}
+
+func TestSpreadsheetFixed(t *testing.T) {
+	testRows := [...]int{1, 5, 100}
+	testBuffer := [...]int{1, 16, 1024}
+
+	for _, buffer := range testBuffer {
+		for _, rows := range testRows {
+			testSpreadsheetFixedOnce(rows, buffer, t)
+		}
+	}
+}
+
+func TestSpreadsheetFixedReject(t *testing.T) {
+	sf := NewSpreadsheetFixed(16, SpreadsheetFixedWidths(3, 3))
+
+	if err := sf.Set(0, 2, "a"); err == nil {
+		t.Errorf("Expected setting an undefined column to fail\n")
+	}
+
+	sf.Set(0, 0, "toolong")
+
+	if err := sf.Save(testFilenameSpreadsheetFixed); err == nil {
+		t.Errorf("Expected saving a value that does not fit to fail\n")
+		os.Remove(testFilenameSpreadsheetFixed)
+	}
+
+	sf.Set(0, 0, "a\nb")
+
+	if err := sf.Save(testFilenameSpreadsheetFixed); err == nil || err.(Error).t != ErrorTypeSaving {
+		t.Errorf("Expected saving a value containing a line break to fail, got %v\n", err)
+		os.Remove(testFilenameSpreadsheetFixed)
+	}
+
+	//columns without padding character are padded using spaces
+	sf = NewSpreadsheetFixed(16, []SpreadsheetFixedColumn{{Start: 0, End: 3}, {Start: 3, End: 6, Align: FixedAlignRight}})
+	sf.Set(0, 0, "a")
+	sf.Set(0, 1, "b")
+
+	if err := sf.Save(testFilenameSpreadsheetFixed); err != nil {
+		t.Errorf("Failed to save, error: %s\n", err.Error())
+	}
+
+	if data, _ := os.ReadFile(testFilenameSpreadsheetFixed); string(data) != "a    b\n" {
+		t.Errorf("Expected space padding, got %q\n", data)
+	}
+
+	if err := sf.Load(testFilenameSpreadsheetFixed); err != nil || sf.Data[0][0] != "a" || sf.Data[0][1] != "b" {
+		t.Errorf("Unexpected reloaded values %q (error: %v)\n", sf.Data, err)
+	}
+
+	//invalid column definitions are rejected before reading the file
+	os.WriteFile(testFilenameSpreadsheetFixed, []byte("abcdef\n"), 0644)
+	defer os.Remove(testFilenameSpreadsheetFixed)
+
+	invalid := [][]SpreadsheetFixedColumn{
+		{{-1, 3, ' ', FixedAlignLeft}},
+		{{3, 1, ' ', FixedAlignLeft}},
+		{{0, 4, ' ', FixedAlignLeft}, {2, 6, ' ', FixedAlignLeft}},
+	}
+
+	for _, columns := range invalid {
+		err := NewSpreadsheetFixed(16, columns).Load(testFilenameSpreadsheetFixed)
+
+		if e, ok := err.(Error); !ok || e.t != ErrorTypeInvalidArgument {
+			t.Errorf("Expected columns %v to be rejected, got %v\n", columns, err)
+		}
+	}
+}
+
+func TestSpreadsheetFixedInfer(t *testing.T) {
+	sample := []string{
+		"ACCOUNT      AMOUNT CUR",
+		"NL01          12.50 EUR",
+		"DE4455      1000.00 USD",
+	}
+
+	columns := InferSpreadsheetFixedColumns(sample)
+
+	if len(columns) != 3 {
+		t.Fatalf("Expected 3 inferred columns, got %d\n", len(columns))
+	}
+
+	expected := [...]SpreadsheetFixedColumn{
+		{0, 12, ' ', FixedAlignLeft},
+		{12, 19, ' ', FixedAlignRight},
+		{20, 23, ' ', FixedAlignLeft},
+	}
+
+	for i, column := range columns {
+		if column != expected[i] {
+			t.Errorf("Inferred column %d = %+v, expected %+v\n", i, column, expected[i])
+		}
+	}
+}
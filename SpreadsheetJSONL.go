@@ -0,0 +1,505 @@
+package fio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//JSONLOptions contains the options used while converting between rows of a
+//spreadsheet and JSON Lines (newline delimited JSON) records.
+type JSONLOptions struct {
+	//InferTypes converts values that look like numbers, booleans or nulls to
+	//their JSON type when writing JSON. Empty values are written as null.
+	InferTypes bool
+
+	//Nest converts dotted column names to nested objects when writing JSON,
+	//such that the column 'a.b' is written as {"a":{"b":...}}.
+	Nest bool
+
+	//Columns specifies the columns to produce when reading JSON. If it is empty
+	//the columns are taken from the first record while streaming, or from all
+	//records when loading into a SpreadsheetDelim.
+	Columns []string
+
+	//Strict causes an error to be returned when reading a record that contains
+	//a key that is not one of the columns, instead of ignoring the key.
+	Strict bool
+
+	//ArraySeparator joins the elements of arrays when reading JSON. If it is
+	//empty arrays are kept as compact JSON text, which contains commas and can
+	//therefore not be written to a comma-delimited spreadsheet. Strings are
+	//joined without quotes and nulls as empty values, nested arrays and objects
+	//remain JSON text.
+	ArraySeparator string
+}
+
+//jsonlNumber matches the JSON number syntax
+var jsonlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+//jsonlNode is a node of the tree of (nested) keys of a JSON object. Leaves
+//refer to a column of the spreadsheet.
+type jsonlNode struct {
+	keys     []string
+	children map[string]*jsonlNode
+	column   int
+}
+
+//jsonlBuildTree creates the tree of keys for the specified header. When nest is
+//false all columns are leaves of the root node.
+func jsonlBuildTree(header []string, nest bool) (*jsonlNode, error) {
+	root := &jsonlNode{nil, make(map[string]*jsonlNode), -1}
+
+	for column, name := range header {
+		path := []string{name}
+
+		if nest {
+			path = strings.Split(name, ".")
+		}
+
+		node := root
+
+		for i, key := range path {
+			child, ok := node.children[key]
+			leaf := i == len(path)-1
+
+			if ok && (leaf || child.column != -1) {
+				return nil, Error{ErrorTypeInvalidArgument, "JSONL", "Column '" + name + "' conflicts with another column"}
+			}
+
+			if !ok {
+				child = &jsonlNode{nil, make(map[string]*jsonlNode), -1}
+				node.keys = append(node.keys, key)
+				node.children[key] = child
+			}
+
+			if leaf {
+				child.column = column
+			}
+
+			node = child
+		}
+	}
+
+	return root, nil
+}
+
+//jsonlWriteString writes a string as a JSON string, without escaping HTML
+//characters
+func jsonlWriteString(buffer *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	//remove the newline appended by the encoder
+	buffer.Truncate(buffer.Len() - 1)
+}
+
+//jsonlWriteValue writes a single value, converting it to its JSON type if
+//inferTypes is true
+func jsonlWriteValue(buffer *bytes.Buffer, value string, inferTypes bool) {
+	if inferTypes {
+		switch {
+		case len(value) == 0 || value == "null" || value == "NULL":
+			buffer.WriteString("null")
+			return
+		case value == "true" || value == "TRUE" || value == "True":
+			buffer.WriteString("true")
+			return
+		case value == "false" || value == "FALSE" || value == "False":
+			buffer.WriteString("false")
+			return
+		case jsonlNumber.MatchString(value):
+			buffer.WriteString(value)
+			return
+		}
+	}
+
+	jsonlWriteString(buffer, value)
+}
+
+//jsonlWriteObject writes the object described by the node using the values of
+//the row
+func jsonlWriteObject(buffer *bytes.Buffer, node *jsonlNode, row []string, inferTypes bool) {
+	buffer.WriteByte('{')
+
+	for i, key := range node.keys {
+		if i != 0 {
+			buffer.WriteByte(',')
+		}
+
+		jsonlWriteString(buffer, key)
+		buffer.WriteByte(':')
+		child := node.children[key]
+
+		if child.column == -1 {
+			jsonlWriteObject(buffer, child, row, inferTypes)
+		} else if child.column < len(row) {
+			jsonlWriteValue(buffer, row[child.column], inferTypes)
+		} else {
+			jsonlWriteValue(buffer, "", inferTypes)
+		}
+	}
+
+	buffer.WriteByte('}')
+}
+
+//WriteJSONL converts rows to JSON Lines. The first row read from the reader is
+//the header, which contains the keys of the written objects. Each following
+//row is written as a single line containing a JSON object. Rows are processed
+//one at a time, so files of any size can be converted when streaming from a
+//SpreadsheetDelimReader.
+func WriteJSONL(r RowReader, w io.Writer, options JSONLOptions) error {
+	header, err := r.ReadRow()
+
+	if err == io.EOF {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	tree, err := jsonlBuildTree(header, options.Nest)
+
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	var buffer bytes.Buffer
+
+	for index := 1; ; index++ {
+		row, err := r.ReadRow()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(row) > len(header) {
+			return Error{ErrorTypeParsing, "JSONL", "Row " + strconv.Itoa(index) + " contains more values than the header"}
+		}
+
+		buffer.Reset()
+		jsonlWriteObject(&buffer, tree, row, options.InferTypes)
+		buffer.WriteByte('\n')
+
+		if _, err = writer.Write(buffer.Bytes()); err != nil {
+			return Error{ErrorTypeSaving, "JSONL", "Failed to write record " + strconv.Itoa(index)}
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "JSONL", "Failed to flush the written records"}
+	}
+
+	return nil
+}
+
+//jsonlArray converts a JSON array to a single value, see
+//JSONLOptions.ArraySeparator
+func jsonlArray(raw json.RawMessage, separator string) (string, error) {
+	var compact bytes.Buffer
+	json.Compact(&compact, raw)
+
+	if len(separator) == 0 {
+		return compact.String(), nil
+	}
+
+	var elements []json.RawMessage
+
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return "", err
+	}
+
+	values := make([]string, len(elements))
+
+	for i, element := range elements {
+		compact.Reset()
+		json.Compact(&compact, element)
+
+		switch element[0] {
+		case '"':
+			json.Unmarshal(element, &values[i])
+		case 'n':
+		default:
+			values[i] = compact.String()
+		}
+	}
+
+	return strings.Join(values, separator), nil
+}
+
+//jsonlFlatten converts a JSON object to a list of dotted keys and their values.
+//Nested objects are flattened, arrays are converted using jsonlArray(...) and
+//null becomes an empty value.
+func jsonlFlatten(raw json.RawMessage, prefix, separator string, keys, values *[]string) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		key := prefix + token.(string)
+		var value json.RawMessage
+
+		if err = decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		switch value[0] {
+		case '{':
+			if err = jsonlFlatten(value, key+".", separator, keys, values); err != nil {
+				return err
+			}
+
+			continue
+		case '"':
+			var s string
+			json.Unmarshal(value, &s)
+			*values = append(*values, s)
+		case 'n':
+			*values = append(*values, "")
+		case '[':
+			array, err := jsonlArray(value, separator)
+
+			if err != nil {
+				return err
+			}
+
+			*values = append(*values, array)
+		default:
+			*values = append(*values, string(value))
+		}
+
+		*keys = append(*keys, key)
+	}
+
+	return nil
+}
+
+//jsonlDecoder reads and flattens the records of a JSON Lines stream
+type jsonlDecoder struct {
+	decoder   *json.Decoder
+	index     int
+	separator string //see JSONLOptions.ArraySeparator
+}
+
+//next returns the flattened keys and values of the next record, or io.EOF if
+//no records remain
+func (jd *jsonlDecoder) next() ([]string, []string, error) {
+	var raw json.RawMessage
+	err := jd.decoder.Decode(&raw)
+
+	if err == io.EOF {
+		return nil, nil, err
+	}
+
+	jd.index++
+
+	if err != nil {
+		return nil, nil, Error{ErrorTypeParsing, "JSONL", "Invalid JSON in record " + strconv.Itoa(jd.index) + ": " + err.Error()}
+	}
+
+	if raw[0] != '{' {
+		return nil, nil, Error{ErrorTypeParsing, "JSONL", "Record " + strconv.Itoa(jd.index) + " is not an object"}
+	}
+
+	var keys, values []string
+
+	if err = jsonlFlatten(raw, "", jd.separator, &keys, &values); err != nil {
+		return nil, nil, Error{ErrorTypeParsing, "JSONL", "Invalid JSON in record " + strconv.Itoa(jd.index) + ": " + err.Error()}
+	}
+
+	return keys, values, nil
+}
+
+//jsonlRow converts flattened keys and values to a row with the specified
+//columns
+func jsonlRow(columns map[string]int, keys, values []string, strict bool, index int) ([]string, error) {
+	row := make([]string, len(columns))
+
+	for i, key := range keys {
+		column, ok := columns[key]
+
+		if !ok {
+			if strict {
+				return nil, Error{ErrorTypeParsing, "JSONL", "Record " + strconv.Itoa(index) + " contains unknown key '" + key + "'"}
+			}
+
+			continue
+		}
+
+		row[column] = values[i]
+	}
+
+	return row, nil
+}
+
+//jsonlColumnMap maps each column name to its index
+func jsonlColumnMap(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	return columns
+}
+
+//ReadJSONL converts JSON Lines to rows. The first row written to the writer is
+//the header, containing the (dotted) keys of the records. Nested objects are
+//flattened to dotted column names, arrays are written as JSON text (unless
+//options.ArraySeparator is specified) and nulls as empty values. If options.Columns is empty the columns are taken from the first
+//record. Records are processed one at a time, so files of any size can be
+//converted when streaming to a SpreadsheetDelimWriter.
+func ReadJSONL(r io.Reader, w RowWriter, options JSONLOptions) error {
+	decoder := &jsonlDecoder{json.NewDecoder(r), 0, options.ArraySeparator}
+	header := options.Columns
+	var columns map[string]int
+
+	if len(header) != 0 {
+		if err := w.WriteRow(header); err != nil {
+			return err
+		}
+
+		columns = jsonlColumnMap(header)
+	}
+
+	for {
+		keys, values, err := decoder.next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		//use the keys of the first record as header if no columns are specified
+		if columns == nil {
+			if err = w.WriteRow(keys); err != nil {
+				return err
+			}
+
+			columns = jsonlColumnMap(keys)
+		}
+
+		row, err := jsonlRow(columns, keys, values, options.Strict, decoder.index)
+
+		if err != nil {
+			return err
+		}
+
+		if err = w.WriteRow(row); err != nil {
+			return err
+		}
+	}
+}
+
+//LoadJSONL will load the records from a JSON Lines file into the
+//SpreadsheetDelim instance, replacing any previously loaded data. See
+//ReadJSONL(...). As all records are held in memory, the columns are the union
+//of the keys of all records if options.Columns is empty. The first row contains
+//the column names, so Header is set if any columns exist.
+func (sd *SpreadsheetDelim) LoadJSONL(filename string, options JSONLOptions) error {
+	file, err := os.Open(filename)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "JSONL", "Failed to open the file"}
+	}
+
+	defer file.Close()
+
+	decoder := &jsonlDecoder{json.NewDecoder(bufio.NewReader(file)), 0, options.ArraySeparator}
+	header := options.Columns
+	columns := jsonlColumnMap(header)
+	var records [][2][]string
+
+	for {
+		keys, values, err := decoder.next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		//collect the union of all keys, in order of appearance
+		if len(options.Columns) == 0 {
+			for _, key := range keys {
+				if _, ok := columns[key]; !ok {
+					columns[key] = len(header)
+					header = append(header, key)
+				}
+			}
+		}
+
+		records = append(records, [2][]string{keys, values})
+	}
+
+	sd.Data = nil
+	sd.Header = len(header) != 0
+
+	if sd.Header {
+		sd.Data = append(sd.Data, append([]string(nil), header...))
+	}
+
+	for i, record := range records {
+		row, err := jsonlRow(columns, record[0], record[1], options.Strict, i+1)
+
+		if err != nil {
+			sd.Data, sd.Header = nil, false
+			return err
+		}
+
+		sd.Data = append(sd.Data, row)
+	}
+
+	return nil
+}
+
+//SaveJSONL will save the contents of the SpreadsheetDelim instance to a JSON
+//Lines file, using the first row as header. See WriteJSONL(...).
+func (sd *SpreadsheetDelim) SaveJSONL(filename string, options JSONLOptions) error {
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "JSONL", "Failed to create/open file for writing"}
+	}
+
+	err = WriteJSONL(sd.Rows(), file, options)
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "JSONL", "Failed to close the file after saving"}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package fio
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testFilenameSpreadsheetJSONL = "test_file.jsonl"
+
+func TestSpreadsheetJSONLWrite(t *testing.T) {
+	input := "id,name,address.city,address.zip,active,score\n" +
+		"1,Ann <a>,Delft,02611,true,\n" +
+		"\n" +
+		"2,Bob,Leiden,2311,FALSE,3.5\n"
+
+	tests := [...]struct {
+		options  JSONLOptions
+		expected string
+	}{
+		{JSONLOptions{}, `{"id":"1","name":"Ann <a>","address.city":"Delft","address.zip":"02611","active":"true","score":""}` + "\n" +
+			`{"id":"2","name":"Bob","address.city":"Leiden","address.zip":"2311","active":"FALSE","score":"3.5"}` + "\n"},
+		{JSONLOptions{InferTypes: true, Nest: true}, `{"id":1,"name":"Ann <a>","address":{"city":"Delft","zip":"02611"},"active":true,"score":null}` + "\n" +
+			`{"id":2,"name":"Bob","address":{"city":"Leiden","zip":2311},"active":false,"score":3.5}` + "\n"},
+	}
+
+	for _, test := range tests {
+		var output bytes.Buffer
+		reader := NewSpreadsheetDelimReader(strings.NewReader(input), 4, ",")
+		err := WriteJSONL(reader, &output, test.options)
+
+		if err != nil {
+			t.Errorf("Failed to write JSON Lines, error: %s\n", err.Error())
+		}
+
+		if output.String() != test.expected {
+			t.Errorf("Unexpected JSON Lines output:\n%s\nexpected:\n%s\n", output.String(), test.expected)
+		}
+	}
+
+	reader := NewSpreadsheetDelimReader(strings.NewReader("a,a.b\n1,2\n"), 4, ",")
+
+	if err := WriteJSONL(reader, &bytes.Buffer{}, JSONLOptions{Nest: true}); err == nil {
+		t.Errorf("Expected conflicting nested columns to fail\n")
+	}
+}
+
+func TestSpreadsheetJSONLRead(t *testing.T) {
+	input := `{"id":1,"name":"Ann","address":{"city":"Delft","geo":{"lat":52.0}},"tags":["x", "y"],"note":null}
+{"id":2,"name":"Bob","extra":true}
+`
+
+	//stream with the columns taken from the first record
+	var output bytes.Buffer
+	writer := NewSpreadsheetDelimWriter(&output, ";")
+	err := ReadJSONL(strings.NewReader(input), writer, JSONLOptions{})
+	writer.Flush()
+
+	expected := "id;name;address.city;address.geo.lat;tags;note\n" +
+		`1;Ann;Delft;52.0;["x","y"];` + "\n" +
+		"2;Bob;;;;\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected rows (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	//strict mode should reject the extra key of the second record
+	err = ReadJSONL(strings.NewReader(input), NewSpreadsheetDelim(16, ";"), JSONLOptions{Strict: true})
+
+	if err == nil {
+		t.Errorf("Expected an unknown key to fail in strict mode\n")
+	}
+
+	//loading should use the union of all keys
+	os.WriteFile(testFilenameSpreadsheetJSONL, []byte(input), 0644)
+	defer os.Remove(testFilenameSpreadsheetJSONL)
+
+	sd := NewSpreadsheetDelim(16, ",")
+	err = sd.LoadJSONL(testFilenameSpreadsheetJSONL, JSONLOptions{})
+
+	if err != nil {
+		t.Fatalf("Failed to load JSON Lines, error: %s\n", err.Error())
+	}
+
+	if !sd.Header {
+		t.Errorf("Expected the loaded spreadsheet to have a header\n")
+	}
+
+	if value, _ := sd.Get(0, 6); value != "extra" {
+		t.Errorf("'%s' [r:0, c:6] != 'extra'\n", value)
+	}
+
+	if value, _ := sd.Get(2, 6); value != "true" {
+		t.Errorf("'%s' [r:2, c:6] != 'true'\n", value)
+	}
+
+	//saving and reloading should produce the same data
+	err = sd.SaveJSONL(testFilenameSpreadsheetJSONL, JSONLOptions{Nest: true})
+
+	if err != nil {
+		t.Fatalf("Failed to save JSON Lines, error: %s\n", err.Error())
+	}
+
+	sd2 := NewSpreadsheetDelim(16, ",")
+	err = sd2.LoadJSONL(testFilenameSpreadsheetJSONL, JSONLOptions{})
+
+	if err != nil {
+		t.Fatalf("Failed to reload JSON Lines, error: %s\n", err.Error())
+	}
+
+	for row := range sd.Data {
+		for col := range sd.Data[row] {
+			if value, _ := sd2.Get(row, col); value != sd.Data[row][col] {
+				t.Errorf("'%s' [r:%d, c:%d] != '%s'\n", value, row, col, sd.Data[row][col])
+			}
+		}
+	}
+}
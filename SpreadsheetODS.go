@@ -0,0 +1,714 @@
+package fio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//The namespaces used within the content of an OpenDocument spreadsheet
+const (
+	odsNamespaceTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsNamespaceOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsNamespaceText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odsMimeType        = "application/vnd.oasis.opendocument.spreadsheet"
+	odsMaxSpaces       = 32767 //the maximum number of consecutive spaces, which is the maximum length of a cell
+)
+
+//odsSheet is a single named sheet as it is read from or written to a document
+type odsSheet struct {
+	name  string
+	data  [][]string
+	types [][]CellType
+}
+
+//odsAttr retrieves the value of an attribute within the specified namespace
+func odsAttr(element xml.StartElement, space, local string) (string, bool) {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+//odsRepeat retrieves the repeat count stored in the specified attribute of an
+//element, defaulting to 1
+func odsRepeat(element xml.StartElement, local string) (int, error) {
+	value, ok := odsAttr(element, odsNamespaceTable, local)
+
+	if !ok {
+		return 1, nil
+	}
+
+	repeat, err := strconv.Atoi(value)
+
+	if err != nil || repeat < 1 {
+		return 0, Error{ErrorTypeParsing, "SpreadsheetODS", "Invalid repeat count '" + value + "'"}
+	}
+
+	return repeat, nil
+}
+
+//odsReadCell reads the contents of a table:table-cell element, of which the
+//start element is already consumed. The value attributes take precedence over
+//the displayed text.
+func odsReadCell(decoder *xml.Decoder, start xml.StartElement) (string, CellType, error) {
+	var text bytes.Buffer
+	paragraphs := 0
+	depth := 1
+
+	//read the displayed text, seperating paragraphs with a newline
+	for depth > 0 {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return "", CellTypeString, Error{ErrorTypeParsing, "SpreadsheetODS", "Unexpected end of cell: " + err.Error()}
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			//annotations are not part of the cell text
+			if t.Name.Space == odsNamespaceOffice && t.Name.Local == "annotation" {
+				if err = decoder.Skip(); err != nil {
+					return "", CellTypeString, Error{ErrorTypeParsing, "SpreadsheetODS", "Unexpected end of annotation: " + err.Error()}
+				}
+
+				continue
+			}
+
+			depth++
+
+			if t.Name.Space == odsNamespaceText {
+				switch t.Name.Local {
+				case "p":
+					if paragraphs > 0 {
+						text.WriteByte('\n')
+					}
+
+					paragraphs++
+				case "s":
+					count := 1
+
+					if c, ok := odsAttr(t, odsNamespaceText, "c"); ok {
+						if count, err = strconv.Atoi(c); err != nil || count < 1 || count > odsMaxSpaces {
+							return "", CellTypeString, Error{ErrorTypeParsing, "SpreadsheetODS", "Invalid space count '" + c + "'"}
+						}
+					}
+
+					text.WriteString(strings.Repeat(" ", count))
+				case "tab":
+					text.WriteByte('\t')
+				case "line-break":
+					text.WriteByte('\n')
+				}
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			//only text within the paragraphs is part of the cell text
+			if depth >= 2 {
+				text.Write(t)
+			}
+		}
+	}
+
+	//use the value attributes to retrieve the typed value
+	valueType, _ := odsAttr(start, odsNamespaceOffice, "value-type")
+
+	switch valueType {
+	case "float", "percentage", "currency":
+		if value, ok := odsAttr(start, odsNamespaceOffice, "value"); ok {
+			return value, CellTypeNumber, nil
+		}
+	case "boolean":
+		if value, ok := odsAttr(start, odsNamespaceOffice, "boolean-value"); ok {
+			if value == "true" {
+				return cellBoolTrue, CellTypeBool, nil
+			}
+
+			return cellBoolFalse, CellTypeBool, nil
+		}
+	case "date":
+		if value, ok := odsAttr(start, odsNamespaceOffice, "date-value"); ok {
+			t, err := time.Parse("2006-01-02T15:04:05", value)
+
+			if err != nil {
+				t, err = time.Parse(CellDateLayout, value)
+			}
+
+			if err == nil {
+				return formatCellTime(t), CellTypeDate, nil
+			}
+		}
+	case "string":
+		if value, ok := odsAttr(start, odsNamespaceOffice, "string-value"); ok {
+			return value, CellTypeString, nil
+		}
+	}
+
+	return text.String(), CellTypeString, nil
+}
+
+//odsReadContent reads all sheets from the content.xml part of a document
+func odsReadContent(reader io.Reader) ([]odsSheet, error) {
+	decoder := xml.NewDecoder(reader)
+	var sheets []odsSheet
+	var sheet *odsSheet
+	var rowRepeat int
+	var row []string
+	var rowTypes []CellType
+	var emptyRows, emptyCells int
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, Error{ErrorTypeParsing, "SpreadsheetODS", "Failed to decode the content: " + err.Error()}
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsNamespaceTable {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "table":
+				name, _ := odsAttr(t, odsNamespaceTable, "name")
+				sheets = append(sheets, odsSheet{name: name})
+				sheet = &sheets[len(sheets)-1]
+				emptyRows = 0
+			case "table-row":
+				if sheet == nil {
+					return nil, Error{ErrorTypeParsing, "SpreadsheetODS", "Row encountered outside of a table"}
+				}
+
+				if rowRepeat, err = odsRepeat(t, "number-rows-repeated"); err != nil {
+					return nil, err
+				}
+
+				row, rowTypes, emptyCells = nil, nil, 0
+			case "table-cell", "covered-table-cell":
+				if sheet == nil {
+					return nil, Error{ErrorTypeParsing, "SpreadsheetODS", "Cell encountered outside of a table"}
+				}
+
+				repeat, err := odsRepeat(t, "number-columns-repeated")
+
+				if err != nil {
+					return nil, err
+				}
+
+				value, cellType, err := odsReadCell(decoder, t)
+
+				if err != nil {
+					return nil, err
+				}
+
+				//empty cells are only added once data follows them, as
+				//applications pad rows up to the maximum number of columns
+				if len(value) == 0 {
+					emptyCells += repeat
+					continue
+				}
+
+				for ; emptyCells > 0; emptyCells-- {
+					row = append(row, "")
+					rowTypes = append(rowTypes, CellTypeString)
+				}
+
+				for i := 0; i < repeat; i++ {
+					row = append(row, value)
+					rowTypes = append(rowTypes, cellType)
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != odsNamespaceTable {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "table":
+				sheet = nil
+			case "table-row":
+				//empty rows are only added once data follows them
+				if len(row) == 0 {
+					emptyRows += rowRepeat
+					continue
+				}
+
+				for ; emptyRows > 0; emptyRows-- {
+					sheet.data = append(sheet.data, []string{})
+					sheet.types = append(sheet.types, []CellType{})
+				}
+
+				for i := 0; i < rowRepeat; i++ {
+					sheet.data = append(sheet.data, append([]string(nil), row...))
+					sheet.types = append(sheet.types, append([]CellType(nil), rowTypes...))
+				}
+			}
+		}
+	}
+
+	return sheets, nil
+}
+
+//odsReadDocument reads all sheets from an OpenDocument spreadsheet
+func odsReadDocument(filename string) ([]odsSheet, error) {
+	archive, err := zip.OpenReader(filename)
+
+	if err != nil {
+		return nil, Error{ErrorTypeLoading, "SpreadsheetODS", "Failed to open the document"}
+	}
+
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+
+		reader, err := file.Open()
+
+		if err != nil {
+			return nil, Error{ErrorTypeLoading, "SpreadsheetODS", "Failed to open the document content"}
+		}
+
+		sheets, err := odsReadContent(reader)
+		reader.Close()
+		return sheets, err
+	}
+
+	return nil, Error{ErrorTypeParsing, "SpreadsheetODS", "Missing part 'content.xml'"}
+}
+
+//The static parts of a written document
+const (
+	odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2"><manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/><manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/></manifest:manifest>`
+	odsContentHead = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2"><office:body><office:spreadsheet>`
+	odsContentTail = `</office:spreadsheet></office:body></office:document-content>`
+)
+
+//odsWriteDocument writes the sheets to a new document. The mimetype is stored
+//uncompressed as the first entry of the archive, as required by the
+//specification.
+func odsWriteDocument(filename string, sheets []odsSheet) error {
+	if len(sheets) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetODS", "A document requires at least one sheet"}
+	}
+
+	//build the content in memory first, so an invalid value does not leave a
+	//half-written file behind
+	var content bytes.Buffer
+	content.WriteString(odsContentHead)
+
+	for _, sheet := range sheets {
+		//determine the widest row, as all rows are required to have the same
+		//number of cells
+		width := 1
+
+		for _, row := range sheet.data {
+			if len(row) > width {
+				width = len(row)
+			}
+		}
+
+		content.WriteString(`<table:table table:name="`)
+		xml.EscapeText(&content, []byte(sheet.name))
+		content.WriteString(`"><table:table-column table:number-columns-repeated="` + strconv.Itoa(width) + `"/>`)
+
+		for r, row := range sheet.data {
+			content.WriteString(`<table:table-row>`)
+
+			for c, value := range row {
+				cellType := inferCellType(value)
+
+				if r < len(sheet.types) && c < len(sheet.types[r]) {
+					cellType = sheet.types[r][c]
+				}
+
+				location := "row " + strconv.Itoa(r) + ", column " + strconv.Itoa(c)
+
+				switch cellType {
+				case CellTypeNumber:
+					if !validCellNumber(value) {
+						return Error{ErrorTypeSaving, "SpreadsheetODS", "Invalid number at " + location}
+					}
+
+					content.WriteString(`<table:table-cell office:value-type="float" office:value="` + value + `">`)
+				case CellTypeBool:
+					b, err := strconv.ParseBool(value)
+
+					if err != nil {
+						return Error{ErrorTypeSaving, "SpreadsheetODS", "Invalid boolean at " + location}
+					}
+
+					value = strconv.FormatBool(b)
+					content.WriteString(`<table:table-cell office:value-type="boolean" office:boolean-value="` + value + `">`)
+					value = strings.ToUpper(value)
+				case CellTypeDate:
+					t, err := parseCellTime(value)
+
+					if err != nil {
+						return Error{ErrorTypeSaving, "SpreadsheetODS", "Invalid date at " + location}
+					}
+
+					date := t.Format(CellDateLayout)
+
+					if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+						date = t.Format("2006-01-02T15:04:05")
+					}
+
+					content.WriteString(`<table:table-cell office:value-type="date" office:date-value="` + date + `">`)
+				default:
+					if len(value) == 0 {
+						content.WriteString(`<table:table-cell/>`)
+						continue
+					}
+
+					content.WriteString(`<table:table-cell office:value-type="string">`)
+				}
+
+				//write the displayed text, one paragraph per line
+				for _, line := range strings.Split(value, "\n") {
+					content.WriteString(`<text:p>`)
+					xml.EscapeText(&content, []byte(line))
+					content.WriteString(`</text:p>`)
+				}
+
+				content.WriteString(`</table:table-cell>`)
+			}
+
+			if len(row) < width {
+				content.WriteString(`<table:table-cell table:number-columns-repeated="` + strconv.Itoa(width-len(row)) + `"/>`)
+			}
+
+			content.WriteString(`</table:table-row>`)
+		}
+
+		content.WriteString(`</table:table>`)
+	}
+
+	content.WriteString(odsContentTail)
+
+	//write all parts to the archive
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetODS", "Failed to create/open file for writing"}
+	}
+
+	archive := zip.NewWriter(file)
+	parts := [...]struct {
+		name   string
+		method uint16
+		data   []byte
+	}{
+		{"mimetype", zip.Store, []byte(odsMimeType)},
+		{"META-INF/manifest.xml", zip.Deflate, []byte(odsManifest)},
+		{"content.xml", zip.Deflate, content.Bytes()},
+	}
+
+	for _, part := range parts {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: part.method})
+
+		if err == nil {
+			_, err = writer.Write(part.data)
+		}
+
+		if err != nil {
+			archive.Close()
+			file.Close()
+			return Error{ErrorTypeSaving, "SpreadsheetODS", "Failed to write part '" + part.name + "'"}
+		}
+	}
+
+	err = archive.Close()
+
+	if err != nil {
+		file.Close()
+		return Error{ErrorTypeSaving, "SpreadsheetODS", "Failed to finish the document archive"}
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetODS", "Failed to close the file after saving"}
+	}
+
+	return nil
+}
+
+//ODSSheetNames returns the names of all sheets within the specified
+//OpenDocument spreadsheet, in the order in which they appear in the document.
+func ODSSheetNames(filename string) ([]string, error) {
+	sheets, err := odsReadDocument(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sheets))
+
+	for _, sheet := range sheets {
+		names = append(names, sheet.name)
+	}
+
+	return names, nil
+}
+
+//The SpreadsheetODS type represents a single sheet within an OpenDocument
+//spreadsheet (.ods file). It implements the Spreadsheeter interface. All
+//values are stored as strings, the Types field holds the type of each value.
+//Dates are stored using the CellDateLayout or CellDateTimeLayout layouts,
+//booleans as TRUE or FALSE. New instances should be created using the
+//NewSpreadsheetODS(...) function.
+type SpreadsheetODS struct {
+	Filename   string
+	Sheet      string //name of the sheet to load and save, takes precedence over SheetIndex
+	SheetIndex int    //zero-based index of the sheet to load if Sheet is empty
+	Data       [][]string
+	Types      [][]CellType
+}
+
+//NewSpreadsheetODS will create a new instance of the SpreadsheetODS type and
+//return its pointer. The sheet name is used to select the sheet to load, and is
+//used as the name of the sheet when saving. If the sheet name is empty then the
+//first sheet will be loaded.
+func NewSpreadsheetODS(sheet string) *SpreadsheetODS {
+	return &SpreadsheetODS{"", sheet, 0, nil, nil}
+}
+
+//Load will load a single sheet from a document into the SpreadsheetODS
+//instance, replacing any previously loaded data. The sheet is selected by the
+//Sheet field, or by the SheetIndex field if the Sheet field is empty. If an
+//empty filename is specified then the filename from the previous Load(...) call
+//will be used.
+func (so *SpreadsheetODS) Load(filename string) error {
+	//check if a valid filename exists
+	if len(filename) == 0 {
+		if len(so.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetODS", "No filename specified to load"}
+		}
+
+		filename = so.Filename
+	} else {
+		so.Filename = filename
+	}
+
+	sheets, err := odsReadDocument(filename)
+
+	if err != nil {
+		return err
+	}
+
+	for index, sheet := range sheets {
+		if (len(so.Sheet) != 0 && sheet.name == so.Sheet) || (len(so.Sheet) == 0 && index == so.SheetIndex) {
+			so.Sheet = sheet.name
+			so.SheetIndex = index
+			so.Data = sheet.data
+			so.Types = sheet.types
+			return nil
+		}
+	}
+
+	return Error{ErrorTypeNotFound, "SpreadsheetODS", "The selected sheet does not exist in the document"}
+}
+
+//Save will save the current contents from the SpreadsheetODS type to a new
+//document containing a single sheet. A filename can be specified, if an empty
+//filename is specified then the filename used for the last call to the
+//Load(...) function is used.
+func (so *SpreadsheetODS) Save(filename string) error {
+	//check if a filename is specified
+	if len(filename) == 0 {
+		if len(so.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetODS", "No filename specified to save"}
+		}
+
+		filename = so.Filename
+	}
+
+	name := so.Sheet
+
+	if len(name) == 0 {
+		name = "Sheet1"
+	}
+
+	return odsWriteDocument(filename, []odsSheet{{name, so.Data, so.Types}})
+}
+
+//Set will set a specifed value at the location of the specified row and column.
+//Intermediate rows and columns will be created if the specified location does
+//not yet exist. Numeric values are stored as numbers, all other values as
+//strings. Use SetTyped(...) to store booleans and dates.
+func (so *SpreadsheetODS) Set(row, col int, value string) error {
+	return so.SetTyped(row, col, value, inferCellType(value))
+}
+
+//SetTyped will set a specified value of the specified type at the location of
+//the specified row and column. Intermediate rows and columns will be created if
+//the specified location does not yet exist.
+func (so *SpreadsheetODS) SetTyped(row, col int, value string, cellType CellType) error {
+	if row < 0 || col < 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetODS", "Negative row or column specified"}
+	}
+
+	//append non-existant rows
+	for i := len(so.Data); i <= row; i++ {
+		so.Data = append(so.Data, []string{})
+	}
+
+	for i := len(so.Types); i < len(so.Data); i++ {
+		so.Types = append(so.Types, []CellType{})
+	}
+
+	//append non-existant columns
+	for i := len(so.Data[row]); i <= col; i++ {
+		so.Data[row] = append(so.Data[row], "")
+	}
+
+	for i := len(so.Types[row]); i < len(so.Data[row]); i++ {
+		so.Types[row] = append(so.Types[row], CellTypeString)
+	}
+
+	//set specified value
+	so.Data[row][col] = value
+	so.Types[row][col] = cellType
+	return nil
+}
+
+//Get will retrieve a value from the location of the specified row and column.
+//In case the location does not exist within the current bounds of the
+//spreadsheet then the function will return false.
+func (so *SpreadsheetODS) Get(row, col int) (string, bool) {
+	if row < 0 || row >= len(so.Data) {
+		return "", false
+	}
+
+	if col < 0 || col >= len(so.Data[row]) {
+		return "", false
+	}
+
+	return so.Data[row][col], true
+}
+
+//GetType will retrieve the type of the value at the location of the specified
+//row and column. In case the location does not exist the function will return
+//false.
+func (so *SpreadsheetODS) GetType(row, col int) (CellType, bool) {
+	if _, ok := so.Get(row, col); !ok {
+		return CellTypeString, false
+	}
+
+	if row >= len(so.Types) || col >= len(so.Types[row]) {
+		return inferCellType(so.Data[row][col]), true
+	}
+
+	return so.Types[row][col], true
+}
+
+//See Get(...), includes a conversion to int. In case the conversion fails the
+//error will be non-nil
+func (so *SpreadsheetODS) GetInt(row, col int) (int, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to int
+		result, err := strconv.Atoi(str)
+		return result, true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to uint. In case the conversion fails the
+//error will be non-nil
+func (so *SpreadsheetODS) GetUint(row, col int) (uint, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to uint
+		result, err := strconv.ParseUint(str, 10, strconv.IntSize)
+		return uint(result), true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float32. In case the conversion fails the
+//error will be non-nil
+func (so *SpreadsheetODS) GetFloat32(row, col int) (float32, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float32
+		result, err := strconv.ParseFloat(str, 32)
+		return float32(result), true, err
+	}
+
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float64. In case the conversion fails the
+//error will be non-nil
+func (so *SpreadsheetODS) GetFloat64(row, col int) (float64, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float64
+		result, err := strconv.ParseFloat(str, 64)
+		return result, true, err
+	}
+
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to bool. In case the conversion fails the
+//error will be non-nil
+func (so *SpreadsheetODS) GetBool(row, col int) (bool, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to bool
+		result, err := strconv.ParseBool(str)
+		return result, true, err
+	}
+
+	return false, false, nil
+}
+
+//See Get(...), includes a conversion to time.Time. In case the conversion fails
+//the error will be non-nil
+func (so *SpreadsheetODS) GetTime(row, col int) (time.Time, bool, error) {
+	//use Get(...)
+	str, ok := so.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to time.Time
+		result, err := parseCellTime(str)
+		return result, true, err
+	}
+
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,165 @@
+package fio
+
+import (
+	"archive/zip"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testFilenameSpreadsheetODS = "test_file.ods"
+
+func TestSpreadsheetODS(t *testing.T) {
+	so := NewSpreadsheetODS("Data")
+
+	for row := 0; row < 20; row++ {
+		so.Set(row, 0, "name <"+strconv.Itoa(row)+">\nsecond  line")
+		so.Set(row, 1, strconv.Itoa(row*3))
+		so.SetTyped(row, 2, strconv.FormatBool(row%2 == 0), CellTypeBool)
+		so.SetTyped(row, 3, "2024-02-"+strconv.Itoa(10+row), CellTypeDate)
+		so.SetTyped(row, 4, "2024-02-10 13:"+strconv.Itoa(10+row)+":05", CellTypeDate)
+	}
+
+	so.Set(25, 30, "far away")
+
+	err := so.Save(testFilenameSpreadsheetODS)
+
+	if err != nil {
+		t.Fatalf("Failed to write to file, error: %s\n", err.Error())
+	}
+
+	defer os.Remove(testFilenameSpreadsheetODS)
+
+	//the mimetype must be the first, uncompressed entry
+	archive, err := zip.OpenReader(testFilenameSpreadsheetODS)
+
+	if err != nil {
+		t.Fatalf("Failed to open the written document, error: %s\n", err.Error())
+	}
+
+	if archive.File[0].Name != "mimetype" || archive.File[0].Method != zip.Store {
+		t.Errorf("Expected an uncompressed mimetype as first entry\n")
+	}
+
+	archive.Close()
+
+	//reload and check all values and types
+	so2 := NewSpreadsheetODS("Data")
+	err = so2.Load(testFilenameSpreadsheetODS)
+
+	if err != nil {
+		t.Fatalf("Failed to reload the file, error: %s\n", err.Error())
+	}
+
+	for row := 0; row < 20; row++ {
+		for col := 0; col < 5; col++ {
+			expected, _ := so.Get(row, col)
+			expectedType, _ := so.GetType(row, col)
+			value, ok := so2.Get(row, col)
+			valueType, _ := so2.GetType(row, col)
+
+			if col == 2 {
+				//booleans are normalized to TRUE and FALSE
+				b, _, _ := so.GetBool(row, col)
+				expected = cellBoolFalse
+
+				if b {
+					expected = cellBoolTrue
+				}
+			}
+
+			if !ok || value != expected || valueType != expectedType {
+				t.Errorf("'%s' (type %d) [r:%d, c:%d] != '%s' (type %d)\n", value, valueType, row, col, expected, expectedType)
+			}
+		}
+	}
+
+	if value, _ := so2.Get(25, 30); value != "far away" {
+		t.Errorf("'%s' [r:25, c:30] != 'far away'\n", value)
+	}
+}
+
+func TestSpreadsheetODSRepeated(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet>
+<table:table table:name="First"><table:table-row><table:table-cell office:value-type="string"><text:p>first</text:p></table:table-cell></table:table-row></table:table>
+<table:table table:name="Second">
+<table:table-row><table:table-cell table:number-columns-repeated="2" office:value-type="float" office:value="1.5"><text:p>1,50</text:p></table:table-cell><table:table-cell table:number-columns-repeated="3"/><table:table-cell office:value-type="string"><text:p>a<text:s text:c="2"/>b</text:p><office:annotation><text:p>note</text:p></office:annotation></table:table-cell><table:table-cell table:number-columns-repeated="16378"/></table:table-row>
+<table:table-row table:number-rows-repeated="2"><table:table-cell/></table:table-row>
+<table:table-row table:number-rows-repeated="3"><table:table-cell office:value-type="boolean" office:boolean-value="false"><text:p>FALSE</text:p></table:table-cell><table:table-cell office:value-type="date" office:date-value="2020-05-17T08:30:00"><text:p>17-05-20</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`
+
+	file, err := os.Create(testFilenameSpreadsheetODS)
+
+	if err != nil {
+		t.Fatalf("Failed to create test document\n")
+	}
+
+	defer os.Remove(testFilenameSpreadsheetODS)
+	archive := zip.NewWriter(file)
+	writer, _ := archive.Create("content.xml")
+	writer.Write([]byte(content))
+	archive.Close()
+	file.Close()
+
+	names, err := ODSSheetNames(testFilenameSpreadsheetODS)
+
+	if err != nil || len(names) != 2 || names[0] != "First" || names[1] != "Second" {
+		t.Errorf("Unexpected sheet names %v (error: %v)\n", names, err)
+	}
+
+	so := NewSpreadsheetODS("")
+	so.SheetIndex = 1
+	err = so.Load(testFilenameSpreadsheetODS)
+
+	if err != nil {
+		t.Fatalf("Failed to load the document, error: %s\n", err.Error())
+	}
+
+	if len(so.Data) != 6 || len(so.Data[0]) != 6 || len(so.Data[1]) != 0 || len(so.Data[3]) != 2 {
+		t.Fatalf("Unexpected dimensions of the repeated rows and columns\n")
+	}
+
+	expected := [...]struct {
+		row, col int
+		value    string
+		cellType CellType
+	}{
+		{0, 0, "1.5", CellTypeNumber},
+		{0, 1, "1.5", CellTypeNumber},
+		{0, 4, "", CellTypeString},
+		{0, 5, "a  b", CellTypeString},
+		{3, 0, "FALSE", CellTypeBool},
+		{5, 1, "2020-05-17 08:30:00", CellTypeDate},
+	}
+
+	for _, e := range expected {
+		value, _ := so.Get(e.row, e.col)
+		valueType, _ := so.GetType(e.row, e.col)
+
+		if value != e.value || valueType != e.cellType {
+			t.Errorf("'%s' (type %d) [r:%d, c:%d] != '%s' (type %d)\n", value, valueType, e.row, e.col, e.value, e.cellType)
+		}
+	}
+}
+
+func TestSpreadsheetODSSpaces(t *testing.T) {
+	cell := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet>
+<table:table table:name="Sheet"><table:table-row><table:table-cell office:value-type="string"><text:p>a<text:s text:c="%s"/>b</text:p></table:table-cell></table:table-row></table:table>
+</office:spreadsheet></office:body></office:document-content>`
+
+	for _, count := range []string{"-1", "0", "x", "1000000000"} {
+		if _, err := odsReadContent(strings.NewReader(strings.Replace(cell, "%s", count, 1))); err == nil || err.(Error).t != ErrorTypeParsing {
+			t.Errorf("Expected space count '%s' to fail with a parsing error, got %v\n", count, err)
+		}
+	}
+
+	sheets, err := odsReadContent(strings.NewReader(strings.Replace(cell, "%s", "3", 1)))
+
+	if err != nil || len(sheets) != 1 || sheets[0].data[0][0] != "a   b" {
+		t.Errorf("Expected 'a   b' (error: %v)\n", err)
+	}
+}
@@ -0,0 +1,668 @@
+package fio
+
+import (
+	"bufio"
+	"bytes"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//TableHeader is the type used to specify if the first row of a spreadsheet is
+//rendered as the header of a table
+type TableHeader byte
+
+//The various header modes to use in conjunction with the TableHeader type
+const (
+	TableHeaderAuto     TableHeader = iota //detect if the first row looks like a header
+	TableHeaderFirstRow                    //the first row is always the header
+	TableHeaderNone                        //the table has no header
+)
+
+//TableAlign is the type used to specify the alignment of a column within a
+//rendered table
+type TableAlign byte
+
+//The various alignments to use in conjunction with the TableAlign type
+const (
+	TableAlignAuto   TableAlign = iota //right-align numeric columns, left-align all others
+	TableAlignLeft                     //left-align the column
+	TableAlignRight                    //right-align the column
+	TableAlignCenter                   //center the column
+)
+
+//TableOptions contains the options used while rendering a spreadsheet as a
+//table. The zero value detects the header, aligns numeric columns to the right
+//and does not truncate any cells.
+type TableOptions struct {
+	Header   TableHeader
+	Align    []TableAlign //alignment per column, missing columns use TableAlignAuto
+	MaxWidth int          //maximum display width of a cell, wider cells are truncated. Zero disables truncation
+}
+
+//tableEllipsis is appended to truncated cells
+const tableEllipsis = "…"
+
+//runeWidth returns the number of columns a rune occupies when displayed in a
+//terminal or fixed-width font. Wide east asian characters and emoji occupy two
+//columns, combining marks and control characters none.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0x303E, r >= 0x3041 && r <= 0x33FF,
+		r >= 0x3400 && r <= 0x4DBF, r >= 0x4E00 && r <= 0x9FFF, r >= 0xA000 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3, r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6, r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF, r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+
+	return 1
+}
+
+//displayWidth returns the number of columns a string occupies when displayed
+func displayWidth(s string) int {
+	width := 0
+
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+//truncateWidth shortens a string to the specified display width, ending it
+//with an ellipsis if it had to be shortened
+func truncateWidth(s string, maxWidth int) string {
+	if maxWidth <= 0 || displayWidth(s) <= maxWidth {
+		return s
+	}
+
+	var buffer bytes.Buffer
+	width := displayWidth(tableEllipsis)
+
+	for _, r := range s {
+		if width+runeWidth(r) > maxWidth {
+			break
+		}
+
+		buffer.WriteRune(r)
+		width += runeWidth(r)
+	}
+
+	buffer.WriteString(tableEllipsis)
+	return buffer.String()
+}
+
+//padWidth pads a string with spaces up to the specified display width using
+//the specified alignment
+func padWidth(s string, width int, align TableAlign) string {
+	padding := width - displayWidth(s)
+
+	if padding <= 0 {
+		return s
+	}
+
+	switch align {
+	case TableAlignRight:
+		return strings.Repeat(" ", padding) + s
+	case TableAlignCenter:
+		return strings.Repeat(" ", padding/2) + s + strings.Repeat(" ", padding-padding/2)
+	}
+
+	return s + strings.Repeat(" ", padding)
+}
+
+//isNumeric checks if a value can be interpreted as a number
+func isNumeric(value string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return err == nil
+}
+
+//tableLayout is a spreadsheet prepared for rendering: the header is seperated
+//from the body, all rows have the same number of columns and the alignment of
+//every column is known
+type tableLayout struct {
+	header []string
+	body   [][]string
+	align  []TableAlign
+}
+
+//newTableLayout prepares the rows of a spreadsheet for rendering. The escape
+//function is applied to every cell after it is truncated, so no escape
+//sequences are cut in half.
+func newTableLayout(s Spreadsheeter, options TableOptions, escape func(string) string) tableLayout {
+	data := spreadsheetData(s)
+	layout := tableLayout{}
+
+	//determine if the first row is a header
+	hasHeader := options.Header == TableHeaderFirstRow
+
+	if options.Header == TableHeaderAuto && len(data) > 1 && len(data[0]) != 0 {
+		hasHeader = true
+		seen := make(map[string]bool)
+
+		for _, value := range data[0] {
+			if len(strings.TrimSpace(value)) == 0 || isNumeric(value) || seen[value] {
+				hasHeader = false
+				break
+			}
+
+			seen[value] = true
+		}
+	}
+
+	if hasHeader && len(data) != 0 {
+		layout.header = data[0]
+		data = data[1:]
+	}
+
+	//determine the number of columns
+	columns := len(layout.header)
+
+	for _, row := range data {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	//determine the alignment of every column
+	layout.align = make([]TableAlign, columns)
+
+	for col := range layout.align {
+		if col < len(options.Align) && options.Align[col] != TableAlignAuto {
+			layout.align[col] = options.Align[col]
+			continue
+		}
+
+		numeric, empty := true, true
+
+		for _, row := range data {
+			if col < len(row) && len(strings.TrimSpace(row[col])) != 0 {
+				empty = false
+				numeric = numeric && isNumeric(row[col])
+			}
+		}
+
+		layout.align[col] = TableAlignLeft
+
+		if numeric && !empty {
+			layout.align[col] = TableAlignRight
+		}
+	}
+
+	//truncate and escape all cells, padding the rows to the same width
+	prepare := func(row []string) []string {
+		result := make([]string, columns)
+
+		for col := range row {
+			result[col] = escape(truncateWidth(row[col], options.MaxWidth))
+		}
+
+		return result
+	}
+
+	if layout.header != nil {
+		layout.header = prepare(layout.header)
+	}
+
+	for _, row := range data {
+		layout.body = append(layout.body, prepare(row))
+	}
+
+	return layout
+}
+
+//widths returns the maximum display width of every column
+func (tl tableLayout) widths(minimum int) []int {
+	widths := make([]int, len(tl.align))
+
+	for col := range widths {
+		widths[col] = minimum
+
+		if tl.header != nil && displayWidth(tl.header[col]) > widths[col] {
+			widths[col] = displayWidth(tl.header[col])
+		}
+
+		for _, row := range tl.body {
+			if displayWidth(row[col]) > widths[col] {
+				widths[col] = displayWidth(row[col])
+			}
+		}
+	}
+
+	return widths
+}
+
+//markdownEscape escapes the characters that would break a Markdown table cell
+func markdownEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+//RenderMarkdown writes the spreadsheet as a GitHub-flavoured Markdown table. As
+//Markdown tables require a header, a table without a header is written with an
+//empty header row. To render a SpreadsheetDelim instance, wrap it in a
+//SpreadsheetDelimSheet.
+func RenderMarkdown(w io.Writer, s Spreadsheeter, options TableOptions) error {
+	layout := newTableLayout(s, options, markdownEscape)
+	widths := layout.widths(3)
+	writer := bufio.NewWriter(w)
+
+	writeRow := func(row []string) {
+		writer.WriteString("|")
+
+		for col, value := range row {
+			writer.WriteString(" " + padWidth(value, widths[col], layout.align[col]) + " |")
+		}
+
+		writer.WriteString("\n")
+	}
+
+	header := layout.header
+
+	if header == nil {
+		header = make([]string, len(layout.align))
+	}
+
+	writeRow(header)
+
+	//write the delimiter row, which also holds the alignment
+	writer.WriteString("|")
+
+	for col, align := range layout.align {
+		dashes := []byte(strings.Repeat("-", widths[col]))
+
+		//only explicitly left-aligned columns are marked, as left is the default
+		if align == TableAlignCenter || (col < len(options.Align) && options.Align[col] == TableAlignLeft) {
+			dashes[0] = ':'
+		}
+
+		if align == TableAlignCenter || align == TableAlignRight {
+			dashes[len(dashes)-1] = ':'
+		}
+
+		writer.WriteString(" " + string(dashes) + " |")
+	}
+
+	writer.WriteString("\n")
+
+	for _, row := range layout.body {
+		writeRow(row)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "RenderMarkdown", "Failed to write the table"}
+	}
+
+	return nil
+}
+
+//RenderHTML writes the spreadsheet as an HTML table. All values are escaped,
+//newlines within values are written as line breaks. To render a
+//SpreadsheetDelim instance, wrap it in a SpreadsheetDelimSheet.
+func RenderHTML(w io.Writer, s Spreadsheeter, options TableOptions) error {
+	//the values are escaped while writing, after the layout is determined
+	layout := newTableLayout(s, options, func(value string) string { return value })
+	writer := bufio.NewWriter(w)
+
+	writeRow := func(row []string, tag string) {
+		writer.WriteString("<tr>")
+
+		for col, value := range row {
+			writer.WriteString("<" + tag)
+
+			switch layout.align[col] {
+			case TableAlignRight:
+				writer.WriteString(` style="text-align: right"`)
+			case TableAlignCenter:
+				writer.WriteString(` style="text-align: center"`)
+			}
+
+			value = html.EscapeString(value)
+			value = strings.ReplaceAll(value, "\r\n", "<br>")
+			writer.WriteString(">" + strings.ReplaceAll(value, "\n", "<br>") + "</" + tag + ">")
+		}
+
+		writer.WriteString("</tr>\n")
+	}
+
+	writer.WriteString("<table>\n")
+
+	if layout.header != nil {
+		writer.WriteString("<thead>\n")
+		writeRow(layout.header, "th")
+		writer.WriteString("</thead>\n")
+	}
+
+	writer.WriteString("<tbody>\n")
+
+	for _, row := range layout.body {
+		writeRow(row, "td")
+	}
+
+	writer.WriteString("</tbody>\n</table>\n")
+
+	if err := writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "RenderHTML", "Failed to write the table"}
+	}
+
+	return nil
+}
+
+//RenderText writes the spreadsheet as a plain-text table in which the columns
+//are aligned using spaces, taking wide characters into account. The header is
+//underlined using dashes. To render a SpreadsheetDelim instance, wrap it in a
+//SpreadsheetDelimSheet.
+func RenderText(w io.Writer, s Spreadsheeter, options TableOptions) error {
+	//newlines would break the alignment
+	escape := func(value string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(value, "\r\n", " "), "\n", " ")
+	}
+
+	layout := newTableLayout(s, options, escape)
+	widths := layout.widths(0)
+	writer := bufio.NewWriter(w)
+
+	writeRow := func(row []string) {
+		var buffer bytes.Buffer
+
+		for col, value := range row {
+			if col != 0 {
+				buffer.WriteString("  ")
+			}
+
+			buffer.WriteString(padWidth(value, widths[col], layout.align[col]))
+		}
+
+		writer.WriteString(strings.TrimRight(buffer.String(), " ") + "\n")
+	}
+
+	if layout.header != nil {
+		writeRow(layout.header)
+		underline := make([]string, len(widths))
+
+		for col, width := range widths {
+			underline[col] = strings.Repeat("-", width)
+		}
+
+		writeRow(underline)
+	}
+
+	for _, row := range layout.body {
+		writeRow(row)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return Error{ErrorTypeSaving, "RenderText", "Failed to write the table"}
+	}
+
+	return nil
+}
+
+//markdownSplitRow splits a Markdown table row into its cells, taking escaped
+//pipe characters into account
+func markdownSplitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell bytes.Buffer
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && (line[i+1] == '|' || line[i+1] == '\\'):
+			i++
+			cell.WriteByte(line[i])
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	cells = append(cells, cell.String())
+
+	for i := range cells {
+		cells[i] = strings.ReplaceAll(strings.TrimSpace(cells[i]), "<br>", "\n")
+	}
+
+	return cells
+}
+
+//markdownIsDelimiterRow checks if the cells form the delimiter row that
+//seperates the header from the body of a Markdown table
+func markdownIsDelimiterRow(cells []string) bool {
+	for _, cell := range cells {
+		cell = strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+
+		if len(cell) == 0 || strings.Trim(cell, "-") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+//ParseMarkdownTable reads the first Markdown table from the reader and writes
+//its rows, starting with the header, to the writer. Lines before the table are
+//ignored, the table ends at the first line that is not part of it. An empty
+//header row, as written by RenderMarkdown(...) for tables without a header, is
+//not written. Use a SpreadsheetDelim instance as writer to load the table.
+func ParseMarkdownTable(r io.Reader, w RowWriter) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024*1024)
+	var header []string
+	state := 0 //0: before the table, 1: header read, 2: in the body
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if !strings.Contains(line, "|") {
+			if state == 0 {
+				continue
+			}
+
+			break
+		}
+
+		cells := markdownSplitRow(line)
+
+		switch state {
+		case 0:
+			header, state = cells, 1
+		case 1:
+			if !markdownIsDelimiterRow(cells) {
+				//not a table after all, this line might be the header
+				header = cells
+				continue
+			}
+
+			if strings.Join(header, "") != "" {
+				if err := w.WriteRow(header); err != nil {
+					return err
+				}
+			}
+
+			state = 2
+		case 2:
+			if err := w.WriteRow(cells); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Error{ErrorTypeLoading, "ParseMarkdownTable", "Failed to read the table"}
+	}
+
+	if state != 2 {
+		return Error{ErrorTypeNotFound, "ParseMarkdownTable", "No Markdown table found"}
+	}
+
+	return nil
+}
+
+//htmlTagEnd returns the index of the '>' ending the tag at the start of the
+//text, skipping quoted attribute values, or -1 if the tag is not ended
+func htmlTagEnd(text string) int {
+	var quote byte
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '"' || text[i] == '\'':
+			quote = text[i]
+		case text[i] == '>':
+			return i
+		}
+	}
+
+	return -1
+}
+
+//ParseHTMLTable reads the first HTML table from the reader and writes its rows
+//to the writer. Header and data cells are both treated as values, tags within
+//cells are removed and line breaks become newlines. The contents of tables
+//nested within a cell are part of the value of that cell. Use a SpreadsheetDelim
+//instance as writer to load the table.
+func ParseHTMLTable(r io.Reader, w RowWriter) error {
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "ParseHTMLTable", "Failed to read the table"}
+	}
+
+	text := string(content)
+	var row []string
+	var cell bytes.Buffer
+	inRow, inCell, found := false, false, false
+	depth := 0 //the nesting depth of tables, only the rows of the outer table are read
+
+	endCell := func() {
+		if inCell {
+			row = append(row, strings.TrimSpace(html.UnescapeString(cell.String())))
+			cell.Reset()
+			inCell = false
+		}
+	}
+
+	endRow := func() error {
+		endCell()
+
+		if inRow {
+			inRow = false
+			return w.WriteRow(row)
+		}
+
+		return nil
+	}
+
+	for i := 0; i < len(text); {
+		if text[i] != '<' {
+			next := strings.IndexByte(text[i:], '<')
+
+			if next == -1 {
+				next = len(text) - i
+			}
+
+			if inCell {
+				//collapse whitespace like a browser would
+				data := text[i : i+next]
+				spaced := cell.Len() != 0 && cell.Bytes()[cell.Len()-1] == ' '
+
+				if unicode.IsSpace(rune(data[0])) && !spaced {
+					cell.WriteByte(' ')
+				}
+
+				cell.WriteString(strings.Join(strings.Fields(data), " "))
+
+				if unicode.IsSpace(rune(data[len(data)-1])) && len(strings.TrimSpace(data)) != 0 {
+					cell.WriteByte(' ')
+				}
+			}
+
+			i += next
+			continue
+		}
+
+		//skip comments
+		if strings.HasPrefix(text[i:], "<!--") {
+			end := strings.Index(text[i:], "-->")
+
+			if end == -1 {
+				break
+			}
+
+			i += end + 3
+			continue
+		}
+
+		end := htmlTagEnd(text[i:])
+
+		if end == -1 {
+			break
+		}
+
+		tag := strings.ToLower(strings.Trim(text[i+1:i+end], "/ \t\r\n"))
+		closing := strings.HasPrefix(text[i+1:], "/")
+		i += end + 1
+
+		if space := strings.IndexAny(tag, " \t\r\n"); space != -1 {
+			tag = tag[:space]
+		}
+
+		switch {
+		case tag == "table" && !closing && (!found || depth != 0):
+			found = true
+			depth++
+		case tag == "table" && closing && depth > 1:
+			depth--
+		case tag == "table" && closing && depth == 1:
+			if err = endRow(); err != nil {
+				return err
+			}
+
+			return nil
+		case depth != 1:
+		case tag == "tr":
+			if err = endRow(); err != nil {
+				return err
+			}
+
+			inRow = !closing
+			row = nil
+		case tag == "td" || tag == "th":
+			endCell()
+
+			if !closing {
+				if !inRow {
+					inRow, row = true, nil
+				}
+
+				inCell = true
+			}
+		case tag == "br" && inCell:
+			cell.WriteString("\n")
+		}
+	}
+
+	if !found {
+		return Error{ErrorTypeNotFound, "ParseHTMLTable", "No HTML table found"}
+	}
+
+	return endRow()
+}
@@ -0,0 +1,124 @@
+package fio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testRenderSheet() SpreadsheetDelimSheet {
+	sd := NewSpreadsheetDelim(16, ",")
+	sd.Data = [][]string{
+		{"name", "amount", "note"},
+		{"Ann", "12.5", "a|b"},
+		{"漢字", "3", "<x> & y"},
+		{"Bob", "", "a very long note indeed"},
+	}
+
+	return SpreadsheetDelimSheet{sd}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var output bytes.Buffer
+	err := RenderMarkdown(&output, testRenderSheet(), TableOptions{MaxWidth: 10})
+
+	expected := "| name | amount | note       |\n" +
+		"| ---- | -----: | ---------- |\n" +
+		"| Ann  |   12.5 | a\\|b       |\n" +
+		"| 漢字 |      3 | <x> & y    |\n" +
+		"| Bob  |        | a very lo… |\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected Markdown output (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	//parsing the output should return the (truncated) values
+	sd := NewSpreadsheetDelim(16, ",")
+	err = ParseMarkdownTable(strings.NewReader("Some text\n\n"+output.String()+"\nMore text | not a table\n"), sd)
+
+	if err != nil {
+		t.Fatalf("Failed to parse Markdown table, error: %s\n", err.Error())
+	}
+
+	if len(sd.Data) != 4 || sd.Data[1][2] != "a|b" || sd.Data[3][2] != "a very lo…" || sd.Data[2][0] != "漢字" {
+		t.Errorf("Unexpected parsed Markdown table %q\n", sd.Data)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	var output bytes.Buffer
+	err := RenderHTML(&output, testRenderSheet(), TableOptions{Align: []TableAlign{TableAlignCenter}})
+
+	expected := "<table>\n<thead>\n" +
+		`<tr><th style="text-align: center">name</th><th style="text-align: right">amount</th><th>note</th></tr>` + "\n" +
+		"</thead>\n<tbody>\n" +
+		`<tr><td style="text-align: center">Ann</td><td style="text-align: right">12.5</td><td>a|b</td></tr>` + "\n" +
+		`<tr><td style="text-align: center">漢字</td><td style="text-align: right">3</td><td>&lt;x&gt; &amp; y</td></tr>` + "\n" +
+		`<tr><td style="text-align: center">Bob</td><td style="text-align: right"></td><td>a very long note indeed</td></tr>` + "\n" +
+		"</tbody>\n</table>\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected HTML output (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	sd := NewSpreadsheetDelim(16, ",")
+	err = ParseHTMLTable(strings.NewReader("<p>intro</p>\n"+output.String()), sd)
+
+	if err != nil {
+		t.Fatalf("Failed to parse HTML table, error: %s\n", err.Error())
+	}
+
+	original := testRenderSheet()
+
+	for row := range original.Data {
+		for col := range original.Data[row] {
+			if value, _ := sd.Get(row, col); value != original.Data[row][col] {
+				t.Errorf("'%s' [r:%d, c:%d] != '%s'\n", value, row, col, original.Data[row][col])
+			}
+		}
+	}
+
+	sd = NewSpreadsheetDelim(16, ",")
+	ParseHTMLTable(strings.NewReader("<TABLE><tr><td>a <b>bold</b>\n text<br>next</td><td>x</TD></tr></TABLE>"), sd)
+
+	if value, _ := sd.Get(0, 0); value != "a bold text\nnext" {
+		t.Errorf("Unexpected parsed HTML cell %q\n", value)
+	}
+
+	//nested tables and quoted '>' within attributes
+	sd = NewSpreadsheetDelim(16, ",")
+	err = ParseHTMLTable(strings.NewReader(`<table><tr><td title="a > b">1</td><td><table class='x>y'><tr><td>inner</td></tr></table></td></tr>`+
+		`<tr><td>2</td><td>3</td></tr></table>`), sd)
+
+	if expected := [][]string{{"1", "inner"}, {"2", "3"}}; err != nil || !reflect.DeepEqual(sd.Data, expected) {
+		t.Errorf("Unexpected nested HTML table %v, expected %v (error: %v)\n", sd.Data, expected, err)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	var output bytes.Buffer
+	err := RenderText(&output, testRenderSheet(), TableOptions{Header: TableHeaderNone})
+
+	expected := "name  amount  note\n" +
+		"Ann   12.5    a|b\n" +
+		"漢字  3       <x> & y\n" +
+		"Bob           a very long note indeed\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected text output (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+
+	output.Reset()
+	err = RenderText(&output, testRenderSheet(), TableOptions{})
+
+	expected = "name  amount  note\n" +
+		"----  ------  -----------------------\n" +
+		"Ann     12.5  a|b\n" +
+		"漢字       3  <x> & y\n" +
+		"Bob           a very long note indeed\n"
+
+	if err != nil || output.String() != expected {
+		t.Errorf("Unexpected text output (error: %v):\n%s\nexpected:\n%s\n", err, output.String(), expected)
+	}
+}
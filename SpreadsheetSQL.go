@@ -0,0 +1,1721 @@
+package fio
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//sqlValueType is the type of a value while evaluating a SQL statement
+type sqlValueType byte
+
+//The various value types to use in conjunction with the sqlValueType type
+const (
+	sqlNull   sqlValueType = iota //a missing (empty) value
+	sqlNumber                     //a floating point number
+	sqlString                     //a string
+	sqlBool                       //the result of a comparison
+)
+
+//sqlValue is a single value while evaluating a SQL statement. Numbers read
+//from a spreadsheet keep their original text in s, such that they are written
+//to the result unchanged.
+type sqlValue struct {
+	t sqlValueType
+	s string
+	f float64
+	b bool
+}
+
+//String converts the value to the string stored in a spreadsheet, NULL values
+//are empty
+func (v sqlValue) String() string {
+	switch v.t {
+	case sqlNumber:
+		if len(v.s) != 0 {
+			return v.s
+		}
+
+		return formatFloat(v.f)
+	case sqlString:
+		return v.s
+	case sqlBool:
+		if v.b {
+			return cellBoolTrue
+		}
+
+		return cellBoolFalse
+	}
+
+	return ""
+}
+
+//key converts the value to a string that is equal for equal values, for use
+//as a map key
+func (v sqlValue) key() string {
+	switch v.t {
+	case sqlNumber:
+		return "n" + formatFloat(v.f)
+	case sqlString:
+		return "s" + v.s
+	case sqlBool:
+		return "b" + v.String()
+	}
+
+	return "0"
+}
+
+//number converts the value to a number, returning false if it cannot be
+//converted
+func (v sqlValue) number() (float64, bool) {
+	switch v.t {
+	case sqlNumber:
+		return v.f, true
+	case sqlBool:
+		if v.b {
+			return 1, true
+		}
+
+		return 0, true
+	case sqlString:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.s), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+//truth converts the value to a boolean, the second boolean is false for NULL
+//values. Numbers are true if they are not zero, strings are converted to
+//numbers first.
+func (v sqlValue) truth() (bool, bool) {
+	switch v.t {
+	case sqlNull:
+		return false, false
+	case sqlBool:
+		return v.b, true
+	}
+
+	f, _ := v.number()
+	return f != 0, true
+}
+
+//sqlBoolValue creates a boolean value
+func sqlBoolValue(b bool) sqlValue {
+	return sqlValue{t: sqlBool, b: b}
+}
+
+//sqlNumberValue creates a numeric value
+func sqlNumberValue(f float64) sqlValue {
+	return sqlValue{t: sqlNumber, f: f}
+}
+
+//sqlCompare compares two values, returning false if either value is NULL.
+//Strings are compared to each other lexically, other combinations are compared
+//as numbers if both values can be converted to numbers.
+func sqlCompare(a, b sqlValue) (int, bool) {
+	if a.t == sqlNull || b.t == sqlNull {
+		return 0, false
+	}
+
+	if a.t == sqlString && b.t == sqlString {
+		return strings.Compare(a.s, b.s), true
+	}
+
+	fa, okA := a.number()
+	fb, okB := b.number()
+
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	return strings.Compare(a.String(), b.String()), true
+}
+
+//sqlContext contains the data an expression is evaluated on: the current row
+//and, when aggregating, all rows of the current group
+type sqlContext struct {
+	row        []sqlValue
+	group      [][]sqlValue
+	grouped    bool
+	parameters []sqlValue
+}
+
+//sqlExpr is implemented by all nodes of a parsed expression
+type sqlExpr interface {
+	eval(ctx *sqlContext) (sqlValue, error)
+	children() []sqlExpr
+}
+
+//sqlLiteral is a constant value
+type sqlLiteral struct {
+	value sqlValue
+}
+
+func (l *sqlLiteral) eval(ctx *sqlContext) (sqlValue, error) {
+	return l.value, nil
+}
+
+func (l *sqlLiteral) children() []sqlExpr {
+	return nil
+}
+
+//sqlParameter is a ? placeholder, replaced by the argument at index
+type sqlParameter struct {
+	index int
+}
+
+func (p *sqlParameter) eval(ctx *sqlContext) (sqlValue, error) {
+	if p.index >= len(ctx.parameters) {
+		return sqlValue{}, Error{ErrorTypeInvalidArgument, "SQL", "No value specified for parameter " + strconv.Itoa(p.index+1)}
+	}
+
+	return ctx.parameters[p.index], nil
+}
+
+func (p *sqlParameter) children() []sqlExpr {
+	return nil
+}
+
+//sqlColumn refers to a column of a table, index is its position within the
+//rows the statement operates on once it is bound
+type sqlColumn struct {
+	table string
+	name  string
+	index int
+}
+
+func (c *sqlColumn) eval(ctx *sqlContext) (sqlValue, error) {
+	if c.index < 0 {
+		return sqlValue{}, Error{ErrorTypeInvalidArgument, "SQL", "Column '" + c.name + "' cannot be used here"}
+	}
+
+	//an empty group has no rows to take the value from
+	if ctx.row == nil {
+		return sqlValue{}, nil
+	}
+
+	return ctx.row[c.index], nil
+}
+
+func (c *sqlColumn) children() []sqlExpr {
+	return nil
+}
+
+//sqlUnary is the NOT or negation operator
+type sqlUnary struct {
+	op   string
+	expr sqlExpr
+}
+
+func (u *sqlUnary) eval(ctx *sqlContext) (sqlValue, error) {
+	value, err := u.expr.eval(ctx)
+
+	if err != nil || value.t == sqlNull {
+		return sqlValue{}, err
+	}
+
+	if u.op == "NOT" {
+		b, _ := value.truth()
+		return sqlBoolValue(!b), nil
+	}
+
+	f, err := sqlNumberOperand(value)
+	return sqlNumberValue(-f), err
+}
+
+func (u *sqlUnary) children() []sqlExpr {
+	return []sqlExpr{u.expr}
+}
+
+//sqlNumberOperand converts an operand of an arithmetic operator to a number
+func sqlNumberOperand(value sqlValue) (float64, error) {
+	f, ok := value.number()
+
+	if !ok {
+		return 0, Error{ErrorTypeInvalidArgument, "SQL", "Value '" + value.String() + "' is not a number"}
+	}
+
+	return f, nil
+}
+
+//sqlBinary is an arithmetic, comparison, concatenation or logical operator
+type sqlBinary struct {
+	op    string
+	left  sqlExpr
+	right sqlExpr
+}
+
+func (b *sqlBinary) eval(ctx *sqlContext) (sqlValue, error) {
+	left, err := b.left.eval(ctx)
+
+	if err != nil {
+		return sqlValue{}, err
+	}
+
+	//logical operators use three-valued logic and skip the right operand if
+	//the left operand decides the result
+	if b.op == "AND" || b.op == "OR" {
+		l, lok := left.truth()
+
+		if lok && l == (b.op == "OR") {
+			return sqlBoolValue(l), nil
+		}
+
+		right, err := b.right.eval(ctx)
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		r, rok := right.truth()
+
+		switch {
+		case rok && r == (b.op == "OR"):
+			return sqlBoolValue(r), nil
+		case !lok || !rok:
+			return sqlValue{}, nil
+		}
+
+		return sqlBoolValue(r), nil
+	}
+
+	right, err := b.right.eval(ctx)
+
+	if err != nil || left.t == sqlNull || right.t == sqlNull {
+		return sqlValue{}, err
+	}
+
+	switch b.op {
+	case "||":
+		return sqlValue{t: sqlString, s: left.String() + right.String()}, nil
+	case "=", "<>", "<", "<=", ">", ">=":
+		c, _ := sqlCompare(left, right)
+
+		switch b.op {
+		case "=":
+			return sqlBoolValue(c == 0), nil
+		case "<>":
+			return sqlBoolValue(c != 0), nil
+		case "<":
+			return sqlBoolValue(c < 0), nil
+		case "<=":
+			return sqlBoolValue(c <= 0), nil
+		case ">":
+			return sqlBoolValue(c > 0), nil
+		}
+
+		return sqlBoolValue(c >= 0), nil
+	}
+
+	l, err := sqlNumberOperand(left)
+
+	if err != nil {
+		return sqlValue{}, err
+	}
+
+	r, err := sqlNumberOperand(right)
+
+	if err != nil {
+		return sqlValue{}, err
+	}
+
+	switch b.op {
+	case "+":
+		return sqlNumberValue(l + r), nil
+	case "-":
+		return sqlNumberValue(l - r), nil
+	case "*":
+		return sqlNumberValue(l * r), nil
+	}
+
+	//division by zero results in NULL
+	if r == 0 {
+		return sqlValue{}, nil
+	}
+
+	if b.op == "/" {
+		return sqlNumberValue(l / r), nil
+	}
+
+	return sqlNumberValue(math.Mod(l, r)), nil
+}
+
+func (b *sqlBinary) children() []sqlExpr {
+	return []sqlExpr{b.left, b.right}
+}
+
+//sqlIsNull is the IS [NOT] NULL operator
+type sqlIsNull struct {
+	expr sqlExpr
+	not  bool
+}
+
+func (n *sqlIsNull) eval(ctx *sqlContext) (sqlValue, error) {
+	value, err := n.expr.eval(ctx)
+	return sqlBoolValue((value.t == sqlNull) != n.not), err
+}
+
+func (n *sqlIsNull) children() []sqlExpr {
+	return []sqlExpr{n.expr}
+}
+
+//sqlIn is the [NOT] IN (...) operator
+type sqlIn struct {
+	expr sqlExpr
+	list []sqlExpr
+	not  bool
+}
+
+func (in *sqlIn) eval(ctx *sqlContext) (sqlValue, error) {
+	value, err := in.expr.eval(ctx)
+
+	if err != nil || value.t == sqlNull {
+		return sqlValue{}, err
+	}
+
+	null := false
+
+	for _, expr := range in.list {
+		candidate, err := expr.eval(ctx)
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		if c, ok := sqlCompare(value, candidate); !ok {
+			null = true
+		} else if c == 0 {
+			return sqlBoolValue(!in.not), nil
+		}
+	}
+
+	if null {
+		return sqlValue{}, nil
+	}
+
+	return sqlBoolValue(in.not), nil
+}
+
+func (in *sqlIn) children() []sqlExpr {
+	return append([]sqlExpr{in.expr}, in.list...)
+}
+
+//sqlLike is the [NOT] LIKE operator
+type sqlLike struct {
+	expr    sqlExpr
+	pattern sqlExpr
+	not     bool
+}
+
+func (l *sqlLike) eval(ctx *sqlContext) (sqlValue, error) {
+	value, err := l.expr.eval(ctx)
+
+	if err != nil || value.t == sqlNull {
+		return sqlValue{}, err
+	}
+
+	pattern, err := l.pattern.eval(ctx)
+
+	if err != nil || pattern.t == sqlNull {
+		return sqlValue{}, err
+	}
+
+	match := sqlLikeMatch([]rune(strings.ToLower(value.String())), []rune(strings.ToLower(pattern.String())))
+	return sqlBoolValue(match != l.not), nil
+}
+
+func (l *sqlLike) children() []sqlExpr {
+	return []sqlExpr{l.expr, l.pattern}
+}
+
+//sqlLikeMatch matches a value to a LIKE pattern, in which '%' matches any
+//sequence of characters and '_' matches a single character. The pattern is
+//matched iteratively: on a mismatch the last '%' is retried matching one more
+//character, which takes at most quadratic time.
+func sqlLikeMatch(value, pattern []rune) bool {
+	v, p := 0, 0
+	star, next := -1, 0 //the position of the last '%' and the value position it is retried at
+
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '%':
+			star, next = p, v
+			p++
+		case p < len(pattern) && (pattern[p] == '_' || pattern[p] == value[v]):
+			v++
+			p++
+		case star >= 0:
+			next++
+			v, p = next, star+1
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '%' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+//sqlBetween is the [NOT] BETWEEN ... AND ... operator
+type sqlBetween struct {
+	expr sqlExpr
+	low  sqlExpr
+	high sqlExpr
+	not  bool
+}
+
+func (b *sqlBetween) eval(ctx *sqlContext) (sqlValue, error) {
+	var values [3]sqlValue
+
+	for i, expr := range b.children() {
+		var err error
+
+		if values[i], err = expr.eval(ctx); err != nil {
+			return sqlValue{}, err
+		}
+	}
+
+	low, okLow := sqlCompare(values[0], values[1])
+	high, okHigh := sqlCompare(values[0], values[2])
+
+	if !okLow || !okHigh {
+		return sqlValue{}, nil
+	}
+
+	return sqlBoolValue((low >= 0 && high <= 0) != b.not), nil
+}
+
+func (b *sqlBetween) children() []sqlExpr {
+	return []sqlExpr{b.expr, b.low, b.high}
+}
+
+//sqlCase is a CASE WHEN ... THEN ... ELSE ... END expression
+type sqlCase struct {
+	conditions []sqlExpr
+	results    []sqlExpr
+	otherwise  sqlExpr
+}
+
+func (c *sqlCase) eval(ctx *sqlContext) (sqlValue, error) {
+	for i, condition := range c.conditions {
+		value, err := condition.eval(ctx)
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		if b, _ := value.truth(); b {
+			return c.results[i].eval(ctx)
+		}
+	}
+
+	if c.otherwise == nil {
+		return sqlValue{}, nil
+	}
+
+	return c.otherwise.eval(ctx)
+}
+
+func (c *sqlCase) children() []sqlExpr {
+	children := append(append([]sqlExpr(nil), c.conditions...), c.results...)
+
+	if c.otherwise != nil {
+		children = append(children, c.otherwise)
+	}
+
+	return children
+}
+
+//sqlFunctionDef describes a scalar function. Unless nulls is true the function
+//returns NULL if any of its arguments is NULL, without calling call.
+type sqlFunctionDef struct {
+	min   int
+	max   int //negative for any number of arguments
+	nulls bool
+	call  func(args []sqlValue) (sqlValue, error)
+}
+
+//sqlStringValue creates a string value
+func sqlStringValue(s string) sqlValue {
+	return sqlValue{t: sqlString, s: s}
+}
+
+//sqlFunctions contains the supported scalar functions
+var sqlFunctions = map[string]sqlFunctionDef{
+	"UPPER": {1, 1, false, func(args []sqlValue) (sqlValue, error) {
+		return sqlStringValue(strings.ToUpper(args[0].String())), nil
+	}},
+	"LOWER": {1, 1, false, func(args []sqlValue) (sqlValue, error) {
+		return sqlStringValue(strings.ToLower(args[0].String())), nil
+	}},
+	"TRIM": {1, 1, false, func(args []sqlValue) (sqlValue, error) {
+		return sqlStringValue(strings.TrimSpace(args[0].String())), nil
+	}},
+	"LENGTH": {1, 1, false, func(args []sqlValue) (sqlValue, error) {
+		return sqlNumberValue(float64(utf8.RuneCountInString(args[0].String()))), nil
+	}},
+	"SUBSTR": {2, 3, false, func(args []sqlValue) (sqlValue, error) {
+		//the start is one-based, the length is optional
+		runes := []rune(args[0].String())
+		start, err := sqlNumberOperand(args[1])
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		first := int(math.Max(start, 1)) - 1
+		last := len(runes)
+
+		if len(args) == 3 {
+			length, err := sqlNumberOperand(args[2])
+
+			if err != nil {
+				return sqlValue{}, err
+			}
+
+			last = int(start) - 1 + int(length)
+		}
+
+		if last > len(runes) {
+			last = len(runes)
+		}
+
+		if last <= first {
+			return sqlStringValue(""), nil
+		}
+
+		return sqlStringValue(string(runes[first:last])), nil
+	}},
+	"REPLACE": {3, 3, false, func(args []sqlValue) (sqlValue, error) {
+		return sqlStringValue(strings.ReplaceAll(args[0].String(), args[1].String(), args[2].String())), nil
+	}},
+	"ABS": {1, 1, false, func(args []sqlValue) (sqlValue, error) {
+		f, err := sqlNumberOperand(args[0])
+		return sqlNumberValue(math.Abs(f)), err
+	}},
+	"ROUND": {1, 2, false, func(args []sqlValue) (sqlValue, error) {
+		f, err := sqlNumberOperand(args[0])
+		digits := 0.0
+
+		if err == nil && len(args) == 2 {
+			digits, err = sqlNumberOperand(args[1])
+		}
+
+		scale := math.Pow(10, math.Trunc(digits))
+		return sqlNumberValue(math.Round(f*scale) / scale), err
+	}},
+	"COALESCE": {1, -1, true, func(args []sqlValue) (sqlValue, error) {
+		for _, arg := range args {
+			if arg.t != sqlNull {
+				return arg, nil
+			}
+		}
+
+		return sqlValue{}, nil
+	}},
+	"NULLIF": {2, 2, true, func(args []sqlValue) (sqlValue, error) {
+		if c, ok := sqlCompare(args[0], args[1]); ok && c == 0 {
+			return sqlValue{}, nil
+		}
+
+		return args[0], nil
+	}},
+}
+
+//sqlAggregates contains the supported aggregate functions
+var sqlAggregates = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+//sqlFunction is a call to a scalar or aggregate function. The star is only
+//used by COUNT(*).
+type sqlFunction struct {
+	name      string
+	args      []sqlExpr
+	star      bool
+	distinct  bool
+	aggregate bool
+}
+
+//checkArguments checks the number of arguments of the function call
+func (f *sqlFunction) checkArguments(pos int) error {
+	minimum, maximum := 1, 1
+
+	if !f.aggregate {
+		minimum, maximum = sqlFunctions[f.name].min, sqlFunctions[f.name].max
+	} else if f.star {
+		minimum, maximum = 0, 0
+	}
+
+	if len(f.args) < minimum || (maximum >= 0 && len(f.args) > maximum) {
+		return sqlError(pos, "Invalid number of arguments for function "+f.name)
+	}
+
+	return nil
+}
+
+func (f *sqlFunction) eval(ctx *sqlContext) (sqlValue, error) {
+	if f.aggregate {
+		return f.evalAggregate(ctx)
+	}
+
+	definition := sqlFunctions[f.name]
+	args := make([]sqlValue, len(f.args))
+
+	for i, arg := range f.args {
+		var err error
+
+		if args[i], err = arg.eval(ctx); err != nil {
+			return sqlValue{}, err
+		}
+
+		if args[i].t == sqlNull && !definition.nulls {
+			return sqlValue{}, nil
+		}
+	}
+
+	return definition.call(args)
+}
+
+//evalAggregate evaluates the argument of an aggregate function for every row of
+//the group, NULL values are skipped
+func (f *sqlFunction) evalAggregate(ctx *sqlContext) (sqlValue, error) {
+	if !ctx.grouped {
+		return sqlValue{}, Error{ErrorTypeInvalidArgument, "SQL", "Aggregate function " + f.name + " cannot be used here"}
+	}
+
+	if f.star {
+		return sqlNumberValue(float64(len(ctx.group))), nil
+	}
+
+	var values []sqlValue
+	seen := make(map[string]bool)
+
+	for _, row := range ctx.group {
+		value, err := f.args[0].eval(&sqlContext{row: row, parameters: ctx.parameters})
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		if value.t == sqlNull || (f.distinct && seen[value.key()]) {
+			continue
+		}
+
+		seen[value.key()] = true
+		values = append(values, value)
+	}
+
+	if f.name == "COUNT" {
+		return sqlNumberValue(float64(len(values))), nil
+	}
+
+	if len(values) == 0 {
+		return sqlValue{}, nil
+	}
+
+	switch f.name {
+	case "MIN", "MAX":
+		result := values[0]
+
+		for _, value := range values[1:] {
+			if c, _ := sqlCompare(value, result); (c < 0) == (f.name == "MIN") && c != 0 {
+				result = value
+			}
+		}
+
+		return result, nil
+	}
+
+	sum := 0.0
+
+	for _, value := range values {
+		number, err := sqlNumberOperand(value)
+
+		if err != nil {
+			return sqlValue{}, err
+		}
+
+		sum += number
+	}
+
+	if f.name == "AVG" {
+		return sqlNumberValue(sum / float64(len(values))), nil
+	}
+
+	return sqlNumberValue(sum), nil
+}
+
+func (f *sqlFunction) children() []sqlExpr {
+	return f.args
+}
+
+//sqlHasAggregate checks if an expression contains an aggregate function
+func sqlHasAggregate(expr sqlExpr) bool {
+	if expr == nil {
+		return false
+	}
+
+	if function, ok := expr.(*sqlFunction); ok && function.aggregate {
+		return true
+	}
+
+	for _, child := range expr.children() {
+		if sqlHasAggregate(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//sqlSchemaColumn describes a column of the rows a statement operates on
+type sqlSchemaColumn struct {
+	table string
+	name  string
+	t     sqlValueType
+}
+
+//sqlBind resolves the columns referred to within an expression to their
+//position within the schema
+func sqlBind(expr sqlExpr, schema []sqlSchemaColumn) error {
+	if expr == nil {
+		return nil
+	}
+
+	if column, ok := expr.(*sqlColumn); ok {
+		column.index = -1
+
+		for i, candidate := range schema {
+			if !strings.EqualFold(candidate.name, column.name) || (len(column.table) != 0 && !strings.EqualFold(candidate.table, column.table)) {
+				continue
+			}
+
+			if column.index >= 0 {
+				return Error{ErrorTypeInvalidArgument, "SQL", "Column name '" + column.name + "' is ambiguous"}
+			}
+
+			column.index = i
+		}
+
+		if column.index < 0 {
+			name := column.name
+
+			if len(column.table) != 0 {
+				name = column.table + "." + name
+			}
+
+			return Error{ErrorTypeNotFound, "SQL", "Column '" + name + "' does not exist"}
+		}
+	}
+
+	for _, child := range expr.children() {
+		if err := sqlBind(child, schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//SQLDatabase executes SQL queries on spreadsheets. Tables are either added by
+//name using AddTable(...), stored as files within the directory of a database
+//created using NewSQLDatabaseDir(...), or referred to by their filename as a
+//quoted string. The filenames of a database with a directory are relative to
+//that directory and may not refer to files outside of it. Files are loaded as
+//delimited spreadsheets with a header:
+//
+//	SELECT region, SUM(amount) FROM 'sales.csv' WHERE year = 2025 GROUP BY region
+//
+//The supported dialect consists of SELECT [DISTINCT] with [INNER] JOIN and LEFT
+//[OUTER] JOIN ... ON, WHERE, GROUP BY, HAVING, ORDER BY (by expression, alias
+//or position) and LIMIT ... [OFFSET ...], as well as INSERT INTO table
+//[(columns)] VALUES (...), ... to append rows. Values can be passed as ?
+//placeholders. Expressions support the usual
+//arithmetic, comparison and logical operators, || for concatenation, IS [NOT]
+//NULL, [NOT] IN, [NOT] LIKE, [NOT] BETWEEN, CASE WHEN and the functions UPPER,
+//LOWER, TRIM, LENGTH, SUBSTR, REPLACE, ABS, ROUND, COALESCE and NULLIF, as well
+//as the aggregates COUNT, SUM, AVG, MIN and MAX (with optional DISTINCT).
+//
+//The type of every column is inferred from its values: a column of which all
+//non-empty values are numbers (without meaningful leading zeroes) is numeric,
+//all other columns contain strings. Empty values are NULL. Comparisons between
+//a number and a string compare them as numbers if the string is numeric.
+type SQLDatabase struct {
+	buffer    int
+	delimeter string
+	directory string
+	extension string
+	tables    map[string]*SpreadsheetDelim
+}
+
+//NewSQLDatabase creates a new SQLDatabase instance and returns its pointer. The
+//buffer size and delimeter are used to load the files referred to by filename
+//and for the results of queries.
+func NewSQLDatabase(buffer int, delimeter string) *SQLDatabase {
+	return &SQLDatabase{buffer, delimeter, "", "", make(map[string]*SpreadsheetDelim)}
+}
+
+//NewSQLDatabaseDir creates a new SQLDatabase instance of which every file with
+//the specified extension (such as '.csv') within the directory is a table,
+//named after the file without its extension. The files are loaded whenever a
+//query refers to them, so changes to the files are visible to subsequent
+//queries. Tables added using AddTable(...) take precedence over the files.
+func NewSQLDatabaseDir(buffer int, delimeter, directory, extension string) *SQLDatabase {
+	return &SQLDatabase{buffer, delimeter, directory, extension, make(map[string]*SpreadsheetDelim)}
+}
+
+//AddTable makes the spreadsheet available to queries under the specified name,
+//which is case-insensitive. If the spreadsheet has a header the columns are
+//named after it, otherwise the columns are named c0, c1, etc. The spreadsheet
+//is not copied, so changes to it are visible to subsequent queries.
+func (db *SQLDatabase) AddTable(name string, sd *SpreadsheetDelim) error {
+	if len(name) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SQLDatabase", "No table name specified"}
+	}
+
+	if _, ok := db.tables[strings.ToLower(name)]; ok {
+		return Error{ErrorTypeExists, "SQLDatabase", "Table '" + name + "' already exists"}
+	}
+
+	db.tables[strings.ToLower(name)] = sd
+	return nil
+}
+
+//RemoveTable removes the table with the specified name
+func (db *SQLDatabase) RemoveTable(name string) error {
+	if _, ok := db.tables[strings.ToLower(name)]; !ok {
+		return Error{ErrorTypeNotFound, "SQLDatabase", "Table '" + name + "' does not exist"}
+	}
+
+	delete(db.tables, strings.ToLower(name))
+	return nil
+}
+
+//tableFile returns the filename of a table referred to by a query, or false if
+//the table is not stored as a file. Quoted filenames are relative to the
+//directory of the database, if any, and may not refer to files outside of it.
+func (db *SQLDatabase) tableFile(ref sqlTableRef) (string, bool) {
+	if ref.file && len(db.directory) == 0 {
+		return ref.name, true
+	}
+
+	if ref.file {
+		if filepath.IsAbs(ref.name) || len(filepath.VolumeName(ref.name)) != 0 || strings.HasPrefix(ref.name, "/") || strings.HasPrefix(ref.name, `\`) {
+			return "", false
+		}
+
+		for _, part := range strings.FieldsFunc(ref.name, func(r rune) bool { return r == '/' || r == '\\' }) {
+			if part == ".." {
+				return "", false
+			}
+		}
+
+		return filepath.Join(db.directory, ref.name), true
+	}
+
+	if _, ok := db.tables[strings.ToLower(ref.name)]; ok || len(db.directory) == 0 {
+		return "", false
+	}
+
+	//the name may not refer to files outside of the directory
+	if strings.ContainsAny(ref.name, `/\`) || ref.name == "." || ref.name == ".." {
+		return "", false
+	}
+
+	filename := filepath.Join(db.directory, ref.name+db.extension)
+
+	if info, err := os.Stat(filename); err != nil || info.IsDir() {
+		return "", false
+	}
+
+	return filename, true
+}
+
+//loadTable returns the schema and rows of a table referred to by a query
+func (db *SQLDatabase) loadTable(ref sqlTableRef) ([]sqlSchemaColumn, [][]sqlValue, error) {
+	sd, ok := db.tables[strings.ToLower(ref.name)]
+
+	if filename, isFile := db.tableFile(ref); isFile {
+		sd = NewSpreadsheetDelim(db.buffer, db.delimeter)
+		sd.Header = true
+
+		if err := sd.Load(filename, 0, 0); err != nil {
+			return nil, nil, err
+		}
+	} else if !ok {
+		return nil, nil, Error{ErrorTypeNotFound, "SQLDatabase", "Table '" + ref.name + "' does not exist"}
+	}
+
+	//name the columns
+	width := sd.Width()
+	schema := make([]sqlSchemaColumn, width)
+
+	for col := range schema {
+		schema[col] = sqlSchemaColumn{ref.alias, "c" + strconv.Itoa(col), sqlNumber}
+
+		if sd.Header && len(sd.Data) != 0 && col < len(sd.Data[0]) {
+			schema[col].name = sd.Data[0][col]
+		}
+	}
+
+	//infer the column types
+	body := sd.body()
+
+	for _, row := range body {
+		for col, value := range row {
+			if len(value) != 0 && inferCellType(value) != CellTypeNumber {
+				schema[col].t = sqlString
+			}
+		}
+	}
+
+	rows := make([][]sqlValue, len(body))
+
+	for i, row := range body {
+		rows[i] = make([]sqlValue, width)
+
+		for col, value := range row {
+			switch {
+			case len(value) == 0:
+			case schema[col].t == sqlNumber:
+				f, _ := strconv.ParseFloat(value, 64)
+				rows[i][col] = sqlValue{t: sqlNumber, s: value, f: f}
+			default:
+				rows[i][col] = sqlStringValue(value)
+			}
+		}
+	}
+
+	return schema, rows, nil
+}
+
+//sqlJoinRows joins the rows of a table to the current rows. Joins on the
+//equality of two columns of the same type are performed using a hash table,
+//all other joins compare every combination of rows.
+func sqlJoinRows(rows, tableRows [][]sqlValue, schema []sqlSchemaColumn, width int, join sqlJoin, parameters []sqlValue) ([][]sqlValue, error) {
+	var result [][]sqlValue
+	tableWidth := len(schema) - width
+
+	//determine if the hash table can be used
+	leftColumn, rightColumn := -1, -1
+
+	if equal, ok := join.on.(*sqlBinary); ok && equal.op == "=" {
+		left, okLeft := equal.left.(*sqlColumn)
+		right, okRight := equal.right.(*sqlColumn)
+
+		if okLeft && okRight && left.index >= width && right.index < width {
+			left, right = right, left
+		}
+
+		if okLeft && okRight && left.index < width && right.index >= width && schema[left.index].t == schema[right.index].t {
+			leftColumn, rightColumn = left.index, right.index-width
+		}
+	}
+
+	var table map[string][]int
+
+	if leftColumn >= 0 {
+		table = make(map[string][]int)
+
+		for i, row := range tableRows {
+			if row[rightColumn].t != sqlNull {
+				key := row[rightColumn].key()
+				table[key] = append(table[key], i)
+			}
+		}
+	}
+
+	for _, row := range rows {
+		matched := false
+		candidates := tableRows
+		var indices []int
+
+		if table != nil {
+			indices = table[row[leftColumn].key()]
+
+			if row[leftColumn].t == sqlNull {
+				indices = nil
+			}
+
+			candidates = nil
+		}
+
+		for _, index := range indices {
+			candidates = append(candidates, tableRows[index])
+		}
+
+		for _, candidate := range candidates {
+			combined := append(append(make([]sqlValue, 0, len(schema)), row...), candidate...)
+			value, err := join.on.eval(&sqlContext{row: combined, parameters: parameters})
+
+			if err != nil {
+				return nil, err
+			}
+
+			if b, _ := value.truth(); b {
+				result = append(result, combined)
+				matched = true
+			}
+		}
+
+		if !matched && join.left {
+			result = append(result, append(append(make([]sqlValue, 0, len(schema)), row...), make([]sqlValue, tableWidth)...))
+		}
+	}
+
+	return result, nil
+}
+
+//sqlItemIndex returns the index of the column of the select list an expression
+//of the GROUP BY or ORDER BY clause refers to, either by its position or by its
+//alias. If the expression does not refer to the select list -1 is returned.
+func sqlItemIndex(expr sqlExpr, items []sqlSelectItem) int {
+	switch expr := expr.(type) {
+	case *sqlLiteral:
+		if position := int(expr.value.f); expr.value.t == sqlNumber && float64(position) == expr.value.f && position >= 1 && position <= len(items) {
+			return position - 1
+		}
+	case *sqlColumn:
+		if len(expr.table) != 0 {
+			return -1
+		}
+
+		for i, item := range items {
+			if strings.EqualFold(item.name, expr.name) {
+				//a plain column named after itself is bound as usual
+				if column, ok := item.expr.(*sqlColumn); ok && column == expr {
+					return -1
+				}
+
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+//sqlRecord is a single row of the result of a query, along with the values to
+//order it by
+type sqlRecord struct {
+	values []sqlValue
+	order  []sqlValue
+}
+
+//execSelect executes a parsed SELECT statement, returning the names of the
+//selected columns and the resulting rows
+func (db *SQLDatabase) execSelect(statement *sqlSelect, parameters []sqlValue) ([]string, [][]sqlValue, error) {
+	//load and join the tables
+	var schema []sqlSchemaColumn
+	rows := [][]sqlValue{{}}
+
+	if statement.from != nil {
+		var err error
+
+		if schema, rows, err = db.loadTable(*statement.from); err != nil {
+			return nil, nil, err
+		}
+
+		for _, join := range statement.joins {
+			tableSchema, tableRows, err := db.loadTable(join.table)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			width := len(schema)
+			schema = append(schema, tableSchema...)
+
+			if err = sqlBind(join.on, schema); err != nil {
+				return nil, nil, err
+			}
+
+			if rows, err = sqlJoinRows(rows, tableRows, schema, width, join, parameters); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	//expand the stars within the select list
+	var items []sqlSelectItem
+
+	for _, item := range statement.items {
+		if !item.star {
+			items = append(items, item)
+			continue
+		}
+
+		found := false
+
+		for i, column := range schema {
+			if len(item.table) == 0 || strings.EqualFold(item.table, column.table) {
+				items = append(items, sqlSelectItem{expr: &sqlColumn{column.table, column.name, i}, name: column.name})
+				found = true
+			}
+		}
+
+		if !found && len(item.table) != 0 {
+			return nil, nil, Error{ErrorTypeNotFound, "SQL", "Table '" + item.table + "' does not exist"}
+		}
+	}
+
+	//GROUP BY and ORDER BY may refer to the select list
+	groupBy := make([]sqlExpr, len(statement.groupBy))
+
+	for i, expr := range statement.groupBy {
+		groupBy[i] = expr
+
+		if column := sqlItemIndex(expr, items); column >= 0 {
+			groupBy[i] = items[column].expr
+		}
+	}
+
+	orderColumns := make([]int, len(statement.orderBy))
+
+	for i, order := range statement.orderBy {
+		orderColumns[i] = sqlItemIndex(order.expr, items)
+	}
+
+	//bind all expressions and check if the rows have to be grouped
+	grouped := len(groupBy) != 0 || statement.having != nil
+	exprs := append([]sqlExpr{statement.where, statement.having}, groupBy...)
+
+	for _, item := range items {
+		exprs = append(exprs, item.expr)
+		grouped = grouped || sqlHasAggregate(item.expr)
+	}
+
+	for i, order := range statement.orderBy {
+		if orderColumns[i] < 0 {
+			exprs = append(exprs, order.expr)
+			grouped = grouped || sqlHasAggregate(order.expr)
+		}
+	}
+
+	for _, expr := range exprs {
+		if err := sqlBind(expr, schema); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	//filter the rows
+	if statement.where != nil {
+		filtered := rows[:0]
+
+		for _, row := range rows {
+			value, err := statement.where.eval(&sqlContext{row: row, parameters: parameters})
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if b, _ := value.truth(); b {
+				filtered = append(filtered, row)
+			}
+		}
+
+		rows = filtered
+	}
+
+	//create the contexts to evaluate the select list on
+	var contexts []*sqlContext
+
+	if grouped {
+		groups := make(map[string]*sqlContext)
+
+		if len(groupBy) == 0 {
+			//all rows form a single group, even if there are none
+			contexts = append(contexts, &sqlContext{nil, rows, true, parameters})
+
+			if len(rows) != 0 {
+				contexts[0].row = rows[0]
+			}
+
+			rows = nil
+		}
+
+		for _, row := range rows {
+			var key strings.Builder
+
+			for _, expr := range groupBy {
+				value, err := expr.eval(&sqlContext{row: row, parameters: parameters})
+
+				if err != nil {
+					return nil, nil, err
+				}
+
+				key.WriteString(strconv.Quote(value.key()))
+			}
+
+			group, ok := groups[key.String()]
+
+			if !ok {
+				group = &sqlContext{row, nil, true, parameters}
+				groups[key.String()] = group
+				contexts = append(contexts, group)
+			}
+
+			group.group = append(group.group, row)
+		}
+	} else {
+		for _, row := range rows {
+			contexts = append(contexts, &sqlContext{row: row, parameters: parameters})
+		}
+	}
+
+	//evaluate the select list and the values to order by
+	var records []sqlRecord
+	seen := make(map[string]bool)
+
+	for _, ctx := range contexts {
+		if statement.having != nil {
+			value, err := statement.having.eval(ctx)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if b, _ := value.truth(); !b {
+				continue
+			}
+		}
+
+		record := sqlRecord{make([]sqlValue, len(items)), make([]sqlValue, len(statement.orderBy))}
+
+		for i, item := range items {
+			var err error
+
+			if record.values[i], err = item.expr.eval(ctx); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if statement.distinct {
+			key := make([]string, len(record.values))
+
+			for i, value := range record.values {
+				key[i] = value.key()
+			}
+
+			if seen[joinKey(key)] {
+				continue
+			}
+
+			seen[joinKey(key)] = true
+		}
+
+		for i, order := range statement.orderBy {
+			if orderColumns[i] >= 0 {
+				record.order[i] = record.values[orderColumns[i]]
+				continue
+			}
+
+			var err error
+
+			if record.order[i], err = order.expr.eval(ctx); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	//order the records, NULL values are always last
+	sort.SliceStable(records, func(i, j int) bool {
+		for k, order := range statement.orderBy {
+			a, b := records[i].order[k], records[j].order[k]
+
+			if a.t == sqlNull || b.t == sqlNull {
+				if a.t != b.t {
+					return b.t == sqlNull
+				}
+
+				continue
+			}
+
+			c, _ := sqlCompare(a, b)
+
+			if c != 0 {
+				return (c < 0) != order.descending
+			}
+		}
+
+		return false
+	})
+
+	//apply the offset and limit
+	offset, err := sqlCount(statement.offset, parameters, 0)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limit, err := sqlCount(statement.limit, parameters, len(records))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if offset > len(records) {
+		offset = len(records)
+	}
+
+	records = records[offset:]
+
+	if limit < len(records) {
+		records = records[:limit]
+	}
+
+	header := make([]string, len(items))
+
+	for i, item := range items {
+		header[i] = item.name
+	}
+
+	result := make([][]sqlValue, len(records))
+
+	for i, record := range records {
+		result[i] = record.values
+	}
+
+	return header, result, nil
+}
+
+//sqlCount evaluates the expression of a LIMIT or OFFSET clause, returning the
+//default value if there is no such clause
+func sqlCount(expr sqlExpr, parameters []sqlValue, defaultValue int) (int, error) {
+	if expr == nil {
+		return defaultValue, nil
+	}
+
+	value, err := expr.eval(&sqlContext{parameters: parameters})
+
+	if err != nil {
+		return 0, err
+	}
+
+	f, ok := value.number()
+
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, Error{ErrorTypeInvalidArgument, "SQL", "LIMIT and OFFSET require a non-negative integer"}
+	}
+
+	return int(f), nil
+}
+
+//sqlArgument converts an argument for a ? placeholder to a value. Strings,
+//byte slices, booleans, integers, floats, times (see formatCellTime) and nil
+//(NULL) are supported.
+func sqlArgument(arg interface{}) (sqlValue, error) {
+	switch v := arg.(type) {
+	case nil:
+		return sqlValue{}, nil
+	case string:
+		return sqlStringValue(v), nil
+	case []byte:
+		return sqlStringValue(string(v)), nil
+	case bool:
+		return sqlBoolValue(v), nil
+	case int:
+		return sqlNumberValue(float64(v)), nil
+	case int32:
+		return sqlNumberValue(float64(v)), nil
+	case int64:
+		return sqlNumberValue(float64(v)), nil
+	case uint:
+		return sqlNumberValue(float64(v)), nil
+	case uint32:
+		return sqlNumberValue(float64(v)), nil
+	case uint64:
+		return sqlNumberValue(float64(v)), nil
+	case float32:
+		return sqlNumberValue(float64(v)), nil
+	case float64:
+		return sqlNumberValue(v), nil
+	case time.Time:
+		return sqlStringValue(formatCellTime(v)), nil
+	}
+
+	return sqlValue{}, Error{ErrorTypeInvalidArgument, "SQL", "Unsupported parameter type"}
+}
+
+//sqlArguments converts the arguments for the ? placeholders of a statement,
+//checking that an argument is specified for every placeholder
+func sqlArguments(args []interface{}, expected int) ([]sqlValue, error) {
+	if len(args) != expected {
+		return nil, Error{ErrorTypeInvalidArgument, "SQL", "Expected " + strconv.Itoa(expected) + " parameters, got " + strconv.Itoa(len(args))}
+	}
+
+	parameters := make([]sqlValue, len(args))
+
+	for i, arg := range args {
+		var err error
+
+		if parameters[i], err = sqlArgument(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	return parameters, nil
+}
+
+//parse parses a single statement, which is either a *sqlSelect or a
+//*sqlInsert, and returns the number of ? placeholders within it
+func (db *SQLDatabase) parse(query string) (interface{}, int, error) {
+	parser, err := newSQLParser(query)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	statement, err := parser.parseStatement()
+
+	if err == nil {
+		err = parser.end()
+	}
+
+	return statement, parser.parameters, err
+}
+
+//Query parses and executes a SELECT statement and returns the result as a new
+//spreadsheet, of which the first row contains the names of the selected
+//columns. Selected expressions are named after their alias, the column they
+//select or otherwise the text of the expression. An argument has to be
+//specified for every ? placeholder within the query, see sqlArgument(...).
+func (db *SQLDatabase) Query(query string, args ...interface{}) (SpreadsheetDelimSheet, error) {
+	statement, count, err := db.parse(query)
+
+	if err != nil {
+		return SpreadsheetDelimSheet{}, err
+	}
+
+	selectStatement, ok := statement.(*sqlSelect)
+
+	if !ok {
+		return SpreadsheetDelimSheet{}, Error{ErrorTypeInvalidArgument, "SQL", "Query only accepts SELECT statements, use Exec(...) instead"}
+	}
+
+	parameters, err := sqlArguments(args, count)
+
+	if err != nil {
+		return SpreadsheetDelimSheet{}, err
+	}
+
+	header, rows, err := db.execSelect(selectStatement, parameters)
+
+	if err != nil {
+		return SpreadsheetDelimSheet{}, err
+	}
+
+	result := NewSpreadsheetDelim(db.buffer, db.delimeter)
+	result.Header = true
+	result.Data = append(result.Data, header)
+
+	for _, values := range rows {
+		row := make([]string, len(values))
+
+		for i, value := range values {
+			row[i] = value.String()
+		}
+
+		result.Data = append(result.Data, row)
+	}
+
+	return SpreadsheetDelimSheet{result}, nil
+}
+
+//Exec parses and executes an INSERT statement and returns the number of
+//inserted rows. An argument has to be specified for every ? placeholder within
+//the statement.
+func (db *SQLDatabase) Exec(query string, args ...interface{}) (int, error) {
+	statement, count, err := db.parse(query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	insertStatement, ok := statement.(*sqlInsert)
+
+	if !ok {
+		return 0, Error{ErrorTypeInvalidArgument, "SQL", "Exec only accepts INSERT statements, use Query(...) instead"}
+	}
+
+	parameters, err := sqlArguments(args, count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return db.execInsert(insertStatement, parameters)
+}
+
+//insertRows converts the values of an INSERT statement to rows, ordering the
+//values in the order of the columns of the header
+func (statement *sqlInsert) insertRows(header []string, parameters []sqlValue) ([][]string, error) {
+	//map the columns of the statement to the columns of the header
+	var columns []int
+
+	for _, name := range statement.columns {
+		found := false
+
+		for col, candidate := range header {
+			if strings.EqualFold(candidate, name) {
+				columns = append(columns, col)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, Error{ErrorTypeNotFound, "SQL", "Column '" + name + "' does not exist"}
+		}
+	}
+
+	rows := make([][]string, len(statement.rows))
+
+	for i, exprs := range statement.rows {
+		if len(columns) != 0 && len(exprs) != len(columns) {
+			return nil, Error{ErrorTypeInvalidArgument, "SQL", "Number of values does not match the number of columns"}
+		}
+
+		if len(columns) == 0 && len(header) != 0 && len(exprs) > len(header) {
+			return nil, Error{ErrorTypeInvalidArgument, "SQL", "More values than columns specified"}
+		}
+
+		width := len(header)
+
+		if len(exprs) > width {
+			width = len(exprs)
+		}
+
+		rows[i] = make([]string, width)
+
+		for j, expr := range exprs {
+			if err := sqlBind(expr, nil); err != nil {
+				return nil, err
+			}
+
+			value, err := expr.eval(&sqlContext{parameters: parameters})
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(columns) != 0 {
+				rows[i][columns[j]] = value.String()
+			} else {
+				rows[i][j] = value.String()
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+//execInsert executes a parsed INSERT statement. Rows are appended to the
+//spreadsheet of a table added using AddTable(...), or to the end of the file of
+//a table stored as a file using a SpreadsheetDelimWriter.
+func (db *SQLDatabase) execInsert(statement *sqlInsert, parameters []sqlValue) (int, error) {
+	filename, isFile := db.tableFile(statement.table)
+
+	if !isFile {
+		sd, ok := db.tables[strings.ToLower(statement.table.name)]
+
+		if !ok {
+			return 0, Error{ErrorTypeNotFound, "SQLDatabase", "Table '" + statement.table.name + "' does not exist"}
+		}
+
+		var header []string
+
+		if sd.Header && len(sd.Data) != 0 {
+			header = sd.Data[0]
+		}
+
+		rows, err := statement.insertRows(header, parameters)
+
+		if err != nil {
+			return 0, err
+		}
+
+		for _, row := range rows {
+			sd.WriteRow(row)
+		}
+
+		return len(rows), nil
+	}
+
+	//read the header of the file
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND, 0)
+
+	if err != nil {
+		return 0, Error{ErrorTypeLoading, "SQLDatabase", "Failed to open '" + filename + "'"}
+	}
+
+	defer file.Close()
+	header, err := NewSpreadsheetDelimReader(file, db.buffer, db.delimeter).ReadRow()
+
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	rows, err := statement.insertRows(header, parameters)
+
+	if err != nil {
+		return 0, err
+	}
+
+	//make sure the appended rows start on a new line
+	var buffer bytes.Buffer
+	info, err := file.Stat()
+
+	if err != nil {
+		return 0, Error{ErrorTypeLoading, "SQLDatabase", "Failed to inspect '" + filename + "'"}
+	}
+
+	if info.Size() != 0 {
+		last := make([]byte, 1)
+
+		if _, err = file.ReadAt(last, info.Size()-1); err != nil {
+			return 0, Error{ErrorTypeLoading, "SQLDatabase", "Failed to read '" + filename + "'"}
+		}
+
+		if last[0] != '\n' {
+			buffer.WriteByte('\n')
+		}
+	}
+
+	//write the rows to a buffer first, such that no rows are appended if any of
+	//them cannot be written
+	writer := NewSpreadsheetDelimWriter(&buffer, db.delimeter)
+
+	for _, row := range rows {
+		if err = writer.WriteRow(row); err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+
+	if _, err = file.Write(buffer.Bytes()); err != nil {
+		return 0, Error{ErrorTypeSaving, "SQLDatabase", "Failed to append rows to '" + filename + "'"}
+	}
+
+	return len(rows), nil
+}
@@ -0,0 +1,211 @@
+package fio
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+)
+
+//SQLDriverName is the name under which SQLDriver is registered with the
+//database/sql package
+const SQLDriverName = "fio"
+
+//sqlDriverBuffer is the initial buffer size used by connections opened through
+//sql.Open(...)
+const sqlDriverBuffer = 4096
+
+func init() {
+	sql.Register(SQLDriverName, SQLDriver{})
+}
+
+//SQLDriver implements the database/sql/driver.Driver interface on top of
+//SQLDatabase, such that a directory of delimited files can be queried through
+//the database/sql package. It is registered as SQLDriverName, the data source
+//name is the directory containing the .csv files:
+//
+//	db, err := sql.Open(fio.SQLDriverName, "path/to/directory")
+//	rows, err := db.Query("SELECT name FROM customers WHERE city = ?", "Delft")
+//
+//Every .csv file within the directory is a table named after the file, the
+//header of the file contains the column names. Both SELECT and INSERT
+//statements are supported, see SQLDatabase. To use a different delimeter or
+//extension, or to query spreadsheets that are not stored as files, use
+//NewSQLConnector(...) in combination with sql.OpenDB(...).
+//
+//Values are returned as int64 for integral numbers, float64 for other numbers,
+//string for all other values and nil for empty values. Transactions are not
+//supported.
+type SQLDriver struct{}
+
+//Open opens a connection to the directory specified by name
+func (d SQLDriver) Open(name string) (driver.Conn, error) {
+	return &sqlConn{NewSQLDatabaseDir(sqlDriverBuffer, ",", name, ".csv")}, nil
+}
+
+//sqlConnector implements the driver.Connector interface for an existing
+//SQLDatabase instance
+type sqlConnector struct {
+	db *SQLDatabase
+}
+
+//NewSQLConnector returns a driver.Connector for the specified database, to be
+//used with sql.OpenDB(...). All connections share the database, which should
+//not be changed (for instance by adding tables) while it is in use.
+func NewSQLConnector(db *SQLDatabase) driver.Connector {
+	return sqlConnector{db}
+}
+
+func (c sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &sqlConn{c.db}, nil
+}
+
+func (c sqlConnector) Driver() driver.Driver {
+	return SQLDriver{}
+}
+
+//sqlConn implements the driver.Conn interface
+type sqlConn struct {
+	db *SQLDatabase
+}
+
+//Prepare parses the statement, such that syntax errors are reported
+//immediately
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	statement, count, err := c.db.parse(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlStmt{c.db, statement, count}, nil
+}
+
+func (c *sqlConn) Close() error {
+	return nil
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return nil, Error{ErrorTypeInvalidArgument, "SQLDriver", "Transactions are not supported"}
+}
+
+//sqlStmt implements the driver.Stmt interface for a parsed statement
+type sqlStmt struct {
+	db        *SQLDatabase
+	statement interface{}
+	count     int
+}
+
+func (s *sqlStmt) Close() error {
+	return nil
+}
+
+func (s *sqlStmt) NumInput() int {
+	return s.count
+}
+
+//sqlDriverArguments converts the arguments of a statement to values
+func sqlDriverArguments(args []driver.Value, expected int) ([]sqlValue, error) {
+	values := make([]interface{}, len(args))
+
+	for i, arg := range args {
+		values[i] = arg
+	}
+
+	return sqlArguments(values, expected)
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	insert, ok := s.statement.(*sqlInsert)
+
+	if !ok {
+		return nil, Error{ErrorTypeInvalidArgument, "SQLDriver", "Only INSERT statements can be executed, use Query(...) for SELECT statements"}
+	}
+
+	parameters, err := sqlDriverArguments(args, s.count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.db.execInsert(insert, parameters)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(count), nil
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	selectStatement, ok := s.statement.(*sqlSelect)
+
+	if !ok {
+		return nil, Error{ErrorTypeInvalidArgument, "SQLDriver", "Only SELECT statements can be queried, use Exec(...) for INSERT statements"}
+	}
+
+	parameters, err := sqlDriverArguments(args, s.count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns, rows, err := s.db.execSelect(selectStatement, parameters)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlRows{columns, rows, 0}, nil
+}
+
+//sqlRows implements the driver.Rows interface for the result of a query
+type sqlRows struct {
+	columns []string
+	rows    [][]sqlValue
+	row     int
+}
+
+func (r *sqlRows) Columns() []string {
+	return r.columns
+}
+
+func (r *sqlRows) Close() error {
+	r.row = len(r.rows)
+	return nil
+}
+
+//Next converts the values of the next row to driver values
+func (r *sqlRows) Next(dest []driver.Value) error {
+	if r.row >= len(r.rows) {
+		return io.EOF
+	}
+
+	for i, value := range r.rows[r.row] {
+		dest[i] = sqlDriverValue(value)
+	}
+
+	r.row++
+	return nil
+}
+
+//sqlDriverValue converts a value to the type returned by the driver: int64
+//for integral numbers, float64 for other numbers, bool for booleans, string for
+//strings and nil for NULL values
+func sqlDriverValue(value sqlValue) driver.Value {
+	switch value.t {
+	case sqlNumber:
+		if value.f == math.Trunc(value.f) && math.Abs(value.f) < 1<<53 {
+			return int64(value.f)
+		}
+
+		return value.f
+	case sqlString:
+		return value.s
+	case sqlBool:
+		return value.b
+	}
+
+	return nil
+}
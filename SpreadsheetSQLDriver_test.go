@@ -0,0 +1,139 @@
+package fio
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLDriver(t *testing.T) {
+	directory := t.TempDir()
+	filename := filepath.Join(directory, "customers.csv")
+
+	if err := os.WriteFile(filename, []byte("name,city,balance\nann,Delft,10.5\nbob,Leiden,3"), 0644); err != nil {
+		t.Fatalf("Failed to write test file, error: %s\n", err.Error())
+	}
+
+	db, err := sql.Open(SQLDriverName, directory)
+
+	if err != nil {
+		t.Fatalf("Failed to open database, error: %s\n", err.Error())
+	}
+
+	defer db.Close()
+
+	//insert rows, the file does not end with a newline
+	result, err := db.Exec("INSERT INTO customers (city, name) VALUES (?, ?), ('Gouda', 'dan')", "Delft", "cat")
+
+	if err != nil {
+		t.Fatalf("Failed to insert rows, error: %s\n", err.Error())
+	}
+
+	if count, _ := result.RowsAffected(); count != 2 {
+		t.Errorf("Expected 2 inserted rows, got %d\n", count)
+	}
+
+	data, _ := os.ReadFile(filename)
+
+	if expected := "name,city,balance\nann,Delft,10.5\nbob,Leiden,3\ncat,Delft,\ndan,Gouda,\n"; string(data) != expected {
+		t.Errorf("Unexpected file contents:\n%s\nexpected:\n%s\n", data, expected)
+	}
+
+	//query with parameters and typed values
+	rows, err := db.Query("SELECT name, balance, balance * 2 FROM customers WHERE city = ? ORDER BY name", "Delft")
+
+	if err != nil {
+		t.Fatalf("Failed to query, error: %s\n", err.Error())
+	}
+
+	var names []string
+	var balances []sql.NullFloat64
+
+	for rows.Next() {
+		var name string
+		var balance sql.NullFloat64
+		var double interface{}
+
+		if err = rows.Scan(&name, &balance, &double); err != nil {
+			t.Fatalf("Failed to scan row, error: %s\n", err.Error())
+		}
+
+		if name == "ann" {
+			if _, ok := double.(int64); !ok || double.(int64) != 21 {
+				t.Errorf("Expected int64 21, got %T %v\n", double, double)
+			}
+		}
+
+		names = append(names, name)
+		balances = append(balances, balance)
+	}
+
+	rows.Close()
+
+	if len(names) != 2 || names[0] != "ann" || names[1] != "cat" || balances[0].Float64 != 10.5 || balances[1].Valid {
+		t.Errorf("Unexpected query result %v %v\n", names, balances)
+	}
+
+	var count int64
+
+	if err = db.QueryRow("SELECT COUNT(*) FROM customers").Scan(&count); err != nil || count != 4 {
+		t.Errorf("Expected 4 rows, got %d (error: %v)\n", count, err)
+	}
+
+	if _, err = db.Query("SELECT name FROM missing"); err == nil {
+		t.Errorf("Expected querying a missing table to fail\n")
+	}
+
+	if _, err = db.Exec("INSERT INTO customers VALUES ('a,b', 'x')"); err == nil {
+		t.Errorf("Expected inserting a value containing the delimeter to fail\n")
+	}
+
+	//quoted filenames are relative to the directory and may not leave it
+	if err = db.QueryRow("SELECT COUNT(*) FROM 'customers.csv'").Scan(&count); err != nil || count != 4 {
+		t.Errorf("Expected 4 rows in the quoted file, got %d (error: %v)\n", count, err)
+	}
+
+	outside := filepath.Join(t.TempDir(), "outside.csv")
+	os.WriteFile(outside, []byte("secret\nvalue\n"), 0644)
+	relative, _ := filepath.Rel(directory, outside)
+
+	for _, name := range []string{outside, relative, filepath.ToSlash(relative)} {
+		if _, err = db.Query("SELECT * FROM '" + name + "'"); err == nil {
+			t.Errorf("Expected querying '%s' outside of the directory to fail\n", name)
+		}
+
+		if _, err = db.Exec("INSERT INTO '" + name + "' VALUES ('x')"); err == nil {
+			t.Errorf("Expected inserting into '%s' outside of the directory to fail\n", name)
+		}
+	}
+
+	if data, _ = os.ReadFile(outside); string(data) != "secret\nvalue\n" {
+		t.Errorf("Expected the file outside of the directory to be unchanged, got:\n%s\n", data)
+	}
+}
+
+func TestSQLConnector(t *testing.T) {
+	table := NewSpreadsheetDelim(16, ";")
+	table.Header = true
+	table.Data = [][]string{{"id", "value"}, {"1", "x"}}
+
+	database := NewSQLDatabase(16, ";")
+	database.AddTable("items", table)
+	db := sql.OpenDB(NewSQLConnector(database))
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO items VALUES (?, ?)", 2, "y"); err != nil {
+		t.Fatalf("Failed to insert row, error: %s\n", err.Error())
+	}
+
+	var value string
+
+	if err := db.QueryRow("SELECT value FROM items WHERE id = ?", int64(2)).Scan(&value); err != nil || value != "y" {
+		t.Errorf("Expected 'y', got '%s' (error: %v)\n", value, err)
+	}
+
+	if len(table.Data) != 3 || table.Data[2][0] != "2" {
+		t.Errorf("Unexpected table contents %q\n", table.Data)
+	}
+}
@@ -0,0 +1,919 @@
+package fio
+
+import (
+	"strconv"
+	"strings"
+)
+
+//sqlTokenType is the type used to distinguish the tokens of a SQL statement
+type sqlTokenType byte
+
+//The various token types to use in conjunction with the sqlTokenType type
+const (
+	sqlTokenEOF        sqlTokenType = iota //end of the statement
+	sqlTokenIdentifier                     //a keyword or unquoted name
+	sqlTokenQuoted                         //a name quoted using "name" or `name`
+	sqlTokenString                         //a string literal quoted using 'value'
+	sqlTokenNumber                         //a numeric literal
+	sqlTokenSymbol                         //an operator or punctuation
+	sqlTokenParameter                      //a ? placeholder
+)
+
+//sqlToken is a single token of a SQL statement, pos is its offset within the
+//statement
+type sqlToken struct {
+	t    sqlTokenType
+	text string
+	pos  int
+}
+
+//sqlKeywords contains the keywords that cannot be used as unquoted aliases
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "DISTINCT": true, "FROM": true, "WHERE": true, "GROUP": true,
+	"BY": true, "HAVING": true, "ORDER": true, "ASC": true, "DESC": true,
+	"LIMIT": true, "OFFSET": true, "JOIN": true, "INNER": true, "LEFT": true,
+	"OUTER": true, "ON": true, "AS": true, "AND": true, "OR": true, "NOT": true,
+	"IN": true, "IS": true, "NULL": true, "LIKE": true, "BETWEEN": true,
+	"TRUE": true, "FALSE": true, "CASE": true, "WHEN": true, "THEN": true,
+	"ELSE": true, "END": true, "INSERT": true, "INTO": true, "VALUES": true,
+}
+
+//sqlError creates a parsing error for the specified position of the statement
+func sqlError(pos int, message string) error {
+	return Error{ErrorTypeParsing, "SQL", message + " at offset " + strconv.Itoa(pos)}
+}
+
+//sqlTokenize splits a SQL statement into tokens
+func sqlTokenize(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			//comment until the end of the line
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '\'' || c == '"' || c == '`':
+			//quoted string or name, quotes are escaped by doubling them
+			var value strings.Builder
+			start := i
+			i++
+
+			for {
+				if i >= len(query) {
+					return nil, sqlError(start, "Unterminated quoted value")
+				}
+
+				if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						value.WriteByte(c)
+						i += 2
+						continue
+					}
+
+					i++
+					break
+				}
+
+				value.WriteByte(query[i])
+				i++
+			}
+
+			t := sqlTokenString
+
+			if c != '\'' {
+				t = sqlTokenQuoted
+			}
+
+			tokens = append(tokens, sqlToken{t, value.String(), start})
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			start := i
+
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+
+			//exponent
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				j := i + 1
+
+				if j < len(query) && (query[j] == '+' || query[j] == '-') {
+					j++
+				}
+
+				if j < len(query) && isDigit(query[j]) {
+					for i = j; i < len(query) && isDigit(query[i]); i++ {
+					}
+				}
+			}
+
+			if !isNumeric(query[start:i]) {
+				return nil, sqlError(start, "Invalid number '"+query[start:i]+"'")
+			}
+
+			tokens = append(tokens, sqlToken{sqlTokenNumber, query[start:i], start})
+		case c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z'):
+			start := i
+
+			for i < len(query) && (query[i] == '_' || isDigit(query[i]) || (query[i]|0x20 >= 'a' && query[i]|0x20 <= 'z')) {
+				i++
+			}
+
+			tokens = append(tokens, sqlToken{sqlTokenIdentifier, query[start:i], start})
+		case c == '?':
+			tokens = append(tokens, sqlToken{sqlTokenParameter, "?", i})
+			i++
+		default:
+			//two-character operators first
+			if i+1 < len(query) {
+				switch query[i : i+2] {
+				case "<>", "!=", "<=", ">=", "||":
+					tokens = append(tokens, sqlToken{sqlTokenSymbol, query[i : i+2], i})
+					i += 2
+					continue
+				}
+			}
+
+			if !strings.ContainsRune("=<>+-*/%(),.;", rune(c)) {
+				return nil, sqlError(i, "Unexpected character '"+string(c)+"'")
+			}
+
+			tokens = append(tokens, sqlToken{sqlTokenSymbol, string(c), i})
+			i++
+		}
+	}
+
+	return append(tokens, sqlToken{sqlTokenEOF, "", len(query)}), nil
+}
+
+//sqlSelectItem is a single expression within the select list. If star is true
+//the item selects all columns (of the table if table is not empty).
+type sqlSelectItem struct {
+	expr  sqlExpr
+	name  string //the alias, or the text of the expression
+	star  bool
+	table string
+}
+
+//sqlTableRef refers to a table within the FROM clause. If file is true the name
+//is the filename of a delimited file.
+type sqlTableRef struct {
+	name  string
+	file  bool
+	alias string
+}
+
+//sqlJoin is a single JOIN clause
+type sqlJoin struct {
+	left  bool
+	table sqlTableRef
+	on    sqlExpr
+}
+
+//sqlOrder is a single expression within the ORDER BY clause
+type sqlOrder struct {
+	expr       sqlExpr
+	descending bool
+}
+
+//sqlSelect is a parsed SELECT statement
+type sqlSelect struct {
+	distinct bool
+	items    []sqlSelectItem
+	from     *sqlTableRef
+	joins    []sqlJoin
+	where    sqlExpr
+	groupBy  []sqlExpr
+	having   sqlExpr
+	orderBy  []sqlOrder
+	limit    sqlExpr
+	offset   sqlExpr
+}
+
+//sqlInsert is a parsed INSERT statement. If no columns are specified the
+//values are inserted in the order of the columns of the table.
+type sqlInsert struct {
+	table   sqlTableRef
+	columns []string
+	rows    [][]sqlExpr
+}
+
+//sqlParser parses the tokens of a single statement
+type sqlParser struct {
+	query      string
+	tokens     []sqlToken
+	pos        int
+	parameters int //the number of ? placeholders encountered
+}
+
+//newSQLParser tokenizes the query and returns a parser for it
+func newSQLParser(query string) (*sqlParser, error) {
+	tokens, err := sqlTokenize(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlParser{query, tokens, 0, 0}, nil
+}
+
+func (p *sqlParser) peek() sqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	token := p.tokens[p.pos]
+
+	if token.t != sqlTokenEOF {
+		p.pos++
+	}
+
+	return token
+}
+
+//isKeyword checks if the token at the specified offset from the current token
+//is the keyword
+func (p *sqlParser) isKeyword(offset int, keyword string) bool {
+	if p.pos+offset >= len(p.tokens) {
+		return false
+	}
+
+	token := p.tokens[p.pos+offset]
+	return token.t == sqlTokenIdentifier && strings.EqualFold(token.text, keyword)
+}
+
+//keyword consumes the sequence of keywords if the next tokens match them
+func (p *sqlParser) keyword(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if !p.isKeyword(i, keyword) {
+			return false
+		}
+	}
+
+	p.pos += len(keywords)
+	return true
+}
+
+//expectKeyword consumes the sequence of keywords, or returns an error if the
+//next tokens do not match them
+func (p *sqlParser) expectKeyword(keywords ...string) error {
+	if !p.keyword(keywords...) {
+		return sqlError(p.peek().pos, "Expected "+strings.Join(keywords, " "))
+	}
+
+	return nil
+}
+
+//symbol consumes the symbol if it is the next token
+func (p *sqlParser) symbol(symbol string) bool {
+	if token := p.peek(); token.t == sqlTokenSymbol && token.text == symbol {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+//expectSymbol consumes the symbol, or returns an error if it is not the next
+//token
+func (p *sqlParser) expectSymbol(symbol string) error {
+	if !p.symbol(symbol) {
+		return sqlError(p.peek().pos, "Expected '"+symbol+"'")
+	}
+
+	return nil
+}
+
+//name consumes an unquoted name (which may not be a keyword) or a quoted name
+func (p *sqlParser) name() (string, bool) {
+	token := p.peek()
+
+	if token.t == sqlTokenQuoted || (token.t == sqlTokenIdentifier && !sqlKeywords[strings.ToUpper(token.text)]) {
+		p.pos++
+		return token.text, true
+	}
+
+	return "", false
+}
+
+//end checks that the statement is completely parsed, allowing a trailing ';'
+func (p *sqlParser) end() error {
+	p.symbol(";")
+
+	if token := p.peek(); token.t != sqlTokenEOF {
+		return sqlError(token.pos, "Unexpected '"+token.text+"'")
+	}
+
+	return nil
+}
+
+//parseStatement parses a SELECT or INSERT statement
+func (p *sqlParser) parseStatement() (interface{}, error) {
+	if p.isKeyword(0, "INSERT") {
+		return p.parseInsert()
+	}
+
+	return p.parseSelect()
+}
+
+//parseInsert parses an INSERT statement
+func (p *sqlParser) parseInsert() (*sqlInsert, error) {
+	if err := p.expectKeyword("INSERT", "INTO"); err != nil {
+		return nil, err
+	}
+
+	statement := &sqlInsert{}
+
+	if token := p.peek(); token.t == sqlTokenString {
+		statement.table = sqlTableRef{p.next().text, true, ""}
+	} else if name, ok := p.name(); ok {
+		statement.table = sqlTableRef{name, false, name}
+	} else {
+		return nil, sqlError(token.pos, "Expected a table name")
+	}
+
+	if p.symbol("(") {
+		for {
+			name, ok := p.name()
+
+			if !ok {
+				return nil, sqlError(p.peek().pos, "Expected a column name")
+			}
+
+			statement.columns = append(statement.columns, name)
+
+			if !p.symbol(",") {
+				break
+			}
+		}
+
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		values, err := p.parseExprList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+
+		statement.rows = append(statement.rows, values)
+
+		if !p.symbol(",") {
+			return statement, nil
+		}
+	}
+}
+
+//parseSelect parses a SELECT statement
+func (p *sqlParser) parseSelect() (*sqlSelect, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	statement := &sqlSelect{}
+	statement.distinct = p.keyword("DISTINCT")
+
+	//select list
+	for {
+		item, err := p.parseSelectItem()
+
+		if err != nil {
+			return nil, err
+		}
+
+		statement.items = append(statement.items, item)
+
+		if !p.symbol(",") {
+			break
+		}
+	}
+
+	//tables
+	if p.keyword("FROM") {
+		table, err := p.parseTableRef()
+
+		if err != nil {
+			return nil, err
+		}
+
+		statement.from = &table
+
+		for {
+			join := sqlJoin{}
+
+			if p.keyword("LEFT", "OUTER", "JOIN") || p.keyword("LEFT", "JOIN") {
+				join.left = true
+			} else if !p.keyword("INNER", "JOIN") && !p.keyword("JOIN") {
+				break
+			}
+
+			if join.table, err = p.parseTableRef(); err != nil {
+				return nil, err
+			}
+
+			if err = p.expectKeyword("ON"); err != nil {
+				return nil, err
+			}
+
+			if join.on, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+
+			statement.joins = append(statement.joins, join)
+		}
+	}
+
+	var err error
+
+	if p.keyword("WHERE") {
+		if statement.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("GROUP", "BY") {
+		if statement.groupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("HAVING") {
+		if statement.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("ORDER", "BY") {
+		for {
+			order := sqlOrder{}
+
+			if order.expr, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+
+			if p.keyword("DESC") {
+				order.descending = true
+			} else {
+				p.keyword("ASC")
+			}
+
+			statement.orderBy = append(statement.orderBy, order)
+
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		if statement.limit, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+
+		if p.keyword("OFFSET") {
+			if statement.offset, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return statement, nil
+}
+
+//parseSelectItem parses a single item of the select list
+func (p *sqlParser) parseSelectItem() (sqlSelectItem, error) {
+	if p.symbol("*") {
+		return sqlSelectItem{star: true}, nil
+	}
+
+	//table.*
+	if token := p.peek(); token.t == sqlTokenIdentifier || token.t == sqlTokenQuoted {
+		if p.pos+2 < len(p.tokens) && p.tokens[p.pos+1].text == "." && p.tokens[p.pos+2].text == "*" && p.tokens[p.pos+2].t == sqlTokenSymbol {
+			p.pos += 3
+			return sqlSelectItem{star: true, table: token.text}, nil
+		}
+	}
+
+	start := p.peek().pos
+	expr, err := p.parseExpr()
+
+	if err != nil {
+		return sqlSelectItem{}, err
+	}
+
+	item := sqlSelectItem{expr: expr, name: strings.TrimSpace(p.query[start:p.peek().pos])}
+
+	if column, ok := expr.(*sqlColumn); ok {
+		item.name = column.name
+	}
+
+	if p.keyword("AS") {
+		name, ok := p.name()
+
+		if !ok {
+			if token := p.peek(); token.t == sqlTokenString {
+				name = p.next().text
+			} else {
+				return sqlSelectItem{}, sqlError(token.pos, "Expected an alias")
+			}
+		}
+
+		item.name = name
+	} else if name, ok := p.name(); ok {
+		item.name = name
+	}
+
+	return item, nil
+}
+
+//parseTableRef parses a table name or a quoted filename, with an optional
+//alias
+func (p *sqlParser) parseTableRef() (sqlTableRef, error) {
+	ref := sqlTableRef{}
+
+	if token := p.peek(); token.t == sqlTokenString {
+		ref.name, ref.file = p.next().text, true
+	} else if name, ok := p.name(); ok {
+		ref.name = name
+	} else {
+		return ref, sqlError(token.pos, "Expected a table name")
+	}
+
+	ref.alias = ref.name
+
+	if p.keyword("AS") {
+		alias, ok := p.name()
+
+		if !ok {
+			return ref, sqlError(p.peek().pos, "Expected an alias")
+		}
+
+		ref.alias = alias
+	} else if alias, ok := p.name(); ok {
+		ref.alias = alias
+	}
+
+	return ref, nil
+}
+
+//parseExprList parses a comma seperated list of expressions
+func (p *sqlParser) parseExprList() ([]sqlExpr, error) {
+	var exprs []sqlExpr
+
+	for {
+		expr, err := p.parseExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if !p.symbol(",") {
+			return exprs, nil
+		}
+	}
+}
+
+//parseExpr parses an expression, starting at the lowest precedence
+func (p *sqlParser) parseExpr() (sqlExpr, error) {
+	return p.parseOr()
+}
+
+func (p *sqlParser) parseOr() (sqlExpr, error) {
+	left, err := p.parseAnd()
+
+	for err == nil && p.keyword("OR") {
+		var right sqlExpr
+
+		if right, err = p.parseAnd(); err == nil {
+			left = &sqlBinary{"OR", left, right}
+		}
+	}
+
+	return left, err
+}
+
+func (p *sqlParser) parseAnd() (sqlExpr, error) {
+	left, err := p.parseNot()
+
+	for err == nil && p.keyword("AND") {
+		var right sqlExpr
+
+		if right, err = p.parseNot(); err == nil {
+			left = &sqlBinary{"AND", left, right}
+		}
+	}
+
+	return left, err
+}
+
+func (p *sqlParser) parseNot() (sqlExpr, error) {
+	if p.keyword("NOT") {
+		expr, err := p.parseNot()
+		return &sqlUnary{"NOT", expr}, err
+	}
+
+	return p.parseComparison()
+}
+
+//parseComparison parses the comparison operators, IS [NOT] NULL, [NOT] IN,
+//[NOT] LIKE and [NOT] BETWEEN
+func (p *sqlParser) parseComparison() (sqlExpr, error) {
+	left, err := p.parseAdditive()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token := p.peek(); token.t == sqlTokenSymbol {
+		switch token.text {
+		case "=", "<>", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			op := token.text
+
+			if op == "!=" {
+				op = "<>"
+			}
+
+			return &sqlBinary{op, left, right}, err
+		}
+	}
+
+	if p.keyword("IS") {
+		not := p.keyword("NOT")
+
+		if err = p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+
+		return &sqlIsNull{left, not}, nil
+	}
+
+	not := p.isKeyword(0, "NOT") && (p.isKeyword(1, "IN") || p.isKeyword(1, "LIKE") || p.isKeyword(1, "BETWEEN"))
+
+	if not {
+		p.next()
+	}
+
+	switch {
+	case p.keyword("IN"):
+		if err = p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		list, err := p.parseExprList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &sqlIn{left, list, not}, p.expectSymbol(")")
+	case p.keyword("LIKE"):
+		pattern, err := p.parseAdditive()
+		return &sqlLike{left, pattern, not}, err
+	case p.keyword("BETWEEN"):
+		low, err := p.parseAdditive()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+
+		high, err := p.parseAdditive()
+		return &sqlBetween{left, low, high, not}, err
+	}
+
+	return left, nil
+}
+
+func (p *sqlParser) parseAdditive() (sqlExpr, error) {
+	left, err := p.parseMultiplicative()
+
+	for err == nil {
+		token := p.peek()
+
+		if token.t != sqlTokenSymbol || (token.text != "+" && token.text != "-" && token.text != "||") {
+			break
+		}
+
+		p.next()
+		var right sqlExpr
+
+		if right, err = p.parseMultiplicative(); err == nil {
+			left = &sqlBinary{token.text, left, right}
+		}
+	}
+
+	return left, err
+}
+
+func (p *sqlParser) parseMultiplicative() (sqlExpr, error) {
+	left, err := p.parseUnary()
+
+	for err == nil {
+		token := p.peek()
+
+		if token.t != sqlTokenSymbol || (token.text != "*" && token.text != "/" && token.text != "%") {
+			break
+		}
+
+		p.next()
+		var right sqlExpr
+
+		if right, err = p.parseUnary(); err == nil {
+			left = &sqlBinary{token.text, left, right}
+		}
+	}
+
+	return left, err
+}
+
+func (p *sqlParser) parseUnary() (sqlExpr, error) {
+	if p.symbol("-") {
+		expr, err := p.parseUnary()
+
+		//fold negative numbers, such that they can be used as literals
+		if literal, ok := expr.(*sqlLiteral); ok && literal.value.t == sqlNumber {
+			return &sqlLiteral{sqlValue{t: sqlNumber, f: -literal.value.f}}, err
+		}
+
+		return &sqlUnary{"-", expr}, err
+	}
+
+	if p.symbol("+") {
+		return p.parseUnary()
+	}
+
+	return p.parsePrimary()
+}
+
+//parsePrimary parses literals, parameters, columns, function calls, CASE
+//expressions and parenthesized expressions
+func (p *sqlParser) parsePrimary() (sqlExpr, error) {
+	token := p.peek()
+
+	switch token.t {
+	case sqlTokenNumber:
+		p.next()
+		f, _ := strconv.ParseFloat(token.text, 64)
+		return &sqlLiteral{sqlValue{t: sqlNumber, f: f}}, nil
+	case sqlTokenString:
+		p.next()
+		return &sqlLiteral{sqlValue{t: sqlString, s: token.text}}, nil
+	case sqlTokenParameter:
+		p.next()
+		p.parameters++
+		return &sqlParameter{p.parameters - 1}, nil
+	case sqlTokenSymbol:
+		if p.symbol("(") {
+			expr, err := p.parseExpr()
+
+			if err != nil {
+				return nil, err
+			}
+
+			return expr, p.expectSymbol(")")
+		}
+	case sqlTokenIdentifier:
+		switch strings.ToUpper(token.text) {
+		case "NULL":
+			p.next()
+			return &sqlLiteral{sqlValue{}}, nil
+		case "TRUE", "FALSE":
+			p.next()
+			return &sqlLiteral{sqlValue{t: sqlBool, b: strings.EqualFold(token.text, "TRUE")}}, nil
+		case "CASE":
+			p.next()
+			return p.parseCase()
+		}
+
+		//function call
+		if p.tokens[p.pos+1].t == sqlTokenSymbol && p.tokens[p.pos+1].text == "(" {
+			p.pos += 2
+			return p.parseFunction(strings.ToUpper(token.text), token.pos)
+		}
+	}
+
+	name, ok := p.name()
+
+	if !ok {
+		if token.t == sqlTokenEOF {
+			return nil, sqlError(token.pos, "Unexpected end of statement")
+		}
+
+		return nil, sqlError(token.pos, "Unexpected '"+token.text+"'")
+	}
+
+	if p.symbol(".") {
+		column, ok := p.name()
+
+		if !ok {
+			return nil, sqlError(p.peek().pos, "Expected a column name")
+		}
+
+		return &sqlColumn{name, column, -1}, nil
+	}
+
+	return &sqlColumn{"", name, -1}, nil
+}
+
+//parseFunction parses the arguments of a function call, after the opening
+//parenthesis
+func (p *sqlParser) parseFunction(name string, pos int) (sqlExpr, error) {
+	function := &sqlFunction{name: name}
+	_, function.aggregate = sqlAggregates[name]
+
+	if _, ok := sqlFunctions[name]; !ok && !function.aggregate {
+		return nil, sqlError(pos, "Unknown function '"+name+"'")
+	}
+
+	if function.aggregate {
+		function.distinct = p.keyword("DISTINCT")
+	}
+
+	if name == "COUNT" && p.symbol("*") {
+		function.star = true
+	} else if !p.symbol(")") {
+		args, err := p.parseExprList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		function.args = args
+	} else {
+		return function, function.checkArguments(pos)
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return function, function.checkArguments(pos)
+}
+
+//parseCase parses a CASE WHEN ... THEN ... [ELSE ...] END expression, after
+//the CASE keyword
+func (p *sqlParser) parseCase() (sqlExpr, error) {
+	expr := &sqlCase{}
+
+	for p.keyword("WHEN") {
+		condition, err := p.parseExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+
+		result, err := p.parseExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		expr.conditions = append(expr.conditions, condition)
+		expr.results = append(expr.results, result)
+	}
+
+	if len(expr.conditions) == 0 {
+		return nil, sqlError(p.peek().pos, "Expected WHEN")
+	}
+
+	if p.keyword("ELSE") {
+		var err error
+
+		if expr.otherwise, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return expr, p.expectKeyword("END")
+}
@@ -0,0 +1,156 @@
+package fio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testSQLDatabase(t *testing.T) (*SQLDatabase, string) {
+	directory := t.TempDir()
+	filename := filepath.Join(directory, "sales.csv")
+	data := "region,year,amount,code\n" +
+		"north,2025,10.50,007\n" +
+		"south,2025,5,008\n" +
+		"north,2024,100,009\n" +
+		"north,2025,2,010\n" +
+		"east,2025,,011\n"
+
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write test file, error: %s\n", err.Error())
+	}
+
+	regions := NewSpreadsheetDelim(16, ",")
+	regions.Header = true
+	regions.Data = [][]string{
+		{"region", "manager"},
+		{"north", "Ann"},
+		{"south", "Bob"},
+		{"west", "Eve"},
+	}
+
+	db := NewSQLDatabase(64, ",")
+	db.AddTable("regions", regions)
+	return db, filename
+}
+
+func TestSQLDatabaseQuery(t *testing.T) {
+	db, filename := testSQLDatabase(t)
+	from := "'" + filename + "'"
+
+	tests := [...]struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT region, SUM(amount) FROM " + from + " WHERE year = 2025 GROUP BY region", [][]string{
+			{"region", "SUM(amount)"},
+			{"north", "12.5"},
+			{"south", "5"},
+			{"east", ""},
+		}},
+		{"SELECT region, COUNT(*) AS n, AVG(amount) avg FROM " + from + " GROUP BY 1 HAVING COUNT(amount) > 0 ORDER BY n DESC, region", [][]string{
+			{"region", "n", "avg"},
+			{"north", "3", "37.5"},
+			{"south", "1", "5"},
+		}},
+		{"SELECT * FROM " + from + " WHERE amount IS NULL OR code = '008'", [][]string{
+			{"region", "year", "amount", "code"},
+			{"south", "2025", "5", "008"},
+			{"east", "2025", "", "011"},
+		}},
+		{"SELECT DISTINCT UPPER(region) r FROM " + from + " ORDER BY r LIMIT 2 OFFSET 1", [][]string{
+			{"r"},
+			{"NORTH"},
+			{"SOUTH"},
+		}},
+		{"SELECT s.region, r.manager, s.amount * 2 FROM " + from + " AS s LEFT JOIN regions r ON s.region = r.region WHERE s.year BETWEEN 2025 AND 2026 ORDER BY s.amount", [][]string{
+			{"region", "manager", "s.amount * 2"},
+			{"north", "Ann", "4"},
+			{"south", "Bob", "10"},
+			{"north", "Ann", "21"},
+			{"east", "", ""},
+		}},
+		{"SELECT manager, SUBSTR(manager, 2, 1) || '-' || LENGTH(manager), CASE WHEN region IN ('north', 'south') THEN 'yes' ELSE 'no' END FROM regions WHERE manager NOT LIKE 'b%'", [][]string{
+			{"manager", "SUBSTR(manager, 2, 1) || '-' || LENGTH(manager)", "CASE WHEN region IN ('north', 'south') THEN 'yes' ELSE 'no' END"},
+			{"Ann", "n-3", "yes"},
+			{"Eve", "v-3", "no"},
+		}},
+		{"SELECT r.manager, COUNT(s.amount), MAX(s.year) FROM regions r INNER JOIN " + from + " s ON s.region = r.region AND s.amount > 3 GROUP BY r.manager", [][]string{
+			{"manager", "COUNT(s.amount)", "MAX(s.year)"},
+			{"Ann", "2", "2025"},
+			{"Bob", "1", "2025"},
+		}},
+		{"SELECT COUNT(*), SUM(LENGTH(manager)), ROUND(7 / 2), 7 % 4, 1 / 0, COALESCE(NULL, 'x') FROM regions WHERE manager = 'nobody'", [][]string{
+			{"COUNT(*)", "SUM(LENGTH(manager))", "ROUND(7 / 2)", "7 % 4", "1 / 0", "COALESCE(NULL, 'x')"},
+			{"0", "", "4", "3", "", "x"},
+		}},
+	}
+
+	for i, test := range tests {
+		result, err := db.Query(test.query)
+
+		if err != nil {
+			t.Errorf("Test %d: query failed, error: %s\n", i, err.Error())
+		} else if !reflect.DeepEqual(result.Data, test.expected) || !result.Header {
+			t.Errorf("Test %d: %q != %q\n", i, result.Data, test.expected)
+		}
+	}
+}
+
+func TestSQLDatabaseErrors(t *testing.T) {
+	db, filename := testSQLDatabase(t)
+	failures := [...]string{
+		"SELECT",
+		"SELECT region FROM regions WHERE",
+		"SELECT region FROM regions extra tokens",
+		"SELECT 'unterminated FROM regions",
+		"SELECT unknown FROM regions",
+		"SELECT region FROM regions r JOIN '" + filename + "' s ON r.region = s.region",
+		"SELECT region FROM missing",
+		"SELECT region FROM regions WHERE COUNT(*) > 1",
+		"SELECT NOSUCHFUNCTION(region) FROM regions",
+		"SELECT region FROM regions LIMIT -1",
+		"SELECT region + 1 FROM regions",
+	}
+
+	for _, query := range failures {
+		if _, err := db.Query(query); err == nil {
+			t.Errorf("Expected query '%s' to fail\n", query)
+		}
+	}
+
+	if err := db.AddTable("REGIONS", NewSpreadsheetDelim(16, ",")); err == nil {
+		t.Errorf("Expected adding an existing table to fail\n")
+	}
+}
+
+func TestSQLLikeMatch(t *testing.T) {
+	tests := []struct {
+		value   string
+		pattern string
+		match   bool
+	}{
+		{"", "", true},
+		{"", "%", true},
+		{"", "_", false},
+		{"abc", "abc", true},
+		{"abc", "a_c", true},
+		{"abc", "a%", true},
+		{"abc", "%c", true},
+		{"abc", "%b%", true},
+		{"abc", "%%a%%c%%", true},
+		{"abc", "ab", false},
+		{"abc", "%d%", false},
+		{"abcbd", "a%bd", true},
+		{"abcbc", "a%bd", false},
+		{"mississippi", "m%iss%ppi", true},
+		{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "%a%a%a%a%a%a%a%a%a%a%a%b", false},
+	}
+
+	for _, test := range tests {
+		if match := sqlLikeMatch([]rune(test.value), []rune(test.pattern)); match != test.match {
+			t.Errorf("Expected matching '%s' to '%s' to be %t\n", test.value, test.pattern, test.match)
+		}
+	}
+}
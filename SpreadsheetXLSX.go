@@ -0,0 +1,833 @@
+package fio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//The XML structures used to decode the parts of an Office Open XML workbook.
+//Only the elements required to retrieve the cell values are declared.
+type xlsxWorkbookXML struct {
+	Properties struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichTextXML struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+//text returns the complete text of a (possibly rich) string
+func (rt *xlsxRichTextXML) text() string {
+	if len(rt.R) == 0 {
+		return rt.T
+	}
+
+	var buffer bytes.Buffer
+
+	for _, r := range rt.R {
+		buffer.WriteString(r.T)
+	}
+
+	return buffer.String()
+}
+
+type xlsxSharedStringsXML struct {
+	Items []xlsxRichTextXML `xml:"si"`
+}
+
+type xlsxStylesXML struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxWorksheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string          `xml:"r,attr"`
+			T      string          `xml:"t,attr"`
+			S      int             `xml:"s,attr"`
+			V      string          `xml:"v"`
+			Inline xlsxRichTextXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+//xlsxSheet is a single named sheet as it is read from or written to a workbook
+type xlsxSheet struct {
+	name  string
+	data  [][]string
+	types [][]CellType
+}
+
+//The base dates of the two date systems that can be used by a workbook. The
+//1900 base date is moved back a day to compensate for 1900 being treated as a
+//leap year.
+var (
+	xlsxEpoch1900 = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	xlsxEpoch1904 = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+//xlsxColumnName converts a zero-based column index to its column letters (A,
+//B, ..., Z, AA, AB, ...).
+func xlsxColumnName(col int) string {
+	var name []byte
+
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+
+	return string(name)
+}
+
+//xlsxParseCellReference converts a cell reference such as 'AB12' to a
+//zero-based row and column index.
+func xlsxParseCellReference(ref string) (row, col int, ok bool) {
+	i := 0
+
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A') + 1
+	}
+
+	if i == 0 || i == len(ref) {
+		return 0, 0, false
+	}
+
+	row, err := strconv.Atoi(ref[i:])
+
+	if err != nil || row < 1 {
+		return 0, 0, false
+	}
+
+	return row - 1, col - 1, true
+}
+
+//xlsxIsDateFormat determines if a number format displays a date and/or time.
+//The built-in formats are known by their identifier, custom formats are
+//considered dates if they contain date or time placeholders outside of quoted
+//text and brackets.
+func xlsxIsDateFormat(id int, code string) bool {
+	if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) {
+		return true
+	}
+
+	if len(code) == 0 {
+		return false
+	}
+
+	inQuote, inBracket := false, false
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '\\':
+			i++
+		case c == '[':
+			inBracket = true
+		case c == ']':
+			inBracket = false
+		case inBracket:
+		case strings.IndexByte("dmyhsDMYHS", c) != -1:
+			return true
+		}
+	}
+
+	return false
+}
+
+//xlsxSerialToTime converts a date serial number to a time, rounded to the
+//nearest second.
+func xlsxSerialToTime(serial float64, date1904 bool) time.Time {
+	epoch := xlsxEpoch1900
+
+	if date1904 {
+		epoch = xlsxEpoch1904
+	}
+
+	seconds := math.Round(serial * 24 * 60 * 60)
+	return epoch.Add(time.Duration(seconds) * time.Second)
+}
+
+//xlsxTimeToSerial converts a time to a date serial number in the 1900 date
+//system
+func xlsxTimeToSerial(t time.Time) float64 {
+	return t.Sub(xlsxEpoch1900).Seconds() / (24 * 60 * 60)
+}
+
+//xlsxReadPart decodes a single XML part from the archive. If the part does not
+//exist and it is optional then no error will be returned.
+func xlsxReadPart(archive *zip.Reader, name string, optional bool, v interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+
+		if err != nil {
+			return Error{ErrorTypeLoading, "SpreadsheetXLSX", "Failed to open part '" + name + "'"}
+		}
+
+		err = xml.NewDecoder(reader).Decode(v)
+		reader.Close()
+
+		if err != nil {
+			return Error{ErrorTypeParsing, "SpreadsheetXLSX", "Failed to decode part '" + name + "': " + err.Error()}
+		}
+
+		return nil
+	}
+
+	if optional {
+		return nil
+	}
+
+	return Error{ErrorTypeParsing, "SpreadsheetXLSX", "Missing part '" + name + "'"}
+}
+
+//xlsxReadWorkbook reads the sheets of a workbook. If load is nil only the
+//sheet names are retrieved, otherwise the sheets for which load returns true
+//are fully loaded.
+func xlsxReadWorkbook(filename string, load func(index int, name string) bool) ([]xlsxSheet, error) {
+	archive, err := zip.OpenReader(filename)
+
+	if err != nil {
+		return nil, Error{ErrorTypeLoading, "SpreadsheetXLSX", "Failed to open the workbook"}
+	}
+
+	defer archive.Close()
+
+	//read the list of sheets and the relationships pointing to their parts
+	var workbook xlsxWorkbookXML
+	var relationships xlsxRelationshipsXML
+
+	if err = xlsxReadPart(&archive.Reader, "xl/workbook.xml", false, &workbook); err != nil {
+		return nil, err
+	}
+
+	if err = xlsxReadPart(&archive.Reader, "xl/_rels/workbook.xml.rels", false, &relationships); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string)
+
+	for _, relationship := range relationships.Relationships {
+		if strings.HasPrefix(relationship.Target, "/") {
+			targets[relationship.ID] = relationship.Target[1:]
+		} else {
+			targets[relationship.ID] = path.Join("xl", relationship.Target)
+		}
+	}
+
+	//the shared strings and styles are only needed if a sheet is loaded
+	var sharedStrings xlsxSharedStringsXML
+	var styles xlsxStylesXML
+	var dateStyles []bool
+	date1904 := workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+
+	for index, entry := range workbook.Sheets {
+		sheet := xlsxSheet{name: entry.Name}
+
+		if load == nil || !load(index, entry.Name) {
+			sheets = append(sheets, sheet)
+			continue
+		}
+
+		if dateStyles == nil {
+			if err = xlsxReadPart(&archive.Reader, "xl/sharedStrings.xml", true, &sharedStrings); err != nil {
+				return nil, err
+			}
+
+			if err = xlsxReadPart(&archive.Reader, "xl/styles.xml", true, &styles); err != nil {
+				return nil, err
+			}
+
+			//determine which cell styles display dates
+			codes := make(map[int]string)
+
+			for _, numFmt := range styles.NumFmts {
+				codes[numFmt.ID] = numFmt.Code
+			}
+
+			dateStyles = make([]bool, len(styles.CellXfs))
+
+			for i, xf := range styles.CellXfs {
+				dateStyles[i] = xlsxIsDateFormat(xf.NumFmtID, codes[xf.NumFmtID])
+			}
+		}
+
+		target, ok := targets[entry.ID]
+
+		if !ok {
+			return nil, Error{ErrorTypeParsing, "SpreadsheetXLSX", "No part found for sheet '" + entry.Name + "'"}
+		}
+
+		var worksheet xlsxWorksheetXML
+
+		if err = xlsxReadPart(&archive.Reader, target, false, &worksheet); err != nil {
+			return nil, err
+		}
+
+		//convert all cells to strings, remembering their types
+		nextRow := 0
+
+		for _, xmlRow := range worksheet.Rows {
+			row := nextRow
+
+			if xmlRow.R > 0 {
+				row = xmlRow.R - 1
+			}
+
+			nextRow = row + 1
+			nextCol := 0
+
+			for _, cell := range xmlRow.Cells {
+				col := nextCol
+
+				if len(cell.R) != 0 {
+					_, refCol, ok := xlsxParseCellReference(cell.R)
+
+					if !ok {
+						return nil, Error{ErrorTypeParsing, "SpreadsheetXLSX", "Invalid cell reference '" + cell.R + "'"}
+					}
+
+					col = refCol
+				}
+
+				nextCol = col + 1
+				value, cellType := cell.V, CellTypeString
+
+				switch cell.T {
+				case "s":
+					index, err := strconv.Atoi(cell.V)
+
+					if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+						return nil, Error{ErrorTypeParsing, "SpreadsheetXLSX", "Invalid shared string index at cell " + xlsxColumnName(col) + strconv.Itoa(row+1)}
+					}
+
+					value = sharedStrings.Items[index].text()
+				case "inlineStr":
+					value = cell.Inline.text()
+				case "b":
+					value, cellType = cellBoolFalse, CellTypeBool
+
+					if cell.V == "1" {
+						value = cellBoolTrue
+					}
+				case "d":
+					t, err := time.Parse(time.RFC3339, cell.V)
+
+					if err != nil {
+						t, err = time.Parse("2006-01-02T15:04:05", cell.V)
+					}
+
+					if err == nil {
+						value, cellType = formatCellTime(t), CellTypeDate
+					}
+				case "", "n":
+					if len(cell.V) == 0 {
+						continue
+					}
+
+					cellType = CellTypeNumber
+
+					if cell.S >= 0 && cell.S < len(dateStyles) && dateStyles[cell.S] {
+						serial, err := strconv.ParseFloat(cell.V, 64)
+
+						if err == nil {
+							value, cellType = formatCellTime(xlsxSerialToTime(serial, date1904)), CellTypeDate
+						}
+					}
+				}
+
+				//grow the sheet to contain the cell
+				for len(sheet.data) <= row {
+					sheet.data = append(sheet.data, []string{})
+					sheet.types = append(sheet.types, []CellType{})
+				}
+
+				for len(sheet.data[row]) <= col {
+					sheet.data[row] = append(sheet.data[row], "")
+					sheet.types[row] = append(sheet.types[row], CellTypeString)
+				}
+
+				sheet.data[row][col] = value
+				sheet.types[row][col] = cellType
+			}
+		}
+
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets, nil
+}
+
+//xlsxWriteEscaped writes a string to the buffer using XML escaping
+func xlsxWriteEscaped(buffer *bytes.Buffer, s string) {
+	xml.EscapeText(buffer, []byte(s))
+}
+
+//The static parts of a written workbook. Style 1 displays dates, style 2
+//displays dates including the time of day.
+const (
+	xlsxContentTypesHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	xlsxRootRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/></numFmts><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+)
+
+//xlsxWriteWorkbook writes the sheets to a new workbook. Strings are written as
+//inline strings, so no shared strings part is required.
+func xlsxWriteWorkbook(filename string, sheets []xlsxSheet) error {
+	if len(sheets) == 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetXLSX", "A workbook requires at least one sheet"}
+	}
+
+	//build all parts in memory first, so an invalid value does not leave a
+	//half-written file behind
+	parts := make(map[string]*bytes.Buffer)
+	var order []string
+	addPart := func(name string) *bytes.Buffer {
+		buffer := &bytes.Buffer{}
+		parts[name] = buffer
+		order = append(order, name)
+		return buffer
+	}
+
+	contentTypes := addPart("[Content_Types].xml")
+	contentTypes.WriteString(xlsxContentTypesHead)
+	addPart("_rels/.rels").WriteString(xlsxRootRelationships)
+
+	workbook := addPart("xl/workbook.xml")
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+
+	relationships := addPart("xl/_rels/workbook.xml.rels")
+	relationships.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	relationships.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	addPart("xl/styles.xml").WriteString(xlsxStyles)
+
+	for i, sheet := range sheets {
+		id := strconv.Itoa(i + 1)
+		part := "worksheets/sheet" + id + ".xml"
+
+		contentTypes.WriteString(`<Override PartName="/xl/` + part + `" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		workbook.WriteString(`<sheet name="`)
+		xlsxWriteEscaped(workbook, sheet.name)
+		workbook.WriteString(`" sheetId="` + id + `" r:id="rId` + id + `"/>`)
+		relationships.WriteString(`<Relationship Id="rId` + id + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="` + part + `"/>`)
+
+		worksheet := addPart("xl/" + part)
+		worksheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+		worksheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+		for r, row := range sheet.data {
+			rowRef := strconv.Itoa(r + 1)
+			worksheet.WriteString(`<row r="` + rowRef + `">`)
+
+			for c, value := range row {
+				cellType := inferCellType(value)
+
+				if r < len(sheet.types) && c < len(sheet.types[r]) {
+					cellType = sheet.types[r][c]
+				}
+
+				ref := xlsxColumnName(c) + rowRef
+
+				switch cellType {
+				case CellTypeNumber:
+					if !validCellNumber(value) {
+						return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Invalid number at cell " + ref}
+					}
+
+					worksheet.WriteString(`<c r="` + ref + `"><v>` + value + `</v></c>`)
+				case CellTypeBool:
+					b, err := strconv.ParseBool(value)
+
+					if err != nil {
+						return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Invalid boolean at cell " + ref}
+					}
+
+					v := "0"
+
+					if b {
+						v = "1"
+					}
+
+					worksheet.WriteString(`<c r="` + ref + `" t="b"><v>` + v + `</v></c>`)
+				case CellTypeDate:
+					t, err := parseCellTime(value)
+
+					if err != nil {
+						return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Invalid date at cell " + ref}
+					}
+
+					style := "1"
+
+					if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+						style = "2"
+					}
+
+					serial := strconv.FormatFloat(xlsxTimeToSerial(t), 'f', -1, 64)
+					worksheet.WriteString(`<c r="` + ref + `" s="` + style + `"><v>` + serial + `</v></c>`)
+				default:
+					if len(value) == 0 {
+						continue
+					}
+
+					worksheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+					xlsxWriteEscaped(worksheet, value)
+					worksheet.WriteString(`</t></is></c>`)
+				}
+			}
+
+			worksheet.WriteString(`</row>`)
+		}
+
+		worksheet.WriteString(`</sheetData></worksheet>`)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	relationships.WriteString(`<Relationship Id="rId` + strconv.Itoa(len(sheets)+1) + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`)
+
+	//write all parts to the archive
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Failed to create/open file for writing"}
+	}
+
+	archive := zip.NewWriter(file)
+
+	for _, name := range order {
+		writer, err := archive.Create(name)
+
+		if err == nil {
+			_, err = io.Copy(writer, parts[name])
+		}
+
+		if err != nil {
+			archive.Close()
+			file.Close()
+			return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Failed to write part '" + name + "'"}
+		}
+	}
+
+	err = archive.Close()
+
+	if err != nil {
+		file.Close()
+		return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Failed to finish the workbook archive"}
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "SpreadsheetXLSX", "Failed to close the file after saving"}
+	}
+
+	return nil
+}
+
+//XLSXSheetNames returns the names of all sheets within the specified Office
+//Open XML workbook, in the order in which they appear in the workbook.
+func XLSXSheetNames(filename string) ([]string, error) {
+	sheets, err := xlsxReadWorkbook(filename, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sheets))
+
+	for _, sheet := range sheets {
+		names = append(names, sheet.name)
+	}
+
+	return names, nil
+}
+
+//The SpreadsheetXLSX type represents a single sheet within an Office Open XML
+//workbook (.xlsx file). It implements the Spreadsheeter interface. All values
+//are stored as strings, the Types field holds the type of each value. Dates are
+//stored using the CellDateLayout or CellDateTimeLayout layouts, booleans as
+//TRUE or FALSE. New instances should be created using the
+//NewSpreadsheetXLSX(...) function.
+type SpreadsheetXLSX struct {
+	Filename   string
+	Sheet      string //name of the sheet to load and save, takes precedence over SheetIndex
+	SheetIndex int    //zero-based index of the sheet to load if Sheet is empty
+	Data       [][]string
+	Types      [][]CellType
+}
+
+//NewSpreadsheetXLSX will create a new instance of the SpreadsheetXLSX type and
+//return its pointer. The sheet name is used to select the sheet to load, and is
+//used as the name of the sheet when saving. If the sheet name is empty then the
+//first sheet will be loaded.
+func NewSpreadsheetXLSX(sheet string) *SpreadsheetXLSX {
+	return &SpreadsheetXLSX{"", sheet, 0, nil, nil}
+}
+
+//Load will load a single sheet from a workbook into the SpreadsheetXLSX
+//instance, replacing any previously loaded data. The sheet is selected by the
+//Sheet field, or by the SheetIndex field if the Sheet field is empty. If an
+//empty filename is specified then the filename from the previous Load(...) call
+//will be used.
+func (sx *SpreadsheetXLSX) Load(filename string) error {
+	//check if a valid filename exists
+	if len(filename) == 0 {
+		if len(sx.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetXLSX", "No filename specified to load"}
+		}
+
+		filename = sx.Filename
+	} else {
+		sx.Filename = filename
+	}
+
+	found := -1
+	sheets, err := xlsxReadWorkbook(filename, func(index int, name string) bool {
+		if found == -1 && ((len(sx.Sheet) != 0 && name == sx.Sheet) || (len(sx.Sheet) == 0 && index == sx.SheetIndex)) {
+			found = index
+			return true
+		}
+
+		return false
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if found == -1 {
+		return Error{ErrorTypeNotFound, "SpreadsheetXLSX", "The selected sheet does not exist in the workbook"}
+	}
+
+	sx.Sheet = sheets[found].name
+	sx.SheetIndex = found
+	sx.Data = sheets[found].data
+	sx.Types = sheets[found].types
+	return nil
+}
+
+//Save will save the current contents from the SpreadsheetXLSX type to a new
+//workbook containing a single sheet. A filename can be specified, if an empty
+//filename is specified then the filename used for the last call to the
+//Load(...) function is used.
+func (sx *SpreadsheetXLSX) Save(filename string) error {
+	//check if a filename is specified
+	if len(filename) == 0 {
+		if len(sx.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "SpreadsheetXLSX", "No filename specified to save"}
+		}
+
+		filename = sx.Filename
+	}
+
+	name := sx.Sheet
+
+	if len(name) == 0 {
+		name = "Sheet1"
+	}
+
+	return xlsxWriteWorkbook(filename, []xlsxSheet{{name, sx.Data, sx.Types}})
+}
+
+//Set will set a specifed value at the location of the specified row and column.
+//Intermediate rows and columns will be created if the specified location does
+//not yet exist. Numeric values are stored as numbers, all other values as
+//strings. Use SetTyped(...) to store booleans and dates.
+func (sx *SpreadsheetXLSX) Set(row, col int, value string) error {
+	return sx.SetTyped(row, col, value, inferCellType(value))
+}
+
+//SetTyped will set a specified value of the specified type at the location of
+//the specified row and column. Intermediate rows and columns will be created if
+//the specified location does not yet exist.
+func (sx *SpreadsheetXLSX) SetTyped(row, col int, value string, cellType CellType) error {
+	if row < 0 || col < 0 {
+		return Error{ErrorTypeInvalidArgument, "SpreadsheetXLSX", "Negative row or column specified"}
+	}
+
+	//append non-existant rows
+	for i := len(sx.Data); i <= row; i++ {
+		sx.Data = append(sx.Data, []string{})
+	}
+
+	for i := len(sx.Types); i < len(sx.Data); i++ {
+		sx.Types = append(sx.Types, []CellType{})
+	}
+
+	//append non-existant columns
+	for i := len(sx.Data[row]); i <= col; i++ {
+		sx.Data[row] = append(sx.Data[row], "")
+	}
+
+	for i := len(sx.Types[row]); i < len(sx.Data[row]); i++ {
+		sx.Types[row] = append(sx.Types[row], CellTypeString)
+	}
+
+	//set specified value
+	sx.Data[row][col] = value
+	sx.Types[row][col] = cellType
+	return nil
+}
+
+//Get will retrieve a value from the location of the specified row and column.
+//In case the location does not exist within the current bounds of the
+//spreadsheet then the function will return false.
+func (sx *SpreadsheetXLSX) Get(row, col int) (string, bool) {
+	if row < 0 || row >= len(sx.Data) {
+		return "", false
+	}
+
+	if col < 0 || col >= len(sx.Data[row]) {
+		return "", false
+	}
+
+	return sx.Data[row][col], true
+}
+
+//GetType will retrieve the type of the value at the location of the specified
+//row and column. In case the location does not exist the function will return
+//false.
+func (sx *SpreadsheetXLSX) GetType(row, col int) (CellType, bool) {
+	if _, ok := sx.Get(row, col); !ok {
+		return CellTypeString, false
+	}
+
+	if row >= len(sx.Types) || col >= len(sx.Types[row]) {
+		return inferCellType(sx.Data[row][col]), true
+	}
+
+	return sx.Types[row][col], true
+}
+
+//See Get(...), includes a conversion to int. In case the conversion fails the
+//error will be non-nil
+func (sx *SpreadsheetXLSX) GetInt(row, col int) (int, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to int
+		result, err := strconv.Atoi(str)
+		return result, true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to uint. In case the conversion fails the
+//error will be non-nil
+func (sx *SpreadsheetXLSX) GetUint(row, col int) (uint, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to uint
+		result, err := strconv.ParseUint(str, 10, strconv.IntSize)
+		return uint(result), true, err
+	}
+
+	//not found
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float32. In case the conversion fails the
+//error will be non-nil
+func (sx *SpreadsheetXLSX) GetFloat32(row, col int) (float32, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float32
+		result, err := strconv.ParseFloat(str, 32)
+		return float32(result), true, err
+	}
+
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to float64. In case the conversion fails the
+//error will be non-nil
+func (sx *SpreadsheetXLSX) GetFloat64(row, col int) (float64, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to float64
+		result, err := strconv.ParseFloat(str, 64)
+		return result, true, err
+	}
+
+	return 0, false, nil
+}
+
+//See Get(...), includes a conversion to bool. In case the conversion fails the
+//error will be non-nil
+func (sx *SpreadsheetXLSX) GetBool(row, col int) (bool, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to bool
+		result, err := strconv.ParseBool(str)
+		return result, true, err
+	}
+
+	return false, false, nil
+}
+
+//See Get(...), includes a conversion to time.Time. In case the conversion fails
+//the error will be non-nil
+func (sx *SpreadsheetXLSX) GetTime(row, col int) (time.Time, bool, error) {
+	//use Get(...)
+	str, ok := sx.Get(row, col)
+
+	if ok {
+		//found variable, attempt conversion to time.Time
+		result, err := parseCellTime(str)
+		return result, true, err
+	}
+
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,157 @@
+package fio
+
+import (
+	"archive/zip"
+	"os"
+	"strconv"
+	"testing"
+)
+
+const testFilenameSpreadsheetXLSX = "test_file.xlsx"
+
+func TestSpreadsheetXLSX(t *testing.T) {
+	sx := NewSpreadsheetXLSX("Data")
+
+	for row := 0; row < 20; row++ {
+		sx.Set(row, 0, "name <"+strconv.Itoa(row)+"> & co ")
+		sx.Set(row, 1, strconv.Itoa(row*3))
+		sx.Set(row, 2, strconv.FormatFloat(float64(row)/4, 'f', -1, 64))
+		sx.SetTyped(row, 3, strconv.FormatBool(row%2 == 0), CellTypeBool)
+		sx.SetTyped(row, 4, "2024-02-"+strconv.Itoa(10+row), CellTypeDate)
+		sx.SetTyped(row, 5, "2024-02-10 13:"+strconv.Itoa(10+row)+":05", CellTypeDate)
+	}
+
+	sx.Set(25, 30, "far away")
+
+	err := sx.Save(testFilenameSpreadsheetXLSX)
+
+	if err != nil {
+		t.Fatalf("Failed to write to file, error: %s\n", err.Error())
+	}
+
+	defer os.Remove(testFilenameSpreadsheetXLSX)
+
+	names, err := XLSXSheetNames(testFilenameSpreadsheetXLSX)
+
+	if err != nil || len(names) != 1 || names[0] != "Data" {
+		t.Errorf("Unexpected sheet names %v (error: %v)\n", names, err)
+	}
+
+	//reload by index and check all values and types
+	sx2 := NewSpreadsheetXLSX("")
+	err = sx2.Load(testFilenameSpreadsheetXLSX)
+
+	if err != nil {
+		t.Fatalf("Failed to reload the file, error: %s\n", err.Error())
+	}
+
+	if sx2.Sheet != "Data" {
+		t.Errorf("Loaded sheet '%s' instead of 'Data'\n", sx2.Sheet)
+	}
+
+	for row := 0; row < 20; row++ {
+		for col := 0; col < 6; col++ {
+			expected, _ := sx.Get(row, col)
+			expectedType, _ := sx.GetType(row, col)
+			value, ok := sx2.Get(row, col)
+			valueType, _ := sx2.GetType(row, col)
+
+			if col == 3 {
+				//booleans are normalized to TRUE and FALSE
+				b, _, _ := sx.GetBool(row, col)
+				expected = cellBoolFalse
+
+				if b {
+					expected = cellBoolTrue
+				}
+			}
+
+			if !ok || value != expected || valueType != expectedType {
+				t.Errorf("'%s' (type %d) [r:%d, c:%d] != '%s' (type %d)\n", value, valueType, row, col, expected, expectedType)
+			}
+		}
+	}
+
+	if value, _ := sx2.Get(25, 30); value != "far away" {
+		t.Errorf("'%s' [r:25, c:30] != 'far away'\n", value)
+	}
+
+	sx3 := NewSpreadsheetXLSX("Missing")
+
+	if err = sx3.Load(testFilenameSpreadsheetXLSX); err == nil {
+		t.Errorf("Expected loading a non-existant sheet to fail\n")
+	}
+
+	//numbers Excel cannot read are rejected
+	for _, value := range []string{"NaN", "Inf", "-inf", "0x1p-2", "1e400", "1_000"} {
+		invalid := NewSpreadsheetXLSX("Invalid")
+		invalid.SetTyped(0, 0, value, CellTypeNumber)
+
+		if err = invalid.Save(testFilenameSpreadsheetXLSX); err == nil {
+			t.Errorf("Expected saving the number '%s' to fail\n", value)
+		}
+	}
+}
+
+func TestSpreadsheetXLSXSharedStrings(t *testing.T) {
+	//write a workbook as produced by spreadsheet applications, using shared
+	//strings, rich text, custom date formats and the 1904 date system
+	parts := [...][2]string{
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><workbookPr date1904="1"/><sheets><sheet name="First" sheetId="1" r:id="rId1"/><sheet name="Second" sheetId="2" r:id="rId2"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/other.xml"/></Relationships>`},
+		{"xl/sharedStrings.xml", `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>plain</t></si><si><r><t>ri</t></r><r><t>ch</t></r></si></sst>`},
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts><numFmt numFmtId="170" formatCode="[Red]&quot;day&quot; dd/mm/yyyy"/><numFmt numFmtId="171" formatCode="&quot;ddd&quot;0.00"/></numFmts><cellXfs><xf numFmtId="0"/><xf numFmtId="170"/><xf numFmtId="171"/></cellXfs></styleSheet>`},
+		{"xl/worksheets/sheet1.xml", `<worksheet/>`},
+		{"xl/worksheets/other.xml", `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="2"><c r="B2" t="s"><v>1</v></c><c t="s"><v>0</v></c><c r="E2" s="1"><v>0</v></c><c s="2"><v>1.5</v></c><c t="b"><v>1</v></c></row></sheetData></worksheet>`},
+	}
+
+	file, err := os.Create(testFilenameSpreadsheetXLSX)
+
+	if err != nil {
+		t.Fatalf("Failed to create test workbook\n")
+	}
+
+	defer os.Remove(testFilenameSpreadsheetXLSX)
+	archive := zip.NewWriter(file)
+
+	for _, part := range parts {
+		writer, _ := archive.Create(part[0])
+		writer.Write([]byte(part[1]))
+	}
+
+	archive.Close()
+	file.Close()
+
+	sx := NewSpreadsheetXLSX("")
+	sx.SheetIndex = 1
+	err = sx.Load(testFilenameSpreadsheetXLSX)
+
+	if err != nil {
+		t.Fatalf("Failed to load the workbook, error: %s\n", err.Error())
+	}
+
+	expected := [...]struct {
+		col      int
+		value    string
+		cellType CellType
+	}{
+		{1, "rich", CellTypeString},
+		{2, "plain", CellTypeString},
+		{4, "1904-01-01", CellTypeDate},
+		{5, "1.5", CellTypeNumber},
+		{6, "TRUE", CellTypeBool},
+	}
+
+	for _, e := range expected {
+		value, _ := sx.Get(1, e.col)
+		valueType, _ := sx.GetType(1, e.col)
+
+		if value != e.value || valueType != e.cellType {
+			t.Errorf("'%s' (type %d) [r:1, c:%d] != '%s' (type %d)\n", value, valueType, e.col, e.value, e.cellType)
+		}
+	}
+
+	if _, ok := sx.Get(0, 0); ok {
+		t.Errorf("Expected the first row to be empty\n")
+	}
+}
@@ -0,0 +1,453 @@
+package fio
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//workbookSheets stores the ordered, named sheets of a workbook. It implements
+//the sheet management methods of the Workbooker interface for the various
+//workbook types. New sheets are created using the create function, and names
+//are checked using the validate function.
+type workbookSheets struct {
+	source   string
+	names    []string
+	sheets   map[string]Spreadsheeter
+	create   func(name string) Spreadsheeter
+	validate func(name string) bool
+}
+
+//reset removes all sheets
+func (ws *workbookSheets) reset() {
+	ws.names = nil
+	ws.sheets = make(map[string]Spreadsheeter)
+}
+
+//Sheets returns the names of all sheets in the workbook, in order
+func (ws *workbookSheets) Sheets() []string {
+	return append([]string(nil), ws.names...)
+}
+
+//Sheet returns the sheet with the specified name. If the sheet does not exist
+//the function will return false.
+func (ws *workbookSheets) Sheet(name string) (Spreadsheeter, bool) {
+	sheet, ok := ws.sheets[name]
+	return sheet, ok
+}
+
+//AddSheet will create a new, empty sheet with the specified name at the end of
+//the workbook and return it. If a sheet with the same name already exists or
+//the name is invalid then an error will be returned.
+func (ws *workbookSheets) AddSheet(name string) (Spreadsheeter, error) {
+	if !ws.validate(name) {
+		return nil, Error{ErrorTypeInvalidArgument, ws.source, "Invalid sheet name '" + name + "'"}
+	}
+
+	if _, ok := ws.sheets[name]; ok {
+		return nil, Error{ErrorTypeExists, ws.source, "Sheet '" + name + "' already exists"}
+	}
+
+	sheet := ws.create(name)
+	ws.names = append(ws.names, name)
+	ws.sheets[name] = sheet
+	return sheet, nil
+}
+
+//RemoveSheet will remove the sheet with the specified name from the workbook.
+//If the sheet doesn't exist an error will be returned.
+func (ws *workbookSheets) RemoveSheet(name string) error {
+	if _, ok := ws.sheets[name]; !ok {
+		return Error{ErrorTypeNotFound, ws.source, "Sheet '" + name + "' does not exist"}
+	}
+
+	for i, n := range ws.names {
+		if n == name {
+			ws.names = append(ws.names[:i], ws.names[i+1:]...)
+			break
+		}
+	}
+
+	delete(ws.sheets, name)
+	return nil
+}
+
+//rename will change the name of a sheet while retaining its position. If the
+//sheet doesn't exist, the new name is invalid or the new name is already used
+//by another sheet an error will be returned.
+func (ws *workbookSheets) rename(name, newName string) (Spreadsheeter, error) {
+	sheet, ok := ws.sheets[name]
+
+	if !ok {
+		return nil, Error{ErrorTypeNotFound, ws.source, "Sheet '" + name + "' does not exist"}
+	}
+
+	if !ws.validate(newName) {
+		return nil, Error{ErrorTypeInvalidArgument, ws.source, "Invalid sheet name '" + newName + "'"}
+	}
+
+	if name == newName {
+		return sheet, nil
+	}
+
+	if _, ok := ws.sheets[newName]; ok {
+		return nil, Error{ErrorTypeExists, ws.source, "Sheet '" + newName + "' already exists"}
+	}
+
+	for i, n := range ws.names {
+		if n == name {
+			ws.names[i] = newName
+			break
+		}
+	}
+
+	delete(ws.sheets, name)
+	ws.sheets[newName] = sheet
+	return sheet, nil
+}
+
+//WorkbookFormat is the type used to specify the file format of a Workbook
+type WorkbookFormat byte
+
+//The various formats to use in conjunction with the WorkbookFormat type
+const (
+	WorkbookFormatXLSX WorkbookFormat = iota //Office Open XML workbook, sheets are *SpreadsheetXLSX
+	WorkbookFormatODS                        //OpenDocument spreadsheet, sheets are *SpreadsheetODS
+)
+
+//Workbook represents a file containing multiple sheets, either an .xlsx or an
+//.ods file. It implements the Workbooker interface. Depending on the format,
+//each sheet is a *SpreadsheetXLSX or a *SpreadsheetODS instance, which can be
+//retrieved through a type assertion to access the cell types. New instances
+//should be created using the NewWorkbook(...) function.
+type Workbook struct {
+	workbookSheets
+	Filename string
+	format   WorkbookFormat
+}
+
+//NewWorkbook creates a new, empty Workbook instance of the specified format and
+//returns its pointer.
+func NewWorkbook(format WorkbookFormat) *Workbook {
+	wb := &Workbook{Filename: "", format: format}
+	wb.source = "Workbook"
+	wb.create = wb.createSheet
+	wb.validate = wb.validateName
+	wb.reset()
+	return wb
+}
+
+//createSheet creates a new, empty sheet of the workbook's format
+func (wb *Workbook) createSheet(name string) Spreadsheeter {
+	if wb.format == WorkbookFormatODS {
+		return NewSpreadsheetODS(name)
+	}
+
+	return NewSpreadsheetXLSX(name)
+}
+
+//validateName checks if a sheet name is allowed. Both formats don't allow empty
+//names, and .xlsx files limit the length and allowed characters of a name.
+func (wb *Workbook) validateName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	if wb.format == WorkbookFormatXLSX {
+		return len([]rune(name)) <= 31 && !strings.ContainsAny(name, `[]:*?/\`)
+	}
+
+	return true
+}
+
+//Load will load all sheets from the specified file, replacing all current
+//sheets. If an empty filename is specified then the filename from the previous
+//Load(...) call will be used. The Filename of the loaded sheets is left empty,
+//as saving a single sheet to the workbook file would remove all other sheets.
+func (wb *Workbook) Load(filename string) error {
+	//check if a valid filename exists
+	if len(filename) == 0 {
+		if len(wb.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "Workbook", "No filename specified to load"}
+		}
+
+		filename = wb.Filename
+	} else {
+		wb.Filename = filename
+	}
+
+	if wb.format == WorkbookFormatODS {
+		sheets, err := odsReadDocument(filename)
+
+		if err != nil {
+			return err
+		}
+
+		wb.reset()
+
+		for index, sheet := range sheets {
+			wb.names = append(wb.names, sheet.name)
+			wb.sheets[sheet.name] = &SpreadsheetODS{"", sheet.name, index, sheet.data, sheet.types}
+		}
+
+		return nil
+	}
+
+	sheets, err := xlsxReadWorkbook(filename, func(int, string) bool { return true })
+
+	if err != nil {
+		return err
+	}
+
+	wb.reset()
+
+	for index, sheet := range sheets {
+		wb.names = append(wb.names, sheet.name)
+		wb.sheets[sheet.name] = &SpreadsheetXLSX{"", sheet.name, index, sheet.data, sheet.types}
+	}
+
+	return nil
+}
+
+//Save will save all sheets to the specified file. If an empty filename is
+//specified then the filename from the previous Load(...) call will be used.
+func (wb *Workbook) Save(filename string) error {
+	//check if a filename is specified
+	if len(filename) == 0 {
+		if len(wb.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "Workbook", "No filename specified to save"}
+		}
+
+		filename = wb.Filename
+	}
+
+	if wb.format == WorkbookFormatODS {
+		sheets := make([]odsSheet, 0, len(wb.names))
+
+		for _, name := range wb.names {
+			sheet := wb.sheets[name].(*SpreadsheetODS)
+			sheets = append(sheets, odsSheet{name, sheet.Data, sheet.Types})
+		}
+
+		return odsWriteDocument(filename, sheets)
+	}
+
+	sheets := make([]xlsxSheet, 0, len(wb.names))
+
+	for _, name := range wb.names {
+		sheet := wb.sheets[name].(*SpreadsheetXLSX)
+		sheets = append(sheets, xlsxSheet{name, sheet.Data, sheet.Types})
+	}
+
+	return xlsxWriteWorkbook(filename, sheets)
+}
+
+//RenameSheet will change the name of a sheet while retaining its position
+//within the workbook. If the sheet doesn't exist or the new name is already in
+//use an error will be returned.
+func (wb *Workbook) RenameSheet(name, newName string) error {
+	sheet, err := wb.rename(name, newName)
+
+	if err != nil {
+		return err
+	}
+
+	switch s := sheet.(type) {
+	case *SpreadsheetXLSX:
+		s.Sheet = newName
+	case *SpreadsheetODS:
+		s.Sheet = newName
+	}
+
+	return nil
+}
+
+//WorkbookDir represents a directory of delimited spreadsheet files as a
+//workbook. It implements the Workbooker interface. Each file with the
+//workbook's extension is a sheet, the name of the sheet is the filename without
+//the extension. Each sheet is a SpreadsheetDelimSheet, wrapping a
+//SpreadsheetDelim instance. New instances should be created using the
+//NewWorkbookDir(...) function.
+type WorkbookDir struct {
+	workbookSheets
+	Filename  string //the directory
+	buffer    int
+	delimeter string
+	extension string
+	removed   map[string]bool
+}
+
+//NewWorkbookDir creates a new, empty WorkbookDir instance and returns its
+//pointer. The buffer size and delimeter are passed on to the SpreadsheetDelim
+//instance of each sheet. The extension (such as '.csv') determines which files
+//within the directory are considered to be sheets.
+func NewWorkbookDir(buffer int, delimeter, extension string) *WorkbookDir {
+	wd := &WorkbookDir{Filename: "", buffer: buffer, delimeter: delimeter, extension: extension}
+	wd.source = "WorkbookDir"
+	wd.create = wd.createSheet
+	wd.validate = wd.validateName
+	wd.reset()
+	return wd
+}
+
+//createSheet creates a new, empty delimited sheet
+func (wd *WorkbookDir) createSheet(name string) Spreadsheeter {
+	return SpreadsheetDelimSheet{NewSpreadsheetDelim(wd.buffer, wd.delimeter)}
+}
+
+//validateName checks if a sheet name can be used as a filename within the
+//directory
+func (wd *WorkbookDir) validateName(name string) bool {
+	return len(name) != 0 && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+//reset removes all sheets, and forgets about removed sheets
+func (wd *WorkbookDir) reset() {
+	wd.workbookSheets.reset()
+	wd.removed = make(map[string]bool)
+}
+
+//Load will load all files with the workbook's extension from the specified
+//directory as sheets, replacing all current sheets. The sheets are ordered by
+//name. If an empty directory name is specified then the directory from the
+//previous Load(...) call will be used.
+func (wd *WorkbookDir) Load(dirname string) error {
+	//check if a valid directory name exists
+	if len(dirname) == 0 {
+		if len(wd.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "WorkbookDir", "No directory specified to load"}
+		}
+
+		dirname = wd.Filename
+	} else {
+		wd.Filename = dirname
+	}
+
+	entries, err := os.ReadDir(dirname)
+
+	if err != nil {
+		return Error{ErrorTypeLoading, "WorkbookDir", "Failed to read the directory"}
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), wd.extension) && len(entry.Name()) > len(wd.extension) {
+			names = append(names, strings.TrimSuffix(entry.Name(), wd.extension))
+		}
+	}
+
+	sort.Strings(names)
+	wd.reset()
+
+	for _, name := range names {
+		sheet := SpreadsheetDelimSheet{NewSpreadsheetDelim(wd.buffer, wd.delimeter)}
+		err = sheet.Load(filepath.Join(dirname, name+wd.extension))
+
+		if err != nil {
+			wd.reset()
+			return err
+		}
+
+		wd.names = append(wd.names, name)
+		wd.sheets[name] = sheet
+	}
+
+	return nil
+}
+
+//Save will save all sheets as files within the specified directory, creating
+//the directory if required. When saving to the directory of the last Load(...)
+//call, files belonging to sheets that were removed or renamed since are
+//deleted. If an empty directory name is specified then the directory from the
+//previous Load(...) call will be used.
+func (wd *WorkbookDir) Save(dirname string) error {
+	//check if a directory name is specified
+	if len(dirname) == 0 {
+		if len(wd.Filename) == 0 {
+			return Error{ErrorTypeInvalidArgument, "WorkbookDir", "No directory specified to save"}
+		}
+
+		dirname = wd.Filename
+	}
+
+	err := os.MkdirAll(dirname, 0755)
+
+	if err != nil {
+		return Error{ErrorTypeSaving, "WorkbookDir", "Failed to create the directory"}
+	}
+
+	for _, name := range wd.names {
+		sheet := wd.sheets[name].(SpreadsheetDelimSheet)
+		err = sheet.Save(filepath.Join(dirname, name+wd.extension))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	//remove the files of sheets that no longer exist, but only from the loaded
+	//directory as other directories may contain unrelated files
+	if !wd.isLoadedDir(dirname) {
+		return nil
+	}
+
+	for name := range wd.removed {
+		if _, ok := wd.sheets[name]; ok {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dirname, name+wd.extension))
+
+		if err != nil && !os.IsNotExist(err) {
+			return Error{ErrorTypeSaving, "WorkbookDir", "Failed to remove the file of sheet '" + name + "'"}
+		}
+	}
+
+	wd.removed = make(map[string]bool)
+	return nil
+}
+
+//isLoadedDir checks if the directory is the directory of the last Load(...)
+//call
+func (wd *WorkbookDir) isLoadedDir(dirname string) bool {
+	if len(wd.Filename) == 0 {
+		return false
+	}
+
+	loaded, err := filepath.Abs(wd.Filename)
+
+	if err != nil {
+		return false
+	}
+
+	dirname, err = filepath.Abs(dirname)
+	return err == nil && loaded == dirname
+}
+
+//RemoveSheet will remove the sheet with the specified name from the workbook.
+//The file of the sheet will be deleted during the next Save(...) call. If the
+//sheet doesn't exist an error will be returned.
+func (wd *WorkbookDir) RemoveSheet(name string) error {
+	err := wd.workbookSheets.RemoveSheet(name)
+
+	if err == nil {
+		wd.removed[name] = true
+	}
+
+	return err
+}
+
+//RenameSheet will change the name of a sheet. The file of the sheet will be
+//renamed during the next Save(...) call. If the sheet doesn't exist or the new
+//name is already in use an error will be returned.
+func (wd *WorkbookDir) RenameSheet(name, newName string) error {
+	_, err := wd.rename(name, newName)
+
+	if err == nil && name != newName {
+		wd.removed[name] = true
+	}
+
+	return err
+}
@@ -0,0 +1,183 @@
+package fio
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+//testWorkbookFill adds three sheets to a workbook, then renames and removes
+//some of them
+func testWorkbookFill(wb Workbooker, t *testing.T) {
+	for _, name := range [...]string{"alpha", "beta", "gamma"} {
+		sheet, err := wb.AddSheet(name)
+
+		if err != nil {
+			t.Fatalf("Failed to add sheet '%s', error: %s\n", name, err.Error())
+		}
+
+		for row := 0; row < 5; row++ {
+			sheet.Set(row, 0, name)
+			sheet.Set(row, 1, strconv.Itoa(row))
+		}
+	}
+
+	if _, err := wb.AddSheet("beta"); err == nil {
+		t.Errorf("Expected adding an existing sheet to fail\n")
+	}
+
+	if err := wb.RenameSheet("alpha", "gamma"); err == nil {
+		t.Errorf("Expected renaming to an existing sheet to fail\n")
+	}
+
+	if err := wb.RenameSheet("alpha", "delta"); err != nil {
+		t.Errorf("Failed to rename sheet, error: %s\n", err.Error())
+	}
+
+	if err := wb.RemoveSheet("beta"); err != nil {
+		t.Errorf("Failed to remove sheet, error: %s\n", err.Error())
+	}
+
+	if err := wb.RemoveSheet("beta"); err == nil {
+		t.Errorf("Expected removing a non-existant sheet to fail\n")
+	}
+}
+
+//testWorkbookValidity checks the sheets written by testWorkbookFill(...)
+func testWorkbookValidity(wb Workbooker, expectedNames []string, t *testing.T) {
+	names := wb.Sheets()
+
+	if len(names) != len(expectedNames) {
+		t.Fatalf("Sheets %v != %v\n", names, expectedNames)
+	}
+
+	for i, name := range names {
+		if name != expectedNames[i] {
+			t.Errorf("Sheets %v != %v\n", names, expectedNames)
+		}
+	}
+
+	original := map[string]string{"delta": "alpha", "gamma": "gamma"}
+
+	for _, name := range names {
+		sheet, ok := wb.Sheet(name)
+
+		if !ok {
+			t.Fatalf("Sheet '%s' is listed but not retrievable\n", name)
+		}
+
+		for row := 0; row < 5; row++ {
+			value, _ := sheet.Get(row, 0)
+			number, _, err := sheet.GetInt(row, 1)
+
+			if value != original[name] || err != nil || number != row {
+				t.Errorf("Unexpected row %d ('%s', %d) in sheet '%s'\n", row, value, number, name)
+			}
+		}
+	}
+}
+
+func TestWorkbook(t *testing.T) {
+	formats := [...]struct {
+		format   WorkbookFormat
+		filename string
+	}{
+		{WorkbookFormatXLSX, "test_workbook.xlsx"},
+		{WorkbookFormatODS, "test_workbook.ods"},
+	}
+
+	for _, f := range formats {
+		wb := NewWorkbook(f.format)
+		testWorkbookFill(wb, t)
+		testWorkbookValidity(wb, []string{"delta", "gamma"}, t)
+
+		err := wb.Save(f.filename)
+
+		if err != nil {
+			t.Fatalf("Failed to save '%s', error: %s\n", f.filename, err.Error())
+		}
+
+		wb2 := NewWorkbook(f.format)
+		err = wb2.Load(f.filename)
+
+		if err != nil {
+			os.Remove(f.filename)
+			t.Fatalf("Failed to load '%s', error: %s\n", f.filename, err.Error())
+		}
+
+		testWorkbookValidity(wb2, []string{"delta", "gamma"}, t)
+
+		//saving a single loaded sheet should not overwrite the workbook
+		sheet, _ := wb2.Sheet("delta")
+
+		if err = sheet.Save(""); err == nil {
+			t.Errorf("Expected saving a loaded sheet without a filename to fail\n")
+		}
+
+		wb3 := NewWorkbook(f.format)
+		err = wb3.Load(f.filename)
+		os.Remove(f.filename)
+
+		if err != nil {
+			t.Fatalf("Failed to reload '%s', error: %s\n", f.filename, err.Error())
+		}
+
+		testWorkbookValidity(wb3, []string{"delta", "gamma"}, t)
+	}
+
+	if _, err := NewWorkbook(WorkbookFormatXLSX).AddSheet("a/b"); err == nil {
+		t.Errorf("Expected an invalid .xlsx sheet name to be rejected\n")
+	}
+}
+
+func TestWorkbookDir(t *testing.T) {
+	dir := t.TempDir()
+
+	//the file of a sheet that is not loaded should be left alone
+	os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0644)
+
+	wd := NewWorkbookDir(16, ",", ".csv")
+	testWorkbookFill(wd, t)
+	err := wd.Save(dir)
+
+	if err != nil {
+		t.Fatalf("Failed to save the directory, error: %s\n", err.Error())
+	}
+
+	wd2 := NewWorkbookDir(16, ",", ".csv")
+	err = wd2.Load(dir)
+
+	if err != nil {
+		t.Fatalf("Failed to load the directory, error: %s\n", err.Error())
+	}
+
+	testWorkbookValidity(wd2, []string{"delta", "gamma"}, t)
+
+	//renaming and removing sheets should rename and remove the files, but
+	//only within the loaded directory
+	wd2.RenameSheet("delta", "alpha")
+	wd2.RemoveSheet("gamma")
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(other, "gamma.csv"), []byte("x"), 0644)
+
+	if err = wd2.Save(other); err != nil {
+		t.Fatalf("Failed to save the directory, error: %s\n", err.Error())
+	}
+
+	if _, err = os.Stat(filepath.Join(other, "gamma.csv")); err != nil {
+		t.Errorf("Expected an unrelated file to be left alone\n")
+	}
+
+	err = wd2.Save("")
+
+	if err != nil {
+		t.Fatalf("Failed to save the directory, error: %s\n", err.Error())
+	}
+
+	entries, _ := os.ReadDir(dir)
+
+	if len(entries) != 2 || entries[0].Name() != "alpha.csv" || entries[1].Name() != "other.txt" {
+		t.Errorf("Unexpected directory contents after saving\n")
+	}
+}